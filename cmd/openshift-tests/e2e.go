@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -414,6 +415,32 @@ func suiteWithInitializedProviderPreSuite(opt *runOptions) error {
 	return nil
 }
 
+// suiteFileInvariants are the invariants a suite in a --suite-file may name
+var suiteFileInvariants = map[string]ginkgo.JUnitsForEvents{
+	"stable":  ginkgo.JUnitForEventsFunc(synthetictests.StableSystemEventInvariants),
+	"upgrade": ginkgo.JUnitForEventsFunc(synthetictests.SystemUpgradeEventInvariants),
+	"system":  ginkgo.JUnitForEventsFunc(synthetictests.SystemEventInvariants),
+}
+
+// withSuitesFromFile returns suites and the suites defined in a --suite-file, which filter out the tests of other
+// providers like most static suites.
+func withSuitesFromFile(suites testSuites, path string) (testSuites, error) {
+	fileSuites, err := ginkgo.ReadSuiteFile(path, suiteFileInvariants)
+	if err != nil {
+		return nil, err
+	}
+	combined := append(testSuites{}, suites...)
+	for _, suite := range fileSuites {
+		for _, existing := range suites {
+			if existing.Name == suite.Name {
+				return nil, fmt.Errorf("suite %q in %s is already defined", suite.Name, path)
+			}
+		}
+		combined = append(combined, testSuite{TestSuite: *suite, PreSuite: suiteWithProviderPreSuite})
+	}
+	return combined, nil
+}
+
 // suiteWithProviderPreSuite ensures that the suite filters out tests from providers
 // that aren't relevant (see exutilcluster.ClusterConfig.MatchFn) by loading the
 // provider info from the cluster or flags.
@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math/rand"
 	"os"
 	"os/exec"
@@ -17,12 +18,17 @@ import (
 	"github.com/openshift/library-go/pkg/image/reference"
 	"github.com/openshift/library-go/pkg/serviceability"
 	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitor/backenddisruption"
 	"github.com/openshift/origin/pkg/monitor/resourcewatch/cmd"
+	"github.com/openshift/origin/pkg/synthetictests"
+	"github.com/openshift/origin/pkg/synthetictests/historicaldata"
 	testginkgo "github.com/openshift/origin/pkg/test/ginkgo"
+	"github.com/openshift/origin/pkg/test/ginkgo/result"
 	"github.com/openshift/origin/pkg/version"
 	exutil "github.com/openshift/origin/test/extended/util"
 	"github.com/openshift/origin/test/extended/util/cluster"
 	"github.com/openshift/origin/test/extended/util/disruption/controlplane"
+	"github.com/openshift/origin/test/extended/util/disruption/custom"
 	"github.com/openshift/origin/test/extended/util/disruption/frontends"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -70,6 +76,9 @@ func main() {
 		newImagesCommand(),
 		newRunTestCommand(),
 		newRunMonitorCommand(),
+		newAnalyzeEventsCommand(),
+		newMergeResultsCommand(),
+		newComputeHistoricalDataCommand(),
 		cmd.NewRunResourceWatchCommand(),
 	)
 
@@ -95,11 +104,9 @@ func newRunMonitorCommand() *cobra.Command {
 	monitorOpt := &monitor.Options{
 		Out:    os.Stdout,
 		ErrOut: os.Stderr,
-		AdditionalEventIntervalRecorders: []monitor.StartEventIntervalRecorderFunc{
-			controlplane.StartAllAPIMonitoring,
-			frontends.StartAllIngressMonitoring,
-		},
 	}
+	var disruptionBackendsFile string
+	disruptionLatency := backenddisruption.DefaultLatencyConfig()
 	cmd := &cobra.Command{
 		Use:   "run-monitor",
 		Short: "Continuously verify the cluster is functional",
@@ -111,9 +118,164 @@ func newRunMonitorCommand() *cobra.Command {
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			monitorOpt.AdditionalEventIntervalRecorders = []monitor.StartEventIntervalRecorderFunc{
+				controlplane.StartAllAPIMonitoringWithLatency(disruptionLatency),
+				frontends.StartAllIngressMonitoringWithLatency(disruptionLatency),
+			}
+			if len(disruptionBackendsFile) > 0 {
+				startConfiguredMonitoring, err := custom.StartMonitoringFromFile(disruptionBackendsFile, disruptionLatency)
+				if err != nil {
+					return err
+				}
+				if err := synthetictests.SetConfiguredDisruptionBackendsFromFile(disruptionBackendsFile); err != nil {
+					return err
+				}
+				monitorOpt.AdditionalEventIntervalRecorders = append(monitorOpt.AdditionalEventIntervalRecorders, startConfiguredMonitoring)
+			}
+			if monitorOpt.SoakWindow > 0 {
+				restConfig, err := monitor.GetMonitorRESTConfig()
+				if err != nil {
+					return err
+				}
+				monitorOpt.WriteWindow = testginkgo.NewSoakWindowWriter("run-monitor", testginkgo.JUnitForEventsFunc(synthetictests.StableSystemEventInvariants), restConfig).WriteWindow
+			}
 			return monitorOpt.Run()
 		},
 	}
+	cmd.Flags().StringVar(&monitorOpt.ArtifactDir, "artifact-dir", monitorOpt.ArtifactDir, "The directory to write the event journal and soak windows to.")
+	cmd.Flags().DurationVar(&monitorOpt.SoakWindow, "soak-window", monitorOpt.SoakWindow, "Every interval of this length (for example 4h), write the events, intervals, disruption and alert data and invariant results to a timestamped directory in --artifact-dir and drop them from memory.")
+	cmd.Flags().StringVar(&disruptionBackendsFile, "disruption-backends", disruptionBackendsFile, "A YAML or JSON file of extra backends to monitor for disruption.")
+	cmd.Flags().StringVar(&monitorOpt.StatusAddress, "status-address", monitorOpt.StatusAddress, "Serve the current intervals, disruption state, Prometheus metrics and event chart over HTTP on this host:port, for example localhost:8080.")
+	bindDisruptionLatency(&disruptionLatency, cmd.Flags())
+	return cmd
+}
+
+func newAnalyzeEventsCommand() *cobra.Command {
+	opt := testginkgo.NewAnalyzeOptions()
+
+	cmd := &cobra.Command{
+		Use:   "analyze-events SUITE",
+		Short: "Run the invariants of a suite against saved events",
+		Long: templates.LongDesc(`
+		Run the invariants of a suite against the events saved by an earlier run
+
+		This command reads the e2e-events_*.json files (or event journals) and resource-*.zip files written
+		to the --junit-dir of a run and evaluates the invariants of the named test or upgrade suite against
+		them without contacting a cluster. The JUnit results and interval pages are written to --junit-dir.
+		Invariants that need to identify the cluster use the JobType in --job-type when it is provided.
+
+		`),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("specify the suite whose invariants should be run, for example: %s analyze-events %s", filepath.Base(os.Args[0]), staticSuites[0].Name)
+			}
+			for _, suite := range staticSuites {
+				if suite.Name == args[0] {
+					opt.SyntheticEventTests = suite.SyntheticEventTests
+					return opt.Run(suite.Name, "openshift-tests")
+				}
+			}
+			for _, suite := range upgradeSuites {
+				if suite.Name == args[0] {
+					opt.SyntheticEventTests = suite.SyntheticEventTests
+					return opt.Run(suite.Name, "openshift-tests-upgrade")
+				}
+			}
+			return fmt.Errorf("suite %q does not exist", args[0])
+		},
+	}
+	cmd.Flags().StringSliceVar(&opt.EventFiles, "events", opt.EventFiles, "The e2e-events_*.json files or event journals to analyze.")
+	cmd.Flags().StringSliceVar(&opt.ResourceFiles, "resources", opt.ResourceFiles, "The resource-*.zip files saved with the events.")
+	cmd.Flags().StringVar(&opt.JobTypeFile, "job-type", opt.JobTypeFile, "A JSON file describing the job type of the cluster the events came from.")
+	cmd.Flags().StringVar(&opt.DisruptionBackendsFile, "disruption-backends", opt.DisruptionBackendsFile, "The YAML or JSON file of extra backends the run monitored for disruption, which get availability tests.")
+	cmd.Flags().StringVar(&opt.DisruptionBudgetFile, "disruption-budget", opt.DisruptionBudgetFile, "A YAML or JSON file of allowed disruption in seconds by backend and job type. Backends disrupted for longer fail instead of flake.")
+	cmd.Flags().StringVar(&opt.HistoricalDataFile, "historical-data", opt.HistoricalDataFile, "A JSON file of alert and disruption percentiles written by compute-historical-data, used instead of the built in data.")
+	cmd.Flags().StringVar(&opt.HistoricalDataMatchingFile, "historical-data-matching", opt.HistoricalDataMatchingFile, "A YAML or JSON file with the MaxDistance and Weights used to find the closest historical data to the job type, and the AlertDefault and DisruptionDefault in seconds used when none is close enough.")
+	cmd.Flags().StringSliceVar(&opt.RepeatedEventsAllowlistFiles, "repeated-events-allowlist", opt.RepeatedEventsAllowlistFiles, "A YAML or JSON allowlist of repeated events with a scope, selectors, bug link and expiry, merged into the built in one. May be repeated.")
+	cmd.Flags().StringVar(&opt.AlertAllowlistFile, "alert-allowlist", opt.AlertAllowlistFile, "A YAML or JSON list of alerts with a reason and bug link that the generic alert invariant skips instead of failing or flaking.")
+	cmd.Flags().StringVar(&opt.JUnitDir, "junit-dir", opt.JUnitDir, "The directory to write test reports and intervals to.")
+	return cmd
+}
+
+func newMergeResultsCommand() *cobra.Command {
+	opt := testginkgo.NewMergeOptions()
+
+	cmd := &cobra.Command{
+		Use:   "merge-results SHARD_DIR...",
+		Short: "Combine the results of the shards of a suite",
+		Long: templates.LongDesc(`
+		Combine the results of the shards of a suite into one report
+
+		A suite run with --shard-count is split across that many processes, usually on different hosts.
+		This command reads the JUnit results and events each shard wrote to its --junit-dir and writes a
+		single JUnit report, the combined events and their interval pages to --junit-dir. Every shard
+		evaluates the invariants, which fail in the report if they failed in any shard.
+
+		`),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opt.Dirs = args
+			return opt.Run()
+		},
+	}
+	cmd.Flags().StringVar(&opt.JUnitDir, "junit-dir", opt.JUnitDir, "The directory to write the merged test reports and intervals to.")
+	return cmd
+}
+
+func newComputeHistoricalDataCommand() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "compute-historical-data JOB_RUNS_DIR...",
+		Short: "Compute alert and disruption percentiles from past job runs",
+		Long: templates.LongDesc(`
+		Compute the P95 and P99 of alert and disruption durations from the artifacts of past job runs
+
+		This command walks the directories for the backend-disruption_*.json and alerts_*.json files
+		written to the --junit-dir of runs and computes the percentiles for every backend or alert and
+		job type. The job type of a run is read from the job-type_*.json file the run wrote next to them,
+		or from a job-type.json file in the same directory or a directory above it for runs that did not
+		record one. The result is written to --output, to be passed to the --historical-data flag of run,
+		run-upgrade and analyze-events in place of the built in data.
+
+		`),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("specify at least one directory of job runs")
+			}
+			if len(output) == 0 {
+				return fmt.Errorf("--output must be specified")
+			}
+			computed, err := historicaldata.ComputeFromJobRuns(args)
+			if err != nil {
+				return err
+			}
+			for _, path := range computed.Unidentified {
+				fmt.Fprintf(os.Stderr, "warning: Skipped %s, no job type was recorded for its job run\n", path)
+			}
+			if computed.JobRuns == 0 {
+				return fmt.Errorf("no job runs with a recorded job type were found in %s", strings.Join(args, ", "))
+			}
+			data, err := historicaldata.MarshalStatisticalData(computed.Data)
+			if err != nil {
+				return err
+			}
+			if err := ioutil.WriteFile(output, data, 0644); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "Computed %d percentiles from %d job runs\n", len(computed.Data), computed.JobRuns)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&output, "output", output, "The file to write the historical data to.")
 	return cmd
 }
 
@@ -201,6 +363,8 @@ type runOptions struct {
 
 	FromRepository string
 	Provider       string
+	// SuiteFile is a YAML file of more suites, see testginkgo.SuiteDefinition
+	SuiteFile string
 
 	// Passed to the test process if set
 	UpgradeSuite string
@@ -282,6 +446,20 @@ func newRunCommand() *cobra.Command {
 		command with the --file argument. You may also pipe a list of test names, one per line, on
 		standard input by passing "-f -".
 
+		Suites can also be defined in a YAML file passed with --suite-file, for example:
+
+		  - name: my-team/network
+		    description: The network tests that can run in parallel.
+		    include: ["\\[sig-network\\]"]
+		    exclude: ["should be reachable from the internet"]
+		    tags: "!([Serial] || [Slow])"
+		    parallelism: 20
+		    testTimeout: 15m
+		    maximumAllowedFlakes: 3
+		    invariants: stable
+
+		Invariants may be stable, upgrade or system.
+
 		`) + testginkgo.SuitesString(staticSuites.TestSuites(), "\n\nAvailable test suites:\n\n"),
 
 		SilenceUsage:  true,
@@ -293,7 +471,14 @@ func newRunCommand() *cobra.Command {
 				}
 				opt.SyntheticEventTests = pulledInvalidImages(opt.FromRepository)
 
-				suite, err := opt.SelectSuite(staticSuites, args)
+				suites := staticSuites
+				if len(opt.SuiteFile) > 0 {
+					var err error
+					if suites, err = withSuitesFromFile(staticSuites, opt.SuiteFile); err != nil {
+						return err
+					}
+				}
+				suite, err := opt.SelectSuite(suites, args)
 				if err != nil {
 					return err
 				}
@@ -315,6 +500,7 @@ func newRunCommand() *cobra.Command {
 		},
 	}
 	bindOptions(opt, cmd.Flags())
+	cmd.Flags().StringVar(&opt.SuiteFile, "suite-file", opt.SuiteFile, "A YAML file of more suites to choose from, each with include and exclude regular expressions, a tags expression, parallelism, count, testTimeout, maximumAllowedFlakes and invariants.")
 	return cmd
 }
 
@@ -389,6 +575,8 @@ func newRunTestCommand() *cobra.Command {
 		ErrOut: os.Stderr,
 	}
 
+	var worker bool
+
 	cmd := &cobra.Command{
 		Use:   "run-test NAME",
 		Short: "Run a single test by name",
@@ -397,6 +585,10 @@ func newRunTestCommand() *cobra.Command {
 
 		This executes a single test by name. It is used by the run command during suite execution but may also
 		be used to test in isolation while developing new tests.
+
+		With --worker the names of tests are read from a pipe the run command passes as file descriptor 3
+		instead, and the tests are run one after the other in this process with /dev/null as standard input.
+		The run command starts workers with --worker-pool.
 		`),
 
 		SilenceUsage:  true,
@@ -431,11 +623,22 @@ func newRunTestCommand() *cobra.Command {
 				return err
 			}
 
+			if worker {
+				return testginkgo.RunWorker(testginkgo.WorkerInput(), os.Stdout, func(name string) error {
+					// a test that flaked and then failed leaves its flake behind, which must not be reported for
+					// the next test
+					result.LastFlake()
+					var err error
+					exutil.WithCleanup(func() { err = testOpt.Run([]string{name}) })
+					return err
+				})
+			}
 			exutil.WithCleanup(func() { err = testOpt.Run(args) })
 			return err
 		},
 	}
 	cmd.Flags().BoolVar(&testOpt.DryRun, "dry-run", testOpt.DryRun, "Print the test to run without executing them.")
+	cmd.Flags().BoolVar(&worker, "worker", worker, "Run the tests named on a pipe from the run command until it is closed.")
 	return cmd
 }
 
@@ -480,5 +683,26 @@ func bindTestOptions(opt *testginkgo.Options, flags *pflag.FlagSet) {
 	flags.BoolVar(&opt.FailFast, "fail-fast", opt.FailFast, "If a test fails, exit immediately.")
 	flags.DurationVar(&opt.Timeout, "timeout", opt.Timeout, "Set the maximum time a test can run before being aborted. This is read from the suite by default, but will be 10 minutes otherwise.")
 	flags.BoolVar(&opt.IncludeSuccessOutput, "include-success", opt.IncludeSuccessOutput, "Print output from successful tests.")
+	flags.BoolVar(&opt.JournalEvents, "journal-events", opt.JournalEvents, "Write monitor events to a journal in --junit-dir as they are recorded so they survive a crash.")
+	flags.StringVar(&opt.DisruptionBackendsFile, "disruption-backends", opt.DisruptionBackendsFile, "A YAML or JSON file of extra backends to monitor for disruption.")
+	flags.StringVar(&opt.DisruptionBudgetFile, "disruption-budget", opt.DisruptionBudgetFile, "A YAML or JSON file of allowed disruption in seconds by backend and job type. Backends disrupted for longer fail instead of flake.")
+	flags.StringVar(&opt.HistoricalDataFile, "historical-data", opt.HistoricalDataFile, "A JSON file of alert and disruption percentiles written by compute-historical-data, used instead of the built in data.")
+	flags.StringVar(&opt.HistoricalDataMatchingFile, "historical-data-matching", opt.HistoricalDataMatchingFile, "A YAML or JSON file with the MaxDistance and Weights used to find the closest historical data to the job type, and the AlertDefault and DisruptionDefault in seconds used when none is close enough.")
+	flags.StringSliceVar(&opt.RepeatedEventsAllowlistFiles, "repeated-events-allowlist", opt.RepeatedEventsAllowlistFiles, "A YAML or JSON allowlist of repeated events with a scope, selectors, bug link and expiry, merged into the built in one. May be repeated.")
+	flags.StringVar(&opt.AlertAllowlistFile, "alert-allowlist", opt.AlertAllowlistFile, "A YAML or JSON list of alerts with a reason and bug link that the generic alert invariant skips instead of failing or flaking.")
+	flags.StringVar(&opt.StatusAddress, "status-address", opt.StatusAddress, "Serve the current monitor intervals, disruption state, Prometheus metrics and event chart over HTTP on this host:port while the tests run, for example localhost:8080.")
+	flags.IntVar(&opt.ShardCount, "shard-count", opt.ShardCount, "Split the suite into this many shards that run separately. Serial, Early and Late tests always run in shard 0. Combine the results with merge-results.")
+	flags.IntVar(&opt.ShardIndex, "shard-index", opt.ShardIndex, "The shard of the suite to run, from 0 to --shard-count minus 1.")
+	flags.StringVar(&opt.TestDurationsPath, "test-durations", opt.TestDurationsPath, "A JSON file of test durations, or the --junit-dir of an earlier run, used to start the longest tests first.")
+	flags.BoolVar(&opt.WorkerPool, "worker-pool", opt.WorkerPool, "Run tests in long lived worker processes, one per parallel test, instead of starting a process for every test.")
+	flags.StringVar(&opt.QuarantineFile, "quarantine-file", opt.QuarantineFile, "A YAML list of known broken tests with a name, reason and expires date. Until they expire, their failures are reported as skips and do not fail the run.")
+	flags.StringVar(&opt.OutputJSONL, "output-jsonl", opt.OutputJSONL, "A file to write a JSON line to as each test finishes, followed by the results of the invariants and a summary of the run.")
+	flags.StringVar(&opt.ResumeFrom, "resume-from", opt.ResumeFrom, "The --junit-dir of an interrupted run. Tests that passed or were skipped in it are not run again, and its results and events are merged into this run's.")
 	flags.IntVar(&opt.Parallelism, "max-parallel-tests", opt.Parallelism, "Maximum number of tests running in parallel. 0 defaults to test suite recommended value, which is different in each suite.")
+	bindDisruptionLatency(&opt.DisruptionLatency, flags)
+}
+
+func bindDisruptionLatency(latency *backenddisruption.LatencyConfig, flags *pflag.FlagSet) {
+	flags.DurationVar(&latency.Threshold, "disruption-latency-threshold", latency.Threshold, "Record a HighLatency interval while the p99 latency of a disruption backend is above this value. 0 disables it.")
+	flags.DurationVar(&latency.Window, "disruption-latency-window", latency.Window, "How far back the p99 latency of a disruption backend is computed over for --disruption-latency-threshold.")
 }
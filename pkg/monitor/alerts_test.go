@@ -1,10 +1,13 @@
 package monitor
 
 import (
+	"context"
 	"reflect"
 	"testing"
 	"time"
 
+	prometheustypes "github.com/prometheus/common/model"
+
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
 )
 
@@ -342,3 +345,42 @@ func Test_blackoutEvents(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateEventIntervalsForAlerts(t *testing.T) {
+	start := prometheustypes.TimeFromUnix(timeOrDie("2022-04-01T10:00:00Z").Unix())
+	alert := func(name, state, severity string) *prometheustypes.SampleStream {
+		metric := prometheustypes.Metric{prometheustypes.AlertNameLabel: prometheustypes.LabelValue(name), "alertstate": prometheustypes.LabelValue(state)}
+		if len(severity) > 0 {
+			metric["severity"] = prometheustypes.LabelValue(severity)
+		}
+		return &prometheustypes.SampleStream{Metric: metric, Values: []prometheustypes.SamplePair{{Timestamp: start, Value: 1}}}
+	}
+
+	intervals, err := CreateEventIntervalsForAlerts(context.TODO(), prometheustypes.Matrix{
+		alert("Critical", "firing", "critical"),
+		alert("Warning", "firing", "warning"),
+		alert("Info", "firing", "info"),
+		alert("Pending", "pending", "critical"),
+		alert("None", "firing", "none"),
+		alert("Missing", "firing", ""),
+	}, start.Time())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]monitorapi.EventLevel{
+		"alert/Critical": monitorapi.Error,
+		"alert/Warning":  monitorapi.Warning,
+		"alert/Info":     monitorapi.Info,
+		"alert/Pending":  monitorapi.Info,
+		"alert/None":     monitorapi.Error,
+		"alert/Missing":  monitorapi.Error,
+	}
+	if len(intervals) != len(expected) {
+		t.Fatalf("expected %d intervals, got %v", len(expected), intervals)
+	}
+	for _, interval := range intervals {
+		if interval.Level != expected[interval.Locator] {
+			t.Errorf("%s: expected level %v, got %v", interval.Locator, expected[interval.Locator], interval.Level)
+		}
+	}
+}
@@ -30,10 +30,31 @@ func GetMonitorRESTConfig() (*rest.Config, error) {
 	return clusterConfig, nil
 }
 
+// defaultIntervalCreationFns create intervals from the events recorded by the monitors started in Start.
+var defaultIntervalCreationFns = []IntervalCreationFunc{
+	intervalcreation.IntervalsFromEvents_OperatorAvailable,
+	intervalcreation.IntervalsFromEvents_OperatorProgressing,
+	intervalcreation.IntervalsFromEvents_OperatorDegraded,
+	intervalcreation.IntervalsFromEvents_E2ETests,
+	intervalcreation.IntervalsFromEvents_NodeChanges,
+	intervalcreation.CreatePodIntervalsFromInstants,
+}
+
 // Start begins monitoring the cluster referenced by the default kube configuration until
 // context is finished.
 func Start(ctx context.Context, restConfig *rest.Config, additionalEventIntervalRecorders []StartEventIntervalRecorderFunc) (*Monitor, error) {
+	return StartWithJournal(ctx, restConfig, additionalEventIntervalRecorders, "")
+}
+
+// StartWithJournal is Start, but if journalFilename is set everything the monitor records is also appended to that
+// file as it happens.  The journal is started before any recorder so it is complete.
+func StartWithJournal(ctx context.Context, restConfig *rest.Config, additionalEventIntervalRecorders []StartEventIntervalRecorderFunc, journalFilename string) (*Monitor, error) {
 	m := NewMonitorWithInterval(time.Second)
+	if len(journalFilename) > 0 {
+		if err := m.StartJournal(ctx, journalFilename); err != nil {
+			return nil, fmt.Errorf("unable to start event journal: %w", err)
+		}
+	}
 	client, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
 		return nil, err
@@ -55,15 +76,7 @@ func Start(ctx context.Context, restConfig *rest.Config, additionalEventInterval
 
 	// add interval creation at the same point where we add the monitors
 	startClusterOperatorMonitoring(ctx, m, configClient)
-	m.intervalCreationFns = append(
-		m.intervalCreationFns,
-		intervalcreation.IntervalsFromEvents_OperatorAvailable,
-		intervalcreation.IntervalsFromEvents_OperatorProgressing,
-		intervalcreation.IntervalsFromEvents_OperatorDegraded,
-		intervalcreation.IntervalsFromEvents_E2ETests,
-		intervalcreation.IntervalsFromEvents_NodeChanges,
-		intervalcreation.CreatePodIntervalsFromInstants,
-	)
+	m.intervalCreationFns = append(m.intervalCreationFns, defaultIntervalCreationFns...)
 
 	m.StartSampling(ctx)
 	return m, nil
@@ -0,0 +1,380 @@
+package backenddisruption
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+	"sigs.k8s.io/yaml"
+)
+
+// BackendConfigList is the content of a file describing extra backends to monitor for disruption, in YAML or JSON.
+//
+//	backends:
+//	- name: my-app
+//	  route:
+//	    namespace: my-namespace
+//	    name: my-app
+//	  path: /healthz
+//	  expectedBody: ok
+//	- name: my-dns
+//	  probe: dns
+//	  dnsName: my-app.my-namespace.svc.cluster.local
+type BackendConfigList struct {
+	Backends []BackendConfig `json:"backends"`
+}
+
+// BackendProbeType is how a backend is checked.
+type BackendProbeType string
+
+const (
+	// HTTPProbeType issues an HTTP GET of the path of a route, service or URL.
+	HTTPProbeType BackendProbeType = "http"
+	// TCPProbeType opens a TCP connection to an address.
+	TCPProbeType BackendProbeType = "tcp"
+	// DNSProbeType looks up a name.
+	DNSProbeType BackendProbeType = "dns"
+	// GRPCProbeType calls the gRPC health service at an address.
+	GRPCProbeType BackendProbeType = "grpc"
+	// WatchProbeType keeps a watch of a resource of the cluster open.
+	WatchProbeType BackendProbeType = "watch"
+)
+
+// defaultWatchMaxSilence is how long a watch probe may deliver nothing.  Servers send bookmarks to idle watches about
+// once a minute.
+const defaultWatchMaxSilence = 2 * time.Minute
+
+// probeTargets are the target field each probe type requires.
+var probeTargets = map[BackendProbeType]string{
+	TCPProbeType:   "address",
+	DNSProbeType:   "dnsName",
+	GRPCProbeType:  "address",
+	WatchProbeType: "watch",
+}
+
+// BackendConfig describes a backend to monitor.  Http probes need exactly one of Route, Service, and URL, the other
+// probes the target in probeTargets.
+type BackendConfig struct {
+	// Name is the disruption backend name used in locators and in the job run data.
+	Name string `json:"name"`
+	// Probe is how the backend is checked: http, tcp, dns, grpc or watch.  Defaults to http.
+	Probe BackendProbeType `json:"probe,omitempty"`
+
+	// Route is monitored through the host of its first ingress.
+	Route *RouteBackendConfig `json:"route,omitempty"`
+	// Service is monitored through its load balancer ingress.
+	Service *ServiceBackendConfig `json:"service,omitempty"`
+	// URL is the scheme://host:port of a backend outside the cluster.
+	URL string `json:"url,omitempty"`
+	// Address is the host:port of tcp and grpc probes.
+	Address string `json:"address,omitempty"`
+	// DNSName is the name dns probes look up.
+	DNSName string `json:"dnsName,omitempty"`
+	// Watch is the resource watch probes watch.
+	Watch *WatchBackendConfig `json:"watch,omitempty"`
+
+	// Path is the /path part of the URL.  Defaults to /.
+	Path string `json:"path,omitempty"`
+	// ConnectionTypes are the connection types to monitor with.  Each gets its own BackendSampler.  Defaults to new
+	// and reused, except for dns probes, which default to new, and watch probes, which default to reused.
+	ConnectionTypes []BackendConnectionType `json:"connectionTypes,omitempty"`
+	// ExpectedBody is an exact match for the response body.  If neither it nor ExpectedBodyRegex is set, any 2xx or
+	// 3xx response is accepted.
+	ExpectedBody string `json:"expectedBody,omitempty"`
+	// ExpectedBodyRegex is a regular expression the response body must match.
+	ExpectedBodyRegex string `json:"expectedBodyRegex,omitempty"`
+	// DNSServer is the host:port of the server dns probes query.  Defaults to the servers configured on the host.
+	DNSServer string `json:"dnsServer,omitempty"`
+	// GRPCService is the service grpc probes check the health of.  Defaults to the server as a whole.
+	GRPCService string `json:"grpcService,omitempty"`
+	// Auth is how requests authenticate to the backend.  Grpc probes use TLS, trusting the CA bundle of the
+	// kubeconfig, if Auth.Kubeconfig is set, and no encryption otherwise.  Watch probes always use the kubeconfig.
+	Auth BackendAuthConfig `json:"auth,omitempty"`
+	// Timeout is the timeout of a single request.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+type WatchBackendConfig struct {
+	Group     string `json:"group,omitempty"`
+	Version   string `json:"version"`
+	Resource  string `json:"resource"`
+	Namespace string `json:"namespace,omitempty"`
+	// MaxSilence is how long the watch may deliver neither events nor bookmarks before it counts as disrupted.
+	// Defaults to 2m.
+	MaxSilence *metav1.Duration `json:"maxSilence,omitempty"`
+}
+
+type RouteBackendConfig struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+type ServiceBackendConfig struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Port is the service port to connect to.  Defaults to the first port.
+	Port int32 `json:"port,omitempty"`
+	// Scheme is http or https.  Defaults to http.
+	Scheme string `json:"scheme,omitempty"`
+}
+
+type BackendAuthConfig struct {
+	// Kubeconfig sends the credentials of the kubeconfig the tests run with and trusts its CA bundle.
+	Kubeconfig bool `json:"kubeconfig,omitempty"`
+	// BearerTokenFile is a file holding a token to send as Authorization: Bearer.  Unless Kubeconfig is also set,
+	// the server certificate is not verified, the same as for backends without auth.
+	BearerTokenFile string `json:"bearerTokenFile,omitempty"`
+}
+
+// ReadBackendConfigFile reads and validates a BackendConfigList from a YAML or JSON file.
+func ReadBackendConfigFile(filename string) (*BackendConfigList, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	backends := &BackendConfigList{}
+	if err := yaml.UnmarshalStrict(data, backends); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %v", filename, err)
+	}
+	if err := backends.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid backends in %s: %v", filename, err)
+	}
+	return backends, nil
+}
+
+// Validate checks that every backend can be turned into BackendSamplers and that the names are unique.
+func (l *BackendConfigList) Validate() error {
+	names := map[string]bool{}
+	for i, backend := range l.Backends {
+		if err := backend.Validate(); err != nil {
+			return fmt.Errorf("backends[%d]: %v", i, err)
+		}
+		if names[backend.Name] {
+			return fmt.Errorf("backends[%d]: duplicate name %q", i, backend.Name)
+		}
+		names[backend.Name] = true
+	}
+	return nil
+}
+
+func (c BackendConfig) Validate() error {
+	if len(c.Name) == 0 {
+		return fmt.Errorf("name is required")
+	}
+	if strings.ContainsAny(c.Name, " /") {
+		return fmt.Errorf("name %q must not contain spaces or slashes", c.Name)
+	}
+
+	targets := []string{}
+	if c.Route != nil {
+		targets = append(targets, "route")
+		if len(c.Route.Namespace) == 0 || len(c.Route.Name) == 0 {
+			return fmt.Errorf("route.namespace and route.name are required")
+		}
+	}
+	if c.Service != nil {
+		targets = append(targets, "service")
+		if len(c.Service.Namespace) == 0 || len(c.Service.Name) == 0 {
+			return fmt.Errorf("service.namespace and service.name are required")
+		}
+		switch c.Service.Scheme {
+		case "", "http", "https":
+		default:
+			return fmt.Errorf("service.scheme must be http or https, not %q", c.Service.Scheme)
+		}
+	}
+	if len(c.URL) > 0 {
+		targets = append(targets, "url")
+		if !strings.HasPrefix(c.URL, "http://") && !strings.HasPrefix(c.URL, "https://") {
+			return fmt.Errorf("url %q must start with http:// or https://", c.URL)
+		}
+	}
+	if len(c.Address) > 0 {
+		targets = append(targets, "address")
+		if _, _, err := net.SplitHostPort(c.Address); err != nil {
+			return fmt.Errorf("address %q must be host:port", c.Address)
+		}
+	}
+	if len(c.DNSName) > 0 {
+		targets = append(targets, "dnsName")
+	}
+	if c.Watch != nil {
+		targets = append(targets, "watch")
+		if len(c.Watch.Version) == 0 || len(c.Watch.Resource) == 0 {
+			return fmt.Errorf("watch.version and watch.resource are required")
+		}
+		if c.Watch.MaxSilence != nil && c.Watch.MaxSilence.Duration <= 0 {
+			return fmt.Errorf("watch.maxSilence must be positive")
+		}
+	}
+
+	probe := c.probeType()
+	switch probe {
+	case HTTPProbeType:
+		if len(targets) != 1 || (targets[0] != "route" && targets[0] != "service" && targets[0] != "url") {
+			return fmt.Errorf("exactly one of route, service, and url is required")
+		}
+	case TCPProbeType, DNSProbeType, GRPCProbeType, WatchProbeType:
+		if len(targets) != 1 || targets[0] != probeTargets[probe] {
+			return fmt.Errorf("%s probes require %s and no other target", probe, probeTargets[probe])
+		}
+		if len(c.Path) > 0 || len(c.ExpectedBody) > 0 || len(c.ExpectedBodyRegex) > 0 || len(c.Auth.BearerTokenFile) > 0 {
+			return fmt.Errorf("path, expectedBody, expectedBodyRegex and auth.bearerTokenFile only apply to http probes")
+		}
+	default:
+		return fmt.Errorf("probe must be %q, %q, %q, %q or %q, not %q", HTTPProbeType, TCPProbeType, DNSProbeType, GRPCProbeType, WatchProbeType, probe)
+	}
+	if len(c.DNSServer) > 0 && probe != DNSProbeType {
+		return fmt.Errorf("dnsServer only applies to dns probes")
+	}
+	if len(c.GRPCService) > 0 && probe != GRPCProbeType {
+		return fmt.Errorf("grpcService only applies to grpc probes")
+	}
+
+	if len(c.Path) > 0 && !strings.HasPrefix(c.Path, "/") {
+		return fmt.Errorf("path %q must start with a slash", c.Path)
+	}
+	for _, connectionType := range c.ConnectionTypes {
+		if connectionType != NewConnectionType && connectionType != ReusedConnectionType {
+			return fmt.Errorf("connectionTypes must be %q or %q, not %q", NewConnectionType, ReusedConnectionType, connectionType)
+		}
+	}
+	if len(c.ExpectedBodyRegex) > 0 {
+		if _, err := regexp.Compile(c.ExpectedBodyRegex); err != nil {
+			return fmt.Errorf("expectedBodyRegex: %v", err)
+		}
+	}
+	if c.Timeout != nil && c.Timeout.Duration <= 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+	return nil
+}
+
+func (c BackendConfig) probeType() BackendProbeType {
+	if len(c.Probe) == 0 {
+		return HTTPProbeType
+	}
+	return c.Probe
+}
+
+// NewBackendSamplers returns a BackendSampler for each of the connection types of the backend.  clientConfig is used
+// to look up routes and services, by watch probes, and for auth if Auth.Kubeconfig is set.
+func (c BackendConfig) NewBackendSamplers(clientConfig *rest.Config) ([]*BackendSampler, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	path := c.Path
+	if len(path) == 0 {
+		path = "/"
+	}
+	connectionTypes := c.ConnectionTypes
+	if len(connectionTypes) == 0 {
+		switch c.probeType() {
+		case DNSProbeType:
+			connectionTypes = []BackendConnectionType{NewConnectionType}
+		case WatchProbeType:
+			connectionTypes = []BackendConnectionType{ReusedConnectionType}
+		default:
+			connectionTypes = []BackendConnectionType{NewConnectionType, ReusedConnectionType}
+		}
+	}
+
+	var watchFn WatchFunc
+	if c.Watch != nil {
+		dynamicClient, err := dynamic.NewForConfig(clientConfig)
+		if err != nil {
+			return nil, err
+		}
+		resource := dynamicClient.Resource(schema.GroupVersionResource{Group: c.Watch.Group, Version: c.Watch.Version, Resource: c.Watch.Resource})
+		watchFn = resource.Watch
+		if len(c.Watch.Namespace) > 0 {
+			watchFn = resource.Namespace(c.Watch.Namespace).Watch
+		}
+	}
+
+	var tlsConfig *tls.Config
+	var bearerToken, bearerTokenFile string
+	if c.Auth.Kubeconfig {
+		kubeTransportConfig, err := clientConfig.TransportConfig()
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig, err = transport.TLSConfigFor(kubeTransportConfig)
+		if err != nil {
+			return nil, err
+		}
+		bearerToken, bearerTokenFile = kubeTransportConfig.BearerToken, kubeTransportConfig.BearerTokenFile
+	}
+	if len(c.Auth.BearerTokenFile) > 0 {
+		bearerToken, bearerTokenFile = "", c.Auth.BearerTokenFile
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{InsecureSkipVerify: true}
+		}
+	}
+
+	ret := []*BackendSampler{}
+	for _, connectionType := range connectionTypes {
+		var backend *BackendSampler
+		switch c.probeType() {
+		case TCPProbeType:
+			backend = NewProbeBackend(NewTCPProbe(c.Address, connectionType), c.Name, connectionType)
+		case DNSProbeType:
+			backend = NewProbeBackend(NewDNSProbe(c.DNSName, c.DNSServer), c.Name, connectionType)
+		case GRPCProbeType:
+			backend = NewProbeBackend(NewGRPCHealthProbe(c.Address, c.GRPCService, tlsConfig, connectionType), c.Name, connectionType)
+		case WatchProbeType:
+			maxSilence := defaultWatchMaxSilence
+			if c.Watch.MaxSilence != nil {
+				maxSilence = c.Watch.MaxSilence.Duration
+			}
+			backend = NewProbeBackend(NewWatchProbe(watchFn, maxSilence), c.Name, connectionType)
+		default:
+			backend = c.newHTTPBackendSampler(clientConfig, path, connectionType, tlsConfig, bearerToken, bearerTokenFile)
+		}
+		if c.Timeout != nil {
+			backend = backend.WithTimeout(c.Timeout.Duration)
+		}
+		ret = append(ret, backend)
+	}
+	return ret, nil
+}
+
+func (c BackendConfig) newHTTPBackendSampler(clientConfig *rest.Config, path string, connectionType BackendConnectionType, tlsConfig *tls.Config, bearerToken, bearerTokenFile string) *BackendSampler {
+	var backend *BackendSampler
+	switch {
+	case c.Route != nil:
+		backend = NewRouteBackend(clientConfig, c.Route.Namespace, c.Route.Name, c.Name, path, connectionType)
+	case c.Service != nil:
+		scheme := c.Service.Scheme
+		if len(scheme) == 0 {
+			scheme = "http"
+		}
+		backend = NewServiceBackend(clientConfig, c.Service.Namespace, c.Service.Name, c.Service.Port, scheme, c.Name, path, connectionType)
+	default:
+		backend = NewSimpleBackend(strings.TrimSuffix(c.URL, "/"), c.Name, path, connectionType)
+	}
+
+	if tlsConfig != nil {
+		backend = backend.WithTLSConfig(tlsConfig)
+	}
+	if len(bearerToken) > 0 || len(bearerTokenFile) > 0 {
+		backend = backend.WithBearerTokenAuth(bearerToken, bearerTokenFile)
+	}
+	if len(c.ExpectedBody) > 0 {
+		backend = backend.WithExpectedBody(c.ExpectedBody)
+	}
+	if len(c.ExpectedBodyRegex) > 0 {
+		backend = backend.WithExpectedBodyRegex(c.ExpectedBodyRegex)
+	}
+	return backend
+}
@@ -0,0 +1,242 @@
+package backenddisruption
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+func TestReadBackendConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "yaml",
+			content: `backends:
+- name: my-app
+  route:
+    namespace: my-namespace
+    name: my-app
+  path: /healthz
+  expectedBody: ok
+- name: my-lb
+  service:
+    namespace: my-namespace
+    name: my-lb
+    port: 8080
+  connectionTypes: [new]
+  timeout: 5s
+`,
+		},
+		{
+			name: "probes",
+			content: `backends:
+- name: my-lb-tcp
+  probe: tcp
+  address: my-lb.example.com:443
+- name: my-dns
+  probe: dns
+  dnsName: my-app.example.com
+  dnsServer: 10.0.0.10:53
+- name: etcd
+  probe: grpc
+  address: etcd.example.com:2379
+  grpcService: etcd
+  auth:
+    kubeconfig: true
+- name: configmaps
+  probe: watch
+  watch:
+    version: v1
+    resource: configmaps
+    namespace: openshift-config
+    maxSilence: 3m
+`,
+		},
+		{
+			name:    "json",
+			content: `{"backends": [{"name": "external", "url": "https://example.com", "auth": {"bearerTokenFile": "/tmp/token"}}]}`,
+		},
+		{
+			name:    "unknown field",
+			content: `{"backends": [{"name": "external", "url": "https://example.com", "expect": "ok"}]}`,
+			wantErr: "unknown field",
+		},
+		{
+			name:    "no target",
+			content: `{"backends": [{"name": "external"}]}`,
+			wantErr: "exactly one of route, service, and url",
+		},
+		{
+			name:    "two targets",
+			content: `{"backends": [{"name": "external", "url": "https://example.com", "route": {"namespace": "ns", "name": "name"}}]}`,
+			wantErr: "exactly one of route, service, and url",
+		},
+		{
+			name:    "duplicate name",
+			content: `{"backends": [{"name": "external", "url": "https://example.com"}, {"name": "external", "url": "https://example.org"}]}`,
+			wantErr: "duplicate name",
+		},
+		{
+			name:    "bad connection type",
+			content: `{"backends": [{"name": "external", "url": "https://example.com", "connectionTypes": ["old"]}]}`,
+			wantErr: "connectionTypes",
+		},
+		{
+			name:    "unknown probe",
+			content: `{"backends": [{"name": "external", "probe": "icmp", "address": "example.com:443"}]}`,
+			wantErr: "probe must be",
+		},
+		{
+			name:    "probe without its target",
+			content: `{"backends": [{"name": "external", "probe": "tcp", "url": "https://example.com"}]}`,
+			wantErr: "tcp probes require address",
+		},
+		{
+			name:    "probe with an http option",
+			content: `{"backends": [{"name": "external", "probe": "dns", "dnsName": "example.com", "path": "/healthz"}]}`,
+			wantErr: "only apply to http probes",
+		},
+		{
+			name:    "address without a port",
+			content: `{"backends": [{"name": "external", "probe": "grpc", "address": "example.com"}]}`,
+			wantErr: "must be host:port",
+		},
+		{
+			name:    "bad regex",
+			content: `{"backends": [{"name": "external", "url": "https://example.com", "expectedBodyRegex": "("}]}`,
+			wantErr: "expectedBodyRegex",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "backends")
+			if err := ioutil.WriteFile(filename, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := ReadBackendConfigFile(filename)
+			switch {
+			case len(tt.wantErr) == 0 && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case len(tt.wantErr) > 0 && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestBackendConfig_NewBackendSamplers(t *testing.T) {
+	config := BackendConfig{
+		Name:              "my-app",
+		URL:               "https://example.com/",
+		ExpectedBodyRegex: "^ok$",
+		Auth:              BackendAuthConfig{BearerTokenFile: "/tmp/token"},
+		Timeout:           &metav1.Duration{Duration: 5 * time.Second},
+	}
+
+	backends, err := config.NewBackendSamplers(&rest.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backends) != 2 {
+		t.Fatalf("expected a backend for each connection type, got %d", len(backends))
+	}
+	if backends[0].GetLocator() != "disruption/my-app connection/new" || backends[1].GetLocator() != "disruption/my-app connection/reused" {
+		t.Errorf("unexpected locators %q and %q", backends[0].GetLocator(), backends[1].GetLocator())
+	}
+	url, err := backends[0].GetURL()
+	if err != nil || url != "https://example.com/" {
+		t.Errorf("unexpected url %q: %v", url, err)
+	}
+	if backends[0].bearerTokenFile != "/tmp/token" || backends[0].tlsConfig == nil {
+		t.Errorf("expected token auth with a TLS config")
+	}
+	if backends[0].getTimeout() != 5*time.Second {
+		t.Errorf("unexpected timeout %s", backends[0].getTimeout())
+	}
+
+	config = BackendConfig{
+		Name:            "my-lb",
+		Service:         &ServiceBackendConfig{Namespace: "my-namespace", Name: "my-lb"},
+		ConnectionTypes: []BackendConnectionType{ReusedConnectionType},
+	}
+	backends, err = config.NewBackendSamplers(&rest.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backends) != 1 || backends[0].GetLocator() != "ns/my-namespace service/my-lb disruption/my-lb connection/reused" {
+		t.Errorf("unexpected backends %v", backends)
+	}
+}
+
+func TestBackendConfig_NewBackendSamplersWithProbes(t *testing.T) {
+	tests := []struct {
+		config          BackendConfig
+		expectedProbe   BackendProbe
+		expectedLocator string
+	}{
+		{
+			config:          BackendConfig{Name: "my-lb", Probe: TCPProbeType, Address: "127.0.0.1:443"},
+			expectedProbe:   &tcpProbe{},
+			expectedLocator: "disruption/my-lb connection/new",
+		},
+		{
+			config:          BackendConfig{Name: "my-dns", Probe: DNSProbeType, DNSName: "example.com"},
+			expectedProbe:   &dnsProbe{},
+			expectedLocator: "disruption/my-dns connection/new",
+		},
+		{
+			config:          BackendConfig{Name: "etcd", Probe: GRPCProbeType, Address: "127.0.0.1:2379", GRPCService: "etcd"},
+			expectedProbe:   &grpcHealthProbe{},
+			expectedLocator: "disruption/etcd connection/new",
+		},
+		{
+			config:          BackendConfig{Name: "configmaps", Probe: WatchProbeType, Watch: &WatchBackendConfig{Version: "v1", Resource: "configmaps", Namespace: "openshift-config"}},
+			expectedProbe:   &watchProbe{},
+			expectedLocator: "disruption/configmaps connection/reused",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.config.Probe), func(t *testing.T) {
+			backends, err := tt.config.NewBackendSamplers(&rest.Config{Host: "https://127.0.0.1:6443"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if backends[0].GetLocator() != tt.expectedLocator {
+				t.Errorf("unexpected locator %q", backends[0].GetLocator())
+			}
+			if reflect.TypeOf(backends[0].probe) != reflect.TypeOf(tt.expectedProbe) {
+				t.Errorf("expected a %T, got %T", tt.expectedProbe, backends[0].probe)
+			}
+		})
+	}
+
+	// the probe built from the config is what the sampler checks
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	config := BackendConfig{Name: "my-lb", Probe: TCPProbeType, Address: listener.Addr().String(), ConnectionTypes: []BackendConnectionType{NewConnectionType}}
+	backends, err := config.NewBackendSamplers(&rest.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := backends[0].checkConnection(context.Background()); err != nil {
+		t.Errorf("unexpected error from an open port: %v", err)
+	}
+	listener.Close()
+	if err := backends[0].checkConnection(context.Background()); err == nil {
+		t.Errorf("expected an error from a closed port")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"net"
 	"net/http"
+	"net/http/httptrace"
 	"regexp"
 	"sync"
 	"time"
@@ -49,6 +50,9 @@ type BackendSampler struct {
 
 	hostGetter HostGetter
 
+	// probe, if set, checks the backend instead of an HTTP GET of the host+path.
+	probe BackendProbe
+
 	// bearerToken is the token to be used when contacting a server. Authorization : Bearer XXXXXX
 	bearerToken string
 	// bearerTokenFile is the file containing a token to be used when contacting a server. Authorization : Bearer XXXXXX
@@ -65,6 +69,9 @@ type BackendSampler struct {
 	// http status code is accepted.
 	expectRegexp *regexp.Regexp
 
+	// latency is when a HighLatency interval is recorded.  If nil, DefaultLatencyConfig is used.
+	latency *LatencyConfig
+
 	// initHTTPClient ensures we only create the http client once
 	initHTTPClient sync.Once
 	// httpClient is used to connect to the host+path
@@ -113,6 +120,16 @@ func NewBackend(hostGetter HostGetter, disruptionBackendName, path string, conne
 	return ret
 }
 
+// NewProbeBackend constructs a BackendSampler that checks availability with the probe instead of an HTTP GET.
+func NewProbeBackend(probe BackendProbe, disruptionBackendName string, connectionType BackendConnectionType) *BackendSampler {
+	return &BackendSampler{
+		connectionType:        connectionType,
+		locator:               LocateDisruptionCheck(disruptionBackendName, connectionType),
+		disruptionBackendName: disruptionBackendName,
+		probe:                 probe,
+	}
+}
+
 // NewAPIServerBackend constructs a BackendSampler suitable for use against a kube-like API server
 func NewAPIServerBackend(clientConfig *rest.Config, disruptionBackendName, path string, connectionType BackendConnectionType) (*BackendSampler, error) {
 
@@ -150,6 +167,24 @@ func NewRouteBackend(clientConfig *rest.Config, namespace, name, disruptionBacke
 	}
 }
 
+// NewServiceBackend constructs a BackendSampler suitable for use against the load balancer of a type=LoadBalancer
+// service.  If port is zero, the first port of the service is used.
+func NewServiceBackend(clientConfig *rest.Config, namespace, name string, port int32, scheme, disruptionBackendName, path string, connectionType BackendConnectionType) *BackendSampler {
+	return &BackendSampler{
+		connectionType:        connectionType,
+		locator:               LocateServiceForDisruptionCheck(namespace, name, disruptionBackendName, connectionType),
+		disruptionBackendName: disruptionBackendName,
+		path:                  path,
+		hostGetter:            NewServiceHostGetter(clientConfig, namespace, name, port, scheme),
+	}
+}
+
+// WithProbe replaces the HTTP GET of the host+path with the probe.
+func (b *BackendSampler) WithProbe(probe BackendProbe) *BackendSampler {
+	b.probe = probe
+	return b
+}
+
 // WithBearerTokenAuth sets bearer tokens to use
 func (b *BackendSampler) WithBearerTokenAuth(token, tokenFile string) *BackendSampler {
 	b.bearerToken = token
@@ -157,6 +192,12 @@ func (b *BackendSampler) WithBearerTokenAuth(token, tokenFile string) *BackendSa
 	return b
 }
 
+// WithTimeout sets the timeout of a single request.
+func (b *BackendSampler) WithTimeout(timeout time.Duration) *BackendSampler {
+	b.timeout = &timeout
+	return b
+}
+
 // WithTLSConfig sets both the CA bundle for trusting the server and the client cert/key pair for identifying to the server
 func (b *BackendSampler) WithTLSConfig(tlsConfig *tls.Config) *BackendSampler {
 	b.tlsConfig = tlsConfig
@@ -179,6 +220,13 @@ func (b *BackendSampler) WithExpectedBodyRegex(expectedBodyRegex string) *Backen
 	return b
 }
 
+// WithLatencyConfig records a HighLatency interval while the p99 latency of successful requests over the window of
+// latency is above its threshold.  A zero threshold disables the intervals.
+func (b *BackendSampler) WithLatencyConfig(latency LatencyConfig) *BackendSampler {
+	b.latency = &latency
+	return b
+}
+
 // bodyMatches checks the body content and returns an error if it doesn't match the expected.
 func (b *BackendSampler) bodyMatches(body []byte) error {
 	switch {
@@ -203,6 +251,21 @@ func (b *BackendSampler) GetConnectionType() BackendConnectionType {
 	return b.connectionType
 }
 
+// getRequests describes what is sent to the backend for disruption messages.
+func (b *BackendSampler) getRequests() string {
+	if b.probe == nil {
+		return httpGETRequests
+	}
+	return b.probe.Requests()
+}
+
+func (b *BackendSampler) getLatencyConfig() LatencyConfig {
+	if b.latency == nil {
+		return DefaultLatencyConfig()
+	}
+	return *b.latency
+}
+
 func (b *BackendSampler) getTimeout() time.Duration {
 	if b.timeout == nil {
 		return 10 * time.Second
@@ -250,10 +313,10 @@ func (b *BackendSampler) GetHTTPClient() (*http.Client, error) {
 		switch b.GetConnectionType() {
 		case NewConnectionType:
 			httpTransport = &http.Transport{
-				Dial: (&net.Dialer{
+				DialContext: (&net.Dialer{
 					Timeout:   timeoutForPartOfRequest,
 					KeepAlive: -1, // this looks unnecessary to me, but it was set in other code.
-				}).Dial,
+				}).DialContext,
 				TLSClientConfig:       b.getTLSConfig(),
 				DisableKeepAlives:     true, // this prevents connections from being reused
 				TLSHandshakeTimeout:   timeoutForPartOfRequest,
@@ -265,9 +328,9 @@ func (b *BackendSampler) GetHTTPClient() (*http.Client, error) {
 
 		case ReusedConnectionType:
 			httpTransport = &http.Transport{
-				Dial: (&net.Dialer{
+				DialContext: (&net.Dialer{
 					Timeout: timeoutForPartOfRequest,
-				}).Dial,
+				}).DialContext,
 				TLSClientConfig:       b.getTLSConfig(),
 				TLSHandshakeTimeout:   timeoutForPartOfRequest,
 				IdleConnTimeout:       timeoutForPartOfRequest,
@@ -300,29 +363,42 @@ func (b *BackendSampler) GetHTTPClient() (*http.Client, error) {
 }
 
 func (b *BackendSampler) checkConnection(ctx context.Context) error {
+	_, err := b.checkConnectionWithTiming(ctx)
+	return err
+}
+
+// checkConnectionWithTiming checks the backend and returns how long the phases of the request took.
+func (b *BackendSampler) checkConnectionWithTiming(ctx context.Context) (monitorapi.RequestTiming, error) {
+	if b.probe != nil {
+		start := time.Now()
+		err := b.checkProbe(ctx)
+		return monitorapi.RequestTiming{Total: time.Since(start)}, err
+	}
+
 	httpClient, err := b.GetHTTPClient()
 	if err != nil {
-		return err
+		return monitorapi.RequestTiming{}, err
 	}
 
 	url, err := b.GetURL()
 	if err != nil {
-		return err
+		return monitorapi.RequestTiming{}, err
 	}
 
 	// this is longer than the http client timeout to avoid tripping, but is here to be sure we finish eventually
 	backstopContextTimeout := b.getTimeout() * 3 / 2 // (1.5)
 	requestContext, requestCancel := context.WithTimeout(ctx, backstopContextTimeout)
 	defer requestCancel()
-	req, err := http.NewRequestWithContext(requestContext, http.MethodGet, url, nil)
+	trace, clientTrace := newRequestTrace(time.Now())
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(requestContext, clientTrace), http.MethodGet, url, nil)
 	if err != nil {
-		return err
+		return monitorapi.RequestTiming{}, err
 	}
 
 	resp, getErr := httpClient.Do(req)
 	if requestContext.Err() == context.Canceled {
 		// this isn't an error, we were simply cancelled
-		return nil
+		return monitorapi.RequestTiming{}, nil
 	}
 
 	var body []byte
@@ -333,6 +409,7 @@ func (b *BackendSampler) checkConnection(ctx context.Context) error {
 			framework.Logf("error closing body: %v: %v", b.GetLocator(), closeErr)
 		}
 	}
+	timing := trace.finish()
 
 	// we don't have an error, but the response code was an error, then we have to set an artificial error for the logic below to work.
 	switch {
@@ -348,7 +425,20 @@ func (b *BackendSampler) checkConnection(ctx context.Context) error {
 		}
 	}
 
-	return sampleErr
+	return timing, sampleErr
+}
+
+func (b *BackendSampler) checkProbe(ctx context.Context) error {
+	// probes are given the same time as an entire http request
+	requestContext, requestCancel := context.WithTimeout(ctx, b.getTimeout())
+	defer requestCancel()
+
+	err := b.probe.Check(requestContext)
+	if ctx.Err() == context.Canceled {
+		// this isn't an error, we were simply cancelled
+		return nil
+	}
+	return err
 }
 
 // RunEndpointMonitoring sets up a client for the given BackendSampler, starts checking the endpoint, and recording
@@ -392,6 +482,15 @@ func (b *BackendSampler) RunEndpointMonitoring(ctx context.Context, monitorRecor
 		eventRecorder = fakeEventRecorder
 	}
 
+	// probes that hold a connection open run for as long as we produce samples
+	if runnable, ok := b.probe.(runnableBackendProbe); ok {
+		go runnable.Run(producerContext)
+		select {
+		case <-runnable.Started():
+		case <-producerContext.Done():
+		}
+	}
+
 	interval := 1 * time.Second
 	disruptionSampler := newDisruptionSampler(b)
 	go disruptionSampler.produceSamples(producerContext, interval)
@@ -473,8 +572,8 @@ func (b *disruptionSampler) produceSamples(ctx context.Context, interval time.Du
 		// was actually 30s before.
 		currDisruptionSample := b.newSample(ctx)
 		go func() {
-			sampleErr := b.backendSampler.checkConnection(ctx)
-			currDisruptionSample.setSampleError(sampleErr)
+			timing, sampleErr := b.backendSampler.checkConnectionWithTiming(ctx)
+			currDisruptionSample.setSampleResult(timing, sampleErr)
 			close(currDisruptionSample.finished)
 		}()
 
@@ -493,12 +592,19 @@ func (b *disruptionSampler) consumeSamples(ctx context.Context, interval time.Du
 	previousIntervalID := -1
 	var previousSampleTime *time.Time
 
+	latencyRecorder, _ := monitorRecorder.(LatencyRecorder)
+	latency := b.backendSampler.getLatencyConfig()
+	latencyWindow := newLatencyWindow(b.backendSampler.GetLocator(), latency.Threshold, latency.Window)
+
 	// when we exit this function, we want to set a final duration of failure.  We don't actually know whether it ended
 	// or how long it took to ask
 	defer func() {
 		if previousIntervalID != -1 && previousSampleTime != nil {
 			monitorRecorder.EndInterval(previousIntervalID, previousSampleTime.Add(interval))
 		}
+		if previousSampleTime != nil {
+			latencyWindow.finish(previousSampleTime.Add(interval), monitorRecorder)
+		}
 	}()
 
 	for {
@@ -530,6 +636,15 @@ func (b *disruptionSampler) consumeSamples(ctx context.Context, interval time.Du
 		currentlyAvailable := currentError == nil
 		currSampleTime := currSample.startTime
 
+		// failed requests are covered by disruption intervals, only the latency of successful ones is interesting
+		if currentlyAvailable {
+			timing := currSample.getTiming()
+			if latencyRecorder != nil {
+				latencyRecorder.RecordLatency(b.backendSampler.GetLocator(), currSampleTime, timing)
+			}
+			latencyWindow.observe(currSampleTime, timing.Total, monitorRecorder)
+		}
+
 		switch {
 		case currentlyAvailable && previouslyAvailable:
 			// we are continuing to function.  no condition change.
@@ -548,7 +663,7 @@ func (b *disruptionSampler) consumeSamples(ctx context.Context, interval time.Du
 			}
 
 			// start a new interval with the new error
-			message := DisruptionBeganMessage(b.backendSampler.GetLocator(), b.backendSampler.GetConnectionType(), currentError)
+			message := DisruptionBeganMessageForRequests(b.backendSampler.GetLocator(), b.backendSampler.getRequests(), b.backendSampler.GetConnectionType(), currentError)
 			framework.Logf(message)
 			eventRecorder.Eventf(
 				&v1.ObjectReference{Kind: "OpenShiftTest", Namespace: "kube-system", Name: b.backendSampler.GetDisruptionBackendName()}, nil,
@@ -566,7 +681,7 @@ func (b *disruptionSampler) consumeSamples(ctx context.Context, interval time.Du
 				monitorRecorder.EndInterval(previousIntervalID, currSample.startTime)
 			}
 
-			message := DisruptionEndedMessage(b.backendSampler.GetLocator(), b.backendSampler.GetConnectionType())
+			message := DisruptionEndedMessageForRequests(b.backendSampler.GetLocator(), b.backendSampler.getRequests(), b.backendSampler.GetConnectionType())
 			framework.Logf(message)
 			eventRecorder.Eventf(
 				&v1.ObjectReference{Kind: "OpenShiftTest", Namespace: "kube-system", Name: b.backendSampler.GetDisruptionBackendName()}, nil,
@@ -584,7 +699,7 @@ func (b *disruptionSampler) consumeSamples(ctx context.Context, interval time.Du
 				monitorRecorder.EndInterval(previousIntervalID, currSample.startTime)
 			}
 
-			message := DisruptionBeganMessage(b.backendSampler.GetLocator(), b.backendSampler.GetConnectionType(), currentError)
+			message := DisruptionBeganMessageForRequests(b.backendSampler.GetLocator(), b.backendSampler.getRequests(), b.backendSampler.GetConnectionType(), currentError)
 			framework.Logf(message)
 			eventRecorder.Eventf(
 				&v1.ObjectReference{Kind: "OpenShiftTest", Namespace: "kube-system", Name: b.backendSampler.GetDisruptionBackendName()}, nil,
@@ -638,6 +753,7 @@ type disruptionSample struct {
 	lock      sync.Mutex
 	startTime time.Time
 	sampleErr error
+	timing    monitorapi.RequestTiming
 
 	finished chan struct{}
 }
@@ -653,6 +769,17 @@ func (s *disruptionSample) setSampleError(sampleErr error) {
 	defer s.lock.Unlock()
 	s.sampleErr = sampleErr
 }
+func (s *disruptionSample) setSampleResult(timing monitorapi.RequestTiming, sampleErr error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.timing = timing
+	s.sampleErr = sampleErr
+}
+func (s *disruptionSample) getTiming() monitorapi.RequestTiming {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.timing
+}
 func (s *disruptionSample) getSampleError() error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
@@ -13,28 +13,44 @@ func LocateRouteForDisruptionCheck(ns, name, disruptionBackendName string, conne
 	return fmt.Sprintf("ns/%s route/%s disruption/%s connection/%s", ns, name, disruptionBackendName, connectionType)
 }
 
+func LocateServiceForDisruptionCheck(ns, name, disruptionBackendName string, connectionType BackendConnectionType) string {
+	return fmt.Sprintf("ns/%s service/%s disruption/%s connection/%s", ns, name, disruptionBackendName, connectionType)
+}
+
 func LocateDisruptionCheck(disruptionBackendName string, connectionType BackendConnectionType) string {
 	return fmt.Sprintf("disruption/%s connection/%s", disruptionBackendName, connectionType)
 }
 
 func DisruptionEndedMessage(locator string, connectionType BackendConnectionType) string {
+	return DisruptionEndedMessageForRequests(locator, httpGETRequests, connectionType)
+}
+
+func DisruptionBeganMessage(locator string, connectionType BackendConnectionType, err error) string {
+	return DisruptionBeganMessageForRequests(locator, httpGETRequests, connectionType, err)
+}
+
+// DisruptionEndedMessageForRequests describes the end of a disruption for a backend checked with something other than
+// GET requests.  requests describes what the probe sends, for instance "DNS lookups".
+func DisruptionEndedMessageForRequests(locator, requests string, connectionType BackendConnectionType) string {
 	switch connectionType {
 	case NewConnectionType:
-		return fmt.Sprintf("%s started responding to GET requests over new connections", locator)
+		return fmt.Sprintf("%s started responding to %s over new connections", locator, requests)
 	case ReusedConnectionType:
-		return fmt.Sprintf("%s started responding to GET requests over reused connections", locator)
+		return fmt.Sprintf("%s started responding to %s over reused connections", locator, requests)
 	default:
-		return fmt.Sprintf("%s started responding to GET requests over %v connections", locator, "Unknown")
+		return fmt.Sprintf("%s started responding to %s over %v connections", locator, requests, "Unknown")
 	}
 }
 
-func DisruptionBeganMessage(locator string, connectionType BackendConnectionType, err error) string {
+// DisruptionBeganMessageForRequests describes the start of a disruption for a backend checked with something other
+// than GET requests.  requests describes what the probe sends, for instance "DNS lookups".
+func DisruptionBeganMessageForRequests(locator, requests string, connectionType BackendConnectionType, err error) string {
 	switch connectionType {
 	case NewConnectionType:
-		return fmt.Sprintf("%s stopped responding to GET requests over new connections: %v", locator, err)
+		return fmt.Sprintf("%s stopped responding to %s over new connections: %v", locator, requests, err)
 	case ReusedConnectionType:
-		return fmt.Sprintf("%s stopped responding to GET requests over reused connections: %v", locator, err)
+		return fmt.Sprintf("%s stopped responding to %s over reused connections: %v", locator, requests, err)
 	default:
-		return fmt.Sprintf("%s stopped responding to GET requests over %v connections: %v", locator, "Unknown", err)
+		return fmt.Sprintf("%s stopped responding to %s over %v connections: %v", locator, requests, "Unknown", err)
 	}
 }
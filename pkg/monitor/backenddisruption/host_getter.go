@@ -3,10 +3,13 @@ package backenddisruption
 import (
 	"context"
 	"fmt"
+	"net"
+	"strconv"
 	"sync"
 
 	routeclientset "github.com/openshift/client-go/route/clientset/versioned"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
 
@@ -101,3 +104,68 @@ func (g *routeHostGetter) GetHost() (string, error) {
 	}
 	return g.host, nil
 }
+
+type serviceHostGetter struct {
+	clientConfig     *rest.Config
+	serviceNamespace string
+	serviceName      string
+	// port is the port of the service to connect to.  If zero, the first port is used.
+	port   int32
+	scheme string
+
+	// initializeHost is used to ensure we only look up the service once instead of on every request
+	initializeHost sync.Once
+	// host is the scheme://host:port part of the URL
+	host string
+	// hostErr is the error (if we got one) from initializeHost.
+	hostErr error
+}
+
+// NewServiceHostGetter returns a HostGetter for the load balancer ingress of a type=LoadBalancer service, the same
+// way a client outside the cluster would reach it.
+func NewServiceHostGetter(clientConfig *rest.Config, serviceNamespace, serviceName string, port int32, scheme string) HostGetter {
+	return &serviceHostGetter{
+		clientConfig:     clientConfig,
+		serviceNamespace: serviceNamespace,
+		serviceName:      serviceName,
+		port:             port,
+		scheme:           scheme,
+	}
+}
+
+func (g *serviceHostGetter) GetHost() (string, error) {
+	g.initializeHost.Do(func() {
+		client, err := kubernetes.NewForConfig(g.clientConfig)
+		if err != nil {
+			g.hostErr = err
+			return
+		}
+		service, err := client.CoreV1().Services(g.serviceNamespace).Get(context.Background(), g.serviceName, metav1.GetOptions{})
+		if err != nil {
+			g.hostErr = err
+			return
+		}
+		port := g.port
+		if port == 0 && len(service.Spec.Ports) > 0 {
+			port = service.Spec.Ports[0].Port
+		}
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			host := ingress.Hostname
+			if len(host) == 0 {
+				host = ingress.IP
+			}
+			if len(host) > 0 {
+				g.host = fmt.Sprintf("%s://%s", g.scheme, net.JoinHostPort(host, strconv.Itoa(int(port))))
+				break
+			}
+		}
+	})
+
+	if g.hostErr != nil {
+		return "", g.hostErr
+	}
+	if len(g.host) == 0 {
+		return "", fmt.Errorf("service %s/%s has no load balancer ingress", g.serviceNamespace, g.serviceName)
+	}
+	return g.host, nil
+}
@@ -0,0 +1,185 @@
+package backenddisruption
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http/httptrace"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+const (
+	// DefaultLatencyThreshold is the p99 latency over the latency window above which a BackendSampler records a
+	// HighLatency interval, so that a backend which slows down without failing is visible.
+	DefaultLatencyThreshold = 5 * time.Second
+	// DefaultLatencyWindow is how far back a BackendSampler looks when computing p99 latency.
+	DefaultLatencyWindow = 1 * time.Minute
+)
+
+// LatencyConfig is when a BackendSampler records HighLatency intervals.
+type LatencyConfig struct {
+	// Threshold is the p99 latency over Window above which a HighLatency interval is recorded.  Zero disables the
+	// intervals.
+	Threshold time.Duration
+	// Window is how far back the p99 latency looks.
+	Window time.Duration
+}
+
+// DefaultLatencyConfig returns the latency config of a BackendSampler that was not given one.
+func DefaultLatencyConfig() LatencyConfig {
+	return LatencyConfig{
+		Threshold: DefaultLatencyThreshold,
+		Window:    DefaultLatencyWindow,
+	}
+}
+
+// minLatencyWindowSamples avoids reporting a p99 computed from a handful of samples, for instance right after startup
+// or an outage.
+const minLatencyWindowSamples = 10
+
+// HighLatencyReason is the reason of intervals recorded while a backend's p99 latency is above the threshold.
+const HighLatencyReason monitorapi.IntervalReason = "HighLatency"
+
+// requestTrace collects the phase timings of a single HTTP request.  The callbacks can run concurrently, for
+// instance when several addresses are dialed at once.
+type requestTrace struct {
+	lock         sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	timing       monitorapi.RequestTiming
+}
+
+func newRequestTrace(start time.Time) (*requestTrace, *httptrace.ClientTrace) {
+	t := &requestTrace{start: start}
+	return t, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			t.timing.DNS = time.Since(t.dnsStart)
+		},
+		ConnectStart: func(network, addr string) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(network, addr string, err error) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			t.timing.Connect = time.Since(t.connectStart)
+		},
+		TLSHandshakeStart: func() {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			t.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			t.timing.TLSHandshake = time.Since(t.tlsStart)
+		},
+		GotFirstResponseByte: func() {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			t.timing.FirstByte = time.Since(t.start)
+		},
+	}
+}
+
+// finish records the total time of the request and returns the timings.
+func (t *requestTrace) finish() monitorapi.RequestTiming {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.timing.Total = time.Since(t.start)
+	return t.timing
+}
+
+type latencySample struct {
+	at      time.Time
+	latency time.Duration
+}
+
+// latencyWindow tracks the p99 latency of the successful samples in a sliding window and records an interval for as
+// long as it is above the threshold.
+type latencyWindow struct {
+	locator   string
+	threshold time.Duration
+	window    time.Duration
+
+	samples []latencySample
+	// intervalID is the open HighLatency interval, or -1
+	intervalID int
+}
+
+func newLatencyWindow(locator string, threshold, window time.Duration) *latencyWindow {
+	return &latencyWindow{
+		locator:    locator,
+		threshold:  threshold,
+		window:     window,
+		intervalID: -1,
+	}
+}
+
+// observe adds a sample and starts or ends the HighLatency interval when the p99 crosses the threshold.
+func (w *latencyWindow) observe(at time.Time, latency time.Duration, monitorRecorder Recorder) {
+	if w.threshold <= 0 {
+		return
+	}
+
+	w.samples = append(w.samples, latencySample{at: at, latency: latency})
+	oldest := 0
+	for oldest < len(w.samples) && at.Sub(w.samples[oldest].at) > w.window {
+		oldest++
+	}
+	w.samples = w.samples[oldest:]
+	if len(w.samples) < minLatencyWindowSamples {
+		return
+	}
+
+	p99 := w.p99()
+	switch {
+	case p99 > w.threshold && w.intervalID == -1:
+		message := monitorapi.NewMessage(HighLatencyReason,
+			fmt.Sprintf("p99 latency over the last %s was %s, above %s", w.window, p99.Round(time.Millisecond), w.threshold))
+		condition := monitorapi.NormalizeCondition(monitorapi.Condition{
+			Level:             monitorapi.Warning,
+			Locator:           w.locator,
+			StructuredMessage: message,
+		})
+		w.intervalID = monitorRecorder.StartInterval(at, condition)
+
+	case p99 <= w.threshold && w.intervalID != -1:
+		monitorRecorder.EndInterval(w.intervalID, at)
+		w.intervalID = -1
+	}
+}
+
+// finish ends the HighLatency interval if one is open.
+func (w *latencyWindow) finish(at time.Time, monitorRecorder Recorder) {
+	if w.intervalID != -1 {
+		monitorRecorder.EndInterval(w.intervalID, at)
+		w.intervalID = -1
+	}
+}
+
+func (w *latencyWindow) p99() time.Duration {
+	latencies := make([]time.Duration, 0, len(w.samples))
+	for _, sample := range w.samples {
+		latencies = append(latencies, sample.latency)
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	// the smallest latency at or above 99% of the samples
+	index := (len(latencies)*99+99)/100 - 1
+	return latencies[index]
+}
@@ -0,0 +1,96 @@
+package backenddisruption
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestBackendSampler_checkConnectionWithTiming(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(200)
+		w.Write([]byte("200"))
+	}))
+	defer testServer.Close()
+
+	backend := NewSimpleBackend(testServer.URL, "slow", "/", NewConnectionType)
+	timing, err := backend.checkConnectionWithTiming(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if timing.FirstByte < 20*time.Millisecond {
+		t.Errorf("expected the time to first byte to include the server delay, got %s", timing.FirstByte)
+	}
+	if timing.Total < timing.FirstByte {
+		t.Errorf("expected the total %s to be at least the time to first byte %s", timing.Total, timing.FirstByte)
+	}
+	if timing.Connect <= 0 || timing.TLSHandshake <= 0 {
+		t.Errorf("expected connect and TLS handshake times for a new connection, got %+v", timing)
+	}
+}
+
+func TestBackendSampler_WithLatencyConfig(t *testing.T) {
+	backend := NewSimpleBackend("https://example.com", "slow", "/", NewConnectionType)
+	if got := backend.getLatencyConfig(); got != DefaultLatencyConfig() {
+		t.Errorf("expected the default latency config without one set, got %+v", got)
+	}
+
+	latency := LatencyConfig{Threshold: 2 * time.Second, Window: 5 * time.Minute}
+	backend.WithLatencyConfig(latency)
+	latency.Threshold = 0
+	if got := backend.getLatencyConfig(); got != (LatencyConfig{Threshold: 2 * time.Second, Window: 5 * time.Minute}) {
+		t.Errorf("expected the latency config that was set, got %+v", got)
+	}
+	if other := NewSimpleBackend("https://example.com", "other", "/", NewConnectionType); other.getLatencyConfig() != DefaultLatencyConfig() {
+		t.Errorf("expected the latency config of one sampler not to change another, got %+v", other.getLatencyConfig())
+	}
+}
+
+func Test_latencyWindow(t *testing.T) {
+	monitor := newSimpleMonitor()
+	locator := "disruption/slow connection/new"
+	window := newLatencyWindow(locator, 100*time.Millisecond, 10*time.Second)
+	start := time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	at := start
+	observe := func(count int, latency time.Duration) {
+		for i := 0; i < count; i++ {
+			at = at.Add(time.Second)
+			window.observe(at, latency, monitor)
+		}
+	}
+
+	// nothing is reported until there are enough samples, then a single slow request is the p99
+	observe(9, 10*time.Millisecond)
+	observe(1, time.Second)
+	if intervals := monitor.Intervals(time.Time{}, time.Time{}); len(intervals) != 1 {
+		t.Fatalf("expected a HighLatency interval with ten samples in the window, got %v", intervals)
+	}
+
+	// the slow request leaves the window
+	observe(11, 10*time.Millisecond)
+	intervals := monitor.Intervals(time.Time{}, time.Time{})
+	if len(intervals) != 1 {
+		t.Fatalf("expected one interval, got %v", intervals)
+	}
+	interval := intervals[0]
+	if interval.Level != monitorapi.Warning || interval.Locator != locator || !strings.Contains(interval.Message, "reason/HighLatency") {
+		t.Errorf("unexpected interval %v", interval)
+	}
+	if !interval.From.Equal(start.Add(10*time.Second)) || !interval.To.Equal(start.Add(21*time.Second)) {
+		t.Errorf("unexpected interval bounds %s to %s", interval.From, interval.To)
+	}
+
+	observe(10, time.Second)
+	window.finish(at.Add(time.Second), monitor)
+	intervals = monitor.Intervals(time.Time{}, time.Time{})
+	if len(intervals) != 2 || !intervals[1].To.Equal(at.Add(time.Second)) {
+		t.Errorf("expected the open interval to be ended by finish, got %v", intervals)
+	}
+}
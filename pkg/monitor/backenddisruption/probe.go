@@ -0,0 +1,343 @@
+package backenddisruption
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// httpGETRequests describes the requests made by a BackendSampler without a probe.
+const httpGETRequests = "GET requests"
+
+// BackendProbe checks whether a backend is available.  A BackendSampler with a probe calls Check once per sample
+// instead of issuing an HTTP GET, and the results produce the same disruption intervals.
+type BackendProbe interface {
+	// Check returns an error if the backend is unavailable.  The context is cancelled when the sampler's timeout
+	// expires.
+	Check(ctx context.Context) error
+	// Requests describes what the probe sends to the backend, for instance "DNS lookups".  It is used in the
+	// disruption messages.
+	Requests() string
+}
+
+// runnableBackendProbe is a BackendProbe that does work between checks.  Run is called once when monitoring starts
+// and returns when the context is done.  Started is closed once Run has made its first attempt, successful or not,
+// and no samples are taken before then so a backend that was not tried yet is not reported as disrupted.
+type runnableBackendProbe interface {
+	BackendProbe
+	Run(ctx context.Context)
+	Started() <-chan struct{}
+}
+
+// tcpProbe dials an address.  With reused connections, the connection is kept open between checks and the check fails
+// when the remote end closes it.
+type tcpProbe struct {
+	address        string
+	connectionType BackendConnectionType
+
+	lock sync.Mutex
+	conn net.Conn
+}
+
+// NewTCPProbe returns a probe that opens a TCP connection to address (host:port).
+func NewTCPProbe(address string, connectionType BackendConnectionType) BackendProbe {
+	return &tcpProbe{
+		address:        address,
+		connectionType: connectionType,
+	}
+}
+
+func (p *tcpProbe) Requests() string {
+	return "TCP connections"
+}
+
+func (p *tcpProbe) Check(ctx context.Context) error {
+	if p.connectionType != ReusedConnectionType {
+		conn, err := p.dial(ctx)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.conn == nil {
+		conn, err := p.dial(ctx)
+		if err != nil {
+			return err
+		}
+		p.conn = conn
+		return nil
+	}
+
+	// nothing is ever sent to us, so a read that times out means the connection is still open
+	if err := p.conn.SetReadDeadline(time.Now().Add(10 * time.Millisecond)); err != nil {
+		return p.dropConnection(err)
+	}
+	_, err := p.conn.Read(make([]byte, 1))
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return nil
+	case errors.Is(err, io.EOF):
+		return p.dropConnection(fmt.Errorf("connection to %s was closed", p.address))
+	default:
+		return p.dropConnection(err)
+	}
+}
+
+func (p *tcpProbe) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{KeepAlive: -1}
+	return dialer.DialContext(ctx, "tcp", p.address)
+}
+
+// dropConnection closes the reused connection so that the next check dials again.  Must be called with the lock held.
+func (p *tcpProbe) dropConnection(err error) error {
+	p.conn.Close()
+	p.conn = nil
+	return err
+}
+
+// dnsProbe resolves a name.  Lookups bypass the cgo resolver so that results are not cached by the host.
+type dnsProbe struct {
+	name     string
+	resolver *net.Resolver
+}
+
+// NewDNSProbe returns a probe that resolves name.  If server (host:port) is set, it is queried instead of the servers
+// configured on the host.
+func NewDNSProbe(name, server string) BackendProbe {
+	resolver := &net.Resolver{PreferGo: true}
+	if len(server) > 0 {
+		resolver.Dial = func(ctx context.Context, network, address string) (net.Conn, error) {
+			dialer := &net.Dialer{}
+			return dialer.DialContext(ctx, network, server)
+		}
+	}
+	return &dnsProbe{
+		name:     name,
+		resolver: resolver,
+	}
+}
+
+func (p *dnsProbe) Requests() string {
+	return "DNS lookups"
+}
+
+func (p *dnsProbe) Check(ctx context.Context) error {
+	addresses, err := p.resolver.LookupHost(ctx, p.name)
+	if err != nil {
+		return err
+	}
+	if len(addresses) == 0 {
+		return fmt.Errorf("lookup %s returned no addresses", p.name)
+	}
+	return nil
+}
+
+// grpcHealthProbe calls the standard grpc.health.v1.Health/Check method.
+type grpcHealthProbe struct {
+	address        string
+	service        string
+	tlsConfig      *tls.Config
+	connectionType BackendConnectionType
+
+	lock sync.Mutex
+	conn *grpc.ClientConn
+}
+
+// NewGRPCHealthProbe returns a probe that checks that service is SERVING at address (host:port).  An empty service
+// checks the server as a whole.  If tlsConfig is nil, the connection is not encrypted.
+func NewGRPCHealthProbe(address, service string, tlsConfig *tls.Config, connectionType BackendConnectionType) BackendProbe {
+	return &grpcHealthProbe{
+		address:        address,
+		service:        service,
+		tlsConfig:      tlsConfig,
+		connectionType: connectionType,
+	}
+}
+
+func (p *grpcHealthProbe) Requests() string {
+	return "gRPC health checks"
+}
+
+func (p *grpcHealthProbe) Check(ctx context.Context) error {
+	conn, err := p.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+	if p.connectionType != ReusedConnectionType {
+		defer conn.Close()
+	}
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: p.service})
+	if err != nil {
+		return err
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("service %q is %v", p.service, resp.Status)
+	}
+	return nil
+}
+
+func (p *grpcHealthProbe) getConnection(ctx context.Context) (*grpc.ClientConn, error) {
+	if p.connectionType == ReusedConnectionType {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		if p.conn != nil {
+			return p.conn, nil
+		}
+	}
+
+	transportOption := grpc.WithInsecure()
+	if p.tlsConfig != nil {
+		transportOption = grpc.WithTransportCredentials(credentials.NewTLS(p.tlsConfig))
+	}
+	conn, err := grpc.DialContext(ctx, p.address, transportOption, grpc.WithBlock())
+	if err != nil {
+		return nil, err
+	}
+	if p.connectionType == ReusedConnectionType {
+		// the lock is held
+		p.conn = conn
+	}
+	return conn, nil
+}
+
+// WatchFunc starts a watch, for instance the Watch method of a typed client.
+type WatchFunc func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error)
+
+// watchProbe keeps a watch open and fails when the watch cannot be established or stops delivering events.  The
+// server sends bookmarks to otherwise idle watches, so a healthy watch is never silent for long.
+type watchProbe struct {
+	watchFn    WatchFunc
+	maxSilence time.Duration
+
+	lock sync.Mutex
+	// open is true once a watch has been established and until establishing one fails.
+	open bool
+	// watchErr is the most recent reason the watch is not open.
+	watchErr error
+	// lastDelivery is the last time an event was delivered or a watch was established.
+	lastDelivery time.Time
+	// started is closed once the first watch was established or failed.
+	started     chan struct{}
+	startedOnce sync.Once
+}
+
+// NewWatchProbe returns a probe that keeps a watch started by watchFn open with bookmarks enabled.  The check fails
+// if the watch is closed and cannot be reopened, or if nothing was delivered for maxSilence.
+func NewWatchProbe(watchFn WatchFunc, maxSilence time.Duration) BackendProbe {
+	return &watchProbe{
+		watchFn:    watchFn,
+		maxSilence: maxSilence,
+		watchErr:   fmt.Errorf("watch has not been started"),
+		started:    make(chan struct{}),
+	}
+}
+
+func (p *watchProbe) Requests() string {
+	return "watch requests"
+}
+
+func (p *watchProbe) Check(ctx context.Context) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if !p.open {
+		return p.watchErr
+	}
+	if silence := time.Since(p.lastDelivery); silence > p.maxSilence {
+		return fmt.Errorf("watch delivered no events or bookmarks for %s", silence.Round(time.Second))
+	}
+	return nil
+}
+
+func (p *watchProbe) Started() <-chan struct{} {
+	return p.started
+}
+
+// Run keeps a watch open until the context is done, resuming from the last delivered resourceVersion.
+func (p *watchProbe) Run(ctx context.Context) {
+	// the server closes watches after the timeout, which also bounds how long a silently broken watch can linger
+	timeoutSeconds := int64(5 * 60)
+	resourceVersion := ""
+	for ctx.Err() == nil {
+		w, err := p.watchFn(ctx, metav1.ListOptions{
+			AllowWatchBookmarks: true,
+			ResourceVersion:     resourceVersion,
+			TimeoutSeconds:      &timeoutSeconds,
+		})
+		if err != nil {
+			p.closed(err)
+			select {
+			case <-time.After(time.Second):
+			case <-ctx.Done():
+			}
+			continue
+		}
+		p.delivered()
+
+		resourceVersion = p.consume(ctx, w, resourceVersion)
+		w.Stop()
+	}
+}
+
+// consume reads events until the watch ends and returns the resourceVersion to resume from.
+func (p *watchProbe) consume(ctx context.Context, w watch.Interface, resourceVersion string) string {
+	for {
+		select {
+		case <-ctx.Done():
+			return resourceVersion
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				// a watch ending is normal, only failing to reopen it is a problem
+				return resourceVersion
+			}
+			if event.Type == watch.Error {
+				err := apierrors.FromObject(event.Object)
+				p.closed(err)
+				if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+					return ""
+				}
+				return resourceVersion
+			}
+			if metadata, err := meta.Accessor(event.Object); err == nil && len(metadata.GetResourceVersion()) > 0 {
+				resourceVersion = metadata.GetResourceVersion()
+			}
+			p.delivered()
+		}
+	}
+}
+
+func (p *watchProbe) delivered() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.open = true
+	p.watchErr = nil
+	p.lastDelivery = time.Now()
+	p.startedOnce.Do(func() { close(p.started) })
+}
+
+func (p *watchProbe) closed(err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.open = false
+	p.watchErr = err
+	p.startedOnce.Do(func() { close(p.started) })
+}
@@ -0,0 +1,204 @@
+package backenddisruption
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestTCPProbe(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accepted := make(chan net.Conn, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+	address := listener.Addr().String()
+	ctx := context.Background()
+
+	newProbe := NewTCPProbe(address, NewConnectionType)
+	if err := newProbe.Check(ctx); err != nil {
+		t.Errorf("new connection: unexpected error: %v", err)
+	}
+
+	reusedProbe := NewTCPProbe(address, ReusedConnectionType)
+	if err := reusedProbe.Check(ctx); err != nil {
+		t.Fatalf("reused connection: unexpected error: %v", err)
+	}
+	if err := reusedProbe.Check(ctx); err != nil {
+		t.Errorf("reused connection: unexpected error on open connection: %v", err)
+	}
+
+	// the server dropping the connection is a disruption, even though a new connection would succeed
+	<-accepted
+	serverSide := <-accepted
+	serverSide.Close()
+	time.Sleep(50 * time.Millisecond)
+	if err := reusedProbe.Check(ctx); err == nil {
+		t.Errorf("reused connection: expected an error after the server closed the connection")
+	}
+
+	listener.Close()
+	if err := newProbe.Check(ctx); err == nil {
+		t.Errorf("new connection: expected an error after the listener closed")
+	}
+}
+
+func TestDNSProbe(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// literal addresses do not need a server
+	if err := NewDNSProbe("127.0.0.1", "").Check(ctx); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// nothing answers on the discard port, so the lookup must fail rather than fall back to the host's servers
+	if err := NewDNSProbe("example.invalid", "127.0.0.1:9").Check(ctx); err == nil {
+		t.Errorf("expected an error from an unreachable server")
+	}
+}
+
+func TestGRPCHealthProbe(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	healthServer := health.NewServer()
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, connectionType := range []BackendConnectionType{NewConnectionType, ReusedConnectionType} {
+		t.Run(string(connectionType), func(t *testing.T) {
+			healthServer.SetServingStatus("etcd", healthpb.HealthCheckResponse_SERVING)
+			probe := NewGRPCHealthProbe(listener.Addr().String(), "etcd", nil, connectionType)
+			if err := probe.Check(ctx); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			healthServer.SetServingStatus("etcd", healthpb.HealthCheckResponse_NOT_SERVING)
+			if err := probe.Check(ctx); err == nil || !strings.Contains(err.Error(), "NOT_SERVING") {
+				t.Errorf("expected NOT_SERVING error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWatchProbe(t *testing.T) {
+	watches := make(chan *watch.FakeWatcher, 10)
+	var lastOptions metav1.ListOptions
+	probe := NewWatchProbe(func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+		lastOptions = options
+		w := watch.NewFake()
+		watches <- w
+		return w, nil
+	}, 100*time.Millisecond).(*watchProbe)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go probe.Run(ctx)
+
+	w := <-watches
+	<-probe.Started()
+	if err := waitForCheck(ctx, probe); err != nil {
+		t.Errorf("unexpected error for a new watch: %v", err)
+	}
+	if !lastOptions.AllowWatchBookmarks {
+		t.Errorf("bookmarks were not requested")
+	}
+
+	w.Action(watch.Bookmark, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "42"}})
+	time.Sleep(150 * time.Millisecond)
+	if err := probe.Check(ctx); err == nil || !strings.Contains(err.Error(), "no events or bookmarks") {
+		t.Errorf("expected a silent watch to fail, got %v", err)
+	}
+
+	// closing the watch is normal, it is reopened from the last bookmark
+	w.Stop()
+	<-watches
+	if err := waitForCheck(ctx, probe); err != nil {
+		t.Errorf("unexpected error for a reopened watch: %v", err)
+	}
+	if lastOptions.ResourceVersion != "42" {
+		t.Errorf("watch was not resumed from the bookmark: %q", lastOptions.ResourceVersion)
+	}
+}
+
+func TestBackendSampler_watchProbeStartup(t *testing.T) {
+	// a watch that takes longer to establish than the first samples
+	probe := NewWatchProbe(func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+		time.Sleep(1500 * time.Millisecond)
+		return watch.NewFake(), nil
+	}, time.Minute)
+	backend := NewProbeBackend(probe, "watch", ReusedConnectionType).WithTimeout(500 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	monitor := newSimpleMonitor()
+	if err := backend.RunEndpointMonitoring(ctx, monitor, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, interval := range monitor.Intervals(time.Time{}, time.Time{}) {
+		if interval.Level == monitorapi.Error {
+			t.Errorf("expected no disruption while the watch was being started, got %s", interval)
+		}
+	}
+}
+
+// waitForCheck gives a probe's background work a moment to catch up before its check is expected to pass.
+func waitForCheck(ctx context.Context, probe BackendProbe) error {
+	var err error
+	for i := 0; i < 100; i++ {
+		if err = probe.Check(ctx); err == nil {
+			return nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return err
+}
+
+func TestBackendSampler_checkConnectionWithProbe(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	backend := NewProbeBackend(NewTCPProbe(address, NewConnectionType), "lb", NewConnectionType)
+	if backend.GetLocator() != "disruption/lb connection/new" {
+		t.Errorf("unexpected locator %q", backend.GetLocator())
+	}
+	checkErr := backend.checkConnection(context.Background())
+	if checkErr == nil {
+		t.Fatalf("expected an error from a closed port")
+	}
+
+	message := DisruptionBeganMessageForRequests(backend.GetLocator(), backend.getRequests(), backend.GetConnectionType(), checkErr)
+	if !strings.Contains(message, "stopped responding to TCP connections over new connections") {
+		t.Errorf("unexpected message %q", message)
+	}
+}
@@ -10,3 +10,9 @@ type Recorder interface {
 	StartInterval(t time.Time, condition monitorapi.Condition) int
 	EndInterval(startedInterval int, t time.Time)
 }
+
+// LatencyRecorder is implemented by Recorders that aggregate request latency.  A BackendSampler records the timing of
+// every successful sample if its Recorder is also a LatencyRecorder.
+type LatencyRecorder interface {
+	RecordLatency(locator string, at time.Time, timing monitorapi.RequestTiming)
+}
@@ -6,8 +6,11 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
 )
 
 // Options is used to run a monitoring process against the provided server as
@@ -15,9 +18,24 @@ import (
 type Options struct {
 	Out, ErrOut io.Writer
 
+	// ArtifactDir, if set, is where the event journal is written while the monitor runs.
+	ArtifactDir string
+	// StatusAddress, if set, is the host:port to serve the live intervals, disruption state, metrics and chart on.
+	StatusAddress string
+
 	AdditionalEventIntervalRecorders []StartEventIntervalRecorderFunc
+
+	// SoakWindow, if set, cuts the intervals every SoakWindow, passes each window to WriteWindow with a timestamped
+	// directory in ArtifactDir, and evicts what ended before the cut so the monitor can run for days.
+	SoakWindow time.Duration
+	// WriteWindow writes the artifacts of a soak window.  Intervals still open at the end of the window end at to
+	// and continue in the next window.
+	WriteWindow WindowWriterFunc
 }
 
+// WindowWriterFunc writes the artifacts for the events of the window [from,to) to artifactDir.
+type WindowWriterFunc func(artifactDir string, m *Monitor, events monitorapi.Intervals, from, to time.Time) error
+
 // Run starts monitoring the cluster by invoking Start, periodically printing the
 // events accumulated to Out. When the user hits CTRL+C or signals termination the
 // condition intervals (all non-instantaneous events) are reported to Out.
@@ -40,14 +58,37 @@ func (opt *Options) Run() error {
 	}()
 	signal.Notify(abortCh, syscall.SIGINT, syscall.SIGTERM)
 
+	if opt.SoakWindow > 0 {
+		if len(opt.ArtifactDir) == 0 {
+			return fmt.Errorf("--artifact-dir is required with --soak-window")
+		}
+		if opt.WriteWindow == nil {
+			return fmt.Errorf("no window writer was provided for --soak-window")
+		}
+	}
+
 	restConfig, err := GetMonitorRESTConfig()
 	if err != nil {
 		return err
 	}
-	m, err := Start(ctx, restConfig, opt.AdditionalEventIntervalRecorders)
+	var journalFilename string
+	if len(opt.ArtifactDir) > 0 {
+		if err := os.MkdirAll(opt.ArtifactDir, 0755); err != nil {
+			return fmt.Errorf("could not create --artifact-dir: %v", err)
+		}
+		journalFilename = filepath.Join(opt.ArtifactDir, JournalFilename)
+	}
+	m, err := StartWithJournal(ctx, restConfig, opt.AdditionalEventIntervalRecorders, journalFilename)
 	if err != nil {
 		return err
 	}
+	if len(opt.StatusAddress) > 0 {
+		address, err := StartStatusServer(ctx, opt.StatusAddress, m)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(opt.ErrOut, "Serving monitor status on http://%s\n", address)
+	}
 
 	go func() {
 		ticker := time.NewTicker(100 * time.Millisecond)
@@ -73,7 +114,18 @@ func (opt *Options) Run() error {
 		}
 	}()
 
+	soakDone := make(chan struct{})
+	if opt.SoakWindow > 0 {
+		go func() {
+			defer close(soakDone)
+			opt.soak(ctx, m, time.Now().UTC())
+		}()
+	} else {
+		close(soakDone)
+	}
+
 	<-ctx.Done()
+	<-soakDone
 
 	time.Sleep(150 * time.Millisecond)
 	if events := m.Conditions(time.Time{}, time.Time{}); len(events) > 0 {
@@ -85,3 +137,38 @@ func (opt *Options) Run() error {
 
 	return nil
 }
+
+// soak writes a window every SoakWindow, and the partial window when ctx is done.
+func (opt *Options) soak(ctx context.Context, m *Monitor, start time.Time) {
+	ticker := time.NewTicker(opt.SoakWindow)
+	defer ticker.Stop()
+	for done := false; !done; {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			done = true
+		}
+		end := time.Now().UTC()
+		if err := opt.writeWindow(m, start, end); err != nil {
+			fmt.Fprintf(opt.ErrOut, "error: Failed to write the window from %s to %s: %v\n", start.Format(time.RFC3339), end.Format(time.RFC3339), err)
+		}
+		start = end
+	}
+}
+
+// writeWindow writes the intervals of [from,to) to their own directory and evicts everything that ended before to.
+// Intervals still open are cut at to, and are reported again in the next window from to.
+func (opt *Options) writeWindow(m *Monitor, from, to time.Time) error {
+	defer m.Evict(to)
+
+	intervals := m.Intervals(time.Time{}, time.Time{})
+	intervals.Clamp(from, to)
+	intervals = intervals.Cut(from, to)
+
+	artifactDir := filepath.Join(opt.ArtifactDir, fmt.Sprintf("window_%s", from.Format("20060102-150405")))
+	if err := os.MkdirAll(artifactDir, 0755); err != nil {
+		return err
+	}
+	fmt.Fprintf(opt.Out, "Writing %d intervals from %s to %s to %s\n", len(intervals), from.Format(time.RFC3339), to.Format(time.RFC3339), artifactDir)
+	return opt.WriteWindow(artifactDir, m, intervals, from, to)
+}
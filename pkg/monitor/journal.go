@@ -0,0 +1,254 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+)
+
+// JournalFilename is the name of the event journal inside an artifact directory.
+const JournalFilename = "e2e-events-journal.jsonl"
+
+// journalCheckpointInterval is how often buffered journal records are flushed and synced to disk.  This bounds how
+// much is lost when the process is killed.
+const journalCheckpointInterval = 10 * time.Second
+
+// journal appends everything a Monitor records to a file as it happens.  Intervals, EndInterval updates and samples
+// are journaled.  Recorded resources are not, they are only needed for the final resource-*.zip files.
+type journal struct {
+	lock   sync.Mutex
+	file   *os.File
+	out    *bufio.Writer
+	closed bool
+	err    error
+}
+
+func newJournal(filename string) (*journal, error) {
+	// a journal left by an earlier run, for instance in a reused --junit-dir, would be read back as part of this one
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &journal{
+		file: file,
+		out:  bufio.NewWriter(file),
+	}, nil
+}
+
+func (j *journal) write(record monitorserialization.JournalRecord) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if j.closed || j.err != nil {
+		return
+	}
+	if err := monitorserialization.WriteJournalRecord(j.out, record); err != nil {
+		j.fail(err)
+	}
+}
+
+// checkpoint writes a checkpoint record and makes everything written so far durable.
+func (j *journal) checkpoint(t time.Time) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if j.closed || j.err != nil {
+		return
+	}
+	if err := monitorserialization.WriteJournalRecord(j.out, monitorserialization.CheckpointJournalRecord(t)); err != nil {
+		j.fail(err)
+		return
+	}
+	if err := j.out.Flush(); err != nil {
+		j.fail(err)
+		return
+	}
+	if err := j.file.Sync(); err != nil {
+		j.fail(err)
+	}
+}
+
+// rewrite replaces the content of the journal with records, the state a monitor keeps after Evict, so the journal of
+// a soaking monitor does not grow without bound.  The records are written to a new file that replaces the journal once
+// it is complete, so a journal is never left half written.
+func (j *journal) rewrite(records []monitorserialization.JournalRecord) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if j.closed || j.err != nil {
+		return
+	}
+
+	filename := j.file.Name()
+	file, err := os.OpenFile(filename+".tmp", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		j.fail(err)
+		return
+	}
+	out := bufio.NewWriter(file)
+	for _, record := range records {
+		if err := monitorserialization.WriteJournalRecord(out, record); err != nil {
+			file.Close()
+			j.fail(err)
+			return
+		}
+	}
+	if err := out.Flush(); err != nil {
+		file.Close()
+		j.fail(err)
+		return
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		j.fail(err)
+		return
+	}
+	if err := os.Rename(filename+".tmp", filename); err != nil {
+		file.Close()
+		j.fail(err)
+		return
+	}
+	// anything still buffered for the old file is part of records
+	j.file.Close()
+	j.file, j.out = file, out
+}
+
+func (j *journal) close() error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if j.closed {
+		return j.err
+	}
+	j.closed = true
+	if j.err == nil {
+		if err := j.out.Flush(); err != nil {
+			j.err = err
+		}
+	}
+	if err := j.file.Close(); err != nil && j.err == nil {
+		j.err = err
+	}
+	return j.err
+}
+
+// fail stops journaling after the first error rather than leaving a journal with holes in it.  Must be called with
+// the lock held.
+func (j *journal) fail(err error) {
+	j.err = err
+	fmt.Fprintf(os.Stderr, "error: stopped writing event journal %s: %v\n", j.file.Name(), err)
+}
+
+// StartJournal writes every interval, interval update and sample recorded from now on to filename, replacing what the
+// file held before, checkpointing periodically, until the context is done.  Use LoadJournal to rebuild the monitor
+// from the file.
+func (m *Monitor) StartJournal(ctx context.Context, filename string) error {
+	j, err := newJournal(filename)
+	if err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	m.journal = j
+	m.lock.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(journalCheckpointInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case t := <-ticker.C:
+				j.checkpoint(t)
+			case <-ctx.Done():
+				m.lock.Lock()
+				m.journal = nil
+				m.lock.Unlock()
+				j.close()
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// LoadJournal rebuilds a monitor from a journal written by StartJournal.  The journal may be incomplete, for instance
+// when the process writing it was killed.  Intervals still open at the end of the journal have a zero To, which
+// Intervals.Clamp will fill in.
+func LoadJournal(filename string) (*Monitor, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	m := NewMonitorWithInterval(0)
+	m.intervalCreationFns = append(m.intervalCreationFns, defaultIntervalCreationFns...)
+	// the journal may have been started after some intervals were, those leave holes that must be dropped
+	journaled := map[int]bool{}
+	err = monitorserialization.ReadJournal(file, func(record monitorserialization.JournalRecord) error {
+		switch record.Kind {
+		case monitorserialization.JournalInterval:
+			intervals, err := record.MonitorIntervals()
+			if err != nil {
+				return err
+			}
+			for _, interval := range intervals {
+				if record.ID == nil {
+					m.events = append(m.events, interval)
+					continue
+				}
+				for len(m.unsortedEvents) <= *record.ID {
+					m.unsortedEvents = append(m.unsortedEvents, monitorapi.EventInterval{})
+				}
+				m.unsortedEvents[*record.ID] = interval
+				journaled[*record.ID] = true
+			}
+
+		case monitorserialization.JournalEnd:
+			// the writer only journals updates that EndInterval applied, so apply them unconditionally.  Times are
+			// serialized with second precision, which would make EndInterval drop intervals shorter than a second.
+			if record.ID != nil && *record.ID < len(m.unsortedEvents) {
+				m.unsortedEvents[*record.ID].To = record.At.Time
+			}
+
+		case monitorserialization.JournalSample:
+			intervals, err := record.MonitorIntervals()
+			if err != nil {
+				return err
+			}
+			conditions := make([]*monitorapi.Condition, 0, len(intervals))
+			for i := range intervals {
+				conditions = append(conditions, &intervals[i].Condition)
+			}
+			m.samples = append(m.samples, &sample{
+				at:         record.At.Time,
+				conditions: conditions,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read event journal %s: %w", filename, err)
+	}
+	unsortedEvents := make(monitorapi.Intervals, 0, len(journaled))
+	for i := range m.unsortedEvents {
+		if journaled[i] {
+			unsortedEvents = append(unsortedEvents, m.unsortedEvents[i])
+			m.unsortedIDs = append(m.unsortedIDs, i)
+		}
+	}
+	m.unsortedEvents = unsortedEvents
+	for _, event := range m.events {
+		m.counters.observe(event)
+	}
+	for _, interval := range m.unsortedEvents {
+		m.counters.observe(interval)
+		m.counters.observeEnd(interval)
+	}
+	if len(m.unsortedIDs) > 0 {
+		m.nextID = m.unsortedIDs[len(m.unsortedIDs)-1] + 1
+	}
+	return m, nil
+}
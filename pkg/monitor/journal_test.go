@@ -0,0 +1,152 @@
+package monitor
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestJournal_LoadJournal(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), JournalFilename)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := NewMonitorWithInterval(time.Second)
+	// recorded before the journal started, so it must not leave a hole behind
+	m.StartInterval(time.Unix(1, 0), monitorapi.Condition{Level: monitorapi.Info, Locator: "node/before", Message: "reason/Before"})
+	if err := m.StartJournal(ctx, filename); err != nil {
+		t.Fatal(err)
+	}
+
+	m.Record(monitorapi.Condition{Level: monitorapi.Warning, Locator: "node/node1", Message: "reason/NotReady node is not ready"})
+	open := m.StartInterval(time.Unix(10, 0), monitorapi.Condition{Level: monitorapi.Error, Locator: "disruption/kube-api connection/new", Message: "reason/DisruptionBegan"})
+	m.EndInterval(open, time.Unix(12, 0))
+	m.StartInterval(time.Unix(20, 0), monitorapi.Condition{Level: monitorapi.Info, Locator: "clusteroperator/dns", Message: "reason/Progressing"})
+	m.journal.checkpoint(time.Now())
+
+	// simulate the writer being killed in the middle of a line
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"kind":"Interval","intervals":[{"lev`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	loaded, err := LoadJournal(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.events) != 1 || loaded.events[0].Locator != "node/node1" {
+		t.Errorf("unexpected events: %v", loaded.events)
+	}
+	if len(loaded.unsortedEvents) != 2 {
+		t.Fatalf("unexpected intervals: %v", loaded.unsortedEvents)
+	}
+	if disruption := loaded.unsortedEvents[0]; disruption.Locator != "disruption/kube-api connection/new" || !disruption.From.Equal(time.Unix(10, 0)) || !disruption.To.Equal(time.Unix(12, 0)) {
+		t.Errorf("unexpected closed interval: %v", disruption)
+	}
+	if progressing := loaded.unsortedEvents[1]; progressing.Locator != "clusteroperator/dns" || !progressing.To.IsZero() {
+		t.Errorf("unexpected open interval: %v", progressing)
+	}
+}
+
+func TestJournal_LoadJournalCorrupt(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), JournalFilename)
+	if err := ioutil.WriteFile(filename, []byte("{not json\n{\"kind\":\"Checkpoint\"}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadJournal(filename); err == nil {
+		t.Fatal("expected an error for a corrupt line before the end of the journal")
+	}
+}
+
+func TestJournal_EvictCompacts(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), JournalFilename)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := NewMonitorWithInterval(time.Second)
+	if err := m.StartJournal(ctx, filename); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		m.RecordAt(time.Unix(int64(i), 0), monitorapi.Condition{Level: monitorapi.Info, Locator: "node/node1", Message: "reason/Old"})
+	}
+	open := m.StartInterval(time.Unix(50, 0), monitorapi.Condition{Level: monitorapi.Error, Locator: "disruption/kube-api connection/new", Message: "reason/DisruptionBegan"})
+	m.journal.checkpoint(time.Now())
+	before, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.Evict(time.Unix(200, 0))
+	m.RecordAt(time.Unix(210, 0), monitorapi.Condition{Level: monitorapi.Info, Locator: "node/node1", Message: "reason/New"})
+	m.EndInterval(open, time.Unix(220, 0))
+	m.journal.checkpoint(time.Now())
+
+	after, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Size() >= before.Size() {
+		t.Errorf("expected the journal to shrink from %d bytes, got %d", before.Size(), after.Size())
+	}
+	loaded, err := LoadJournal(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.unsortedEvents) != 2 {
+		t.Fatalf("unexpected intervals: %v", loaded.unsortedEvents)
+	}
+	if disruption := loaded.unsortedEvents[0]; disruption.Locator != "disruption/kube-api connection/new" || !disruption.To.Equal(time.Unix(220, 0)) {
+		t.Errorf("unexpected interval kept across the eviction: %v", disruption)
+	}
+	if instant := loaded.unsortedEvents[1]; instant.Message != "reason/New" {
+		t.Errorf("unexpected interval after the eviction: %v", instant)
+	}
+	if _, err := os.Stat(filename + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("expected no temporary journal to be left behind: %v", err)
+	}
+}
+
+func TestJournal_ReusedDirectory(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), JournalFilename)
+
+	// the first run checkpoints the same interval ID the second one uses
+	firstCtx, firstCancel := context.WithCancel(context.Background())
+	first := NewMonitorWithInterval(time.Second)
+	if err := first.StartJournal(firstCtx, filename); err != nil {
+		t.Fatal(err)
+	}
+	first.StartInterval(time.Unix(1, 0), monitorapi.Condition{Level: monitorapi.Info, Locator: "node/first", Message: "reason/First"})
+	first.Record(monitorapi.Condition{Level: monitorapi.Warning, Locator: "node/first", Message: "reason/NotReady"})
+	first.journal.checkpoint(time.Now())
+	firstCancel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	second := NewMonitorWithInterval(time.Second)
+	if err := second.StartJournal(ctx, filename); err != nil {
+		t.Fatal(err)
+	}
+	second.StartInterval(time.Unix(10, 0), monitorapi.Condition{Level: monitorapi.Info, Locator: "node/second", Message: "reason/Second"})
+	second.journal.checkpoint(time.Now())
+
+	loaded, err := LoadJournal(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.events) != 0 {
+		t.Errorf("unexpected events of the first run: %v", loaded.events)
+	}
+	if len(loaded.unsortedEvents) != 1 || loaded.unsortedEvents[0].Locator != "node/second" {
+		t.Errorf("expected only the interval of the second run, got %v", loaded.unsortedEvents)
+	}
+}
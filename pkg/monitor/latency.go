@@ -0,0 +1,175 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// latencyBucketBounds are the upper bounds of the latency histogram buckets.  Anything slower lands in a final,
+// unbounded bucket.
+var latencyBucketBounds = []time.Duration{
+	1 * time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	20 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	200 * time.Millisecond,
+	500 * time.Millisecond,
+	1 * time.Second,
+	2 * time.Second,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// The phases of a request that are tracked.  Phases that did not happen for a request, for instance the TLS handshake
+// on a reused connection, are not counted.
+const (
+	LatencyPhaseDNS          = "DNS"
+	LatencyPhaseConnect      = "Connect"
+	LatencyPhaseTLSHandshake = "TLSHandshake"
+	LatencyPhaseFirstByte    = "FirstByte"
+	LatencyPhaseTotal        = "Total"
+)
+
+// latencyHistogram counts latencies into latencyBucketBounds.
+type latencyHistogram struct {
+	counts []int
+	max    time.Duration
+}
+
+func newLatencyHistogram() *latencyHistogram {
+	return &latencyHistogram{counts: make([]int, len(latencyBucketBounds)+1)}
+}
+
+func (h *latencyHistogram) observe(latency time.Duration) {
+	bucket := len(latencyBucketBounds)
+	for i, bound := range latencyBucketBounds {
+		if latency <= bound {
+			bucket = i
+			break
+		}
+	}
+	h.counts[bucket]++
+	if latency > h.max {
+		h.max = latency
+	}
+}
+
+func (h *latencyHistogram) count() int {
+	total := 0
+	for _, count := range h.counts {
+		total += count
+	}
+	return total
+}
+
+// quantile returns the upper bound of the bucket that holds quantile q of the observations, capped at the maximum
+// observed latency.
+func (h *latencyHistogram) quantile(q float64) time.Duration {
+	total := h.count()
+	if total == 0 {
+		return 0
+	}
+	seen := 0
+	for i, count := range h.counts {
+		seen += count
+		if float64(seen) >= q*float64(total) {
+			if i < len(latencyBucketBounds) && latencyBucketBounds[i] < h.max {
+				return latencyBucketBounds[i]
+			}
+			break
+		}
+	}
+	return h.max
+}
+
+// backendLatency is the latency recorded for a single backend locator.
+type backendLatency struct {
+	samples int
+	phases  map[string]*latencyHistogram
+}
+
+func (l *backendLatency) observe(phase string, latency time.Duration) {
+	if latency <= 0 {
+		return
+	}
+	histogram, ok := l.phases[phase]
+	if !ok {
+		histogram = newLatencyHistogram()
+		l.phases[phase] = histogram
+	}
+	histogram.observe(latency)
+}
+
+// RecordLatency adds the timing of a successful request to the latency histograms of the backend with the locator.
+func (m *Monitor) RecordLatency(locator string, at time.Time, timing monitorapi.RequestTiming) {
+	m.latencyLock.Lock()
+	defer m.latencyLock.Unlock()
+
+	if m.latencies == nil {
+		m.latencies = map[string]*backendLatency{}
+	}
+	latency, ok := m.latencies[locator]
+	if !ok {
+		latency = &backendLatency{phases: map[string]*latencyHistogram{}}
+		m.latencies[locator] = latency
+	}
+	latency.samples++
+	latency.observe(LatencyPhaseDNS, timing.DNS)
+	latency.observe(LatencyPhaseConnect, timing.Connect)
+	latency.observe(LatencyPhaseTLSHandshake, timing.TLSHandshake)
+	latency.observe(LatencyPhaseFirstByte, timing.FirstByte)
+	latency.observe(LatencyPhaseTotal, timing.Total)
+}
+
+// LatencyHistogram is a snapshot of the latency of one phase of the requests to a backend.
+type LatencyHistogram struct {
+	// BucketBounds are the upper bounds of the buckets.  Counts has one more entry for the latencies above the last bound.
+	BucketBounds []metav1.Duration
+	Counts       []int
+	// P50, P95 and P99 are the upper bounds of the buckets holding those percentiles.
+	P50 metav1.Duration
+	P95 metav1.Duration
+	P99 metav1.Duration
+	Max metav1.Duration
+}
+
+// BackendLatencySnapshot holds the latency histograms for a backend, keyed by phase.
+type BackendLatencySnapshot struct {
+	Samples int
+	Phases  map[string]*LatencyHistogram
+}
+
+// CurrentLatencies returns a snapshot of the latency recorded for each backend locator.
+func (m *Monitor) CurrentLatencies() map[string]*BackendLatencySnapshot {
+	m.latencyLock.Lock()
+	defer m.latencyLock.Unlock()
+
+	ret := map[string]*BackendLatencySnapshot{}
+	for locator, latency := range m.latencies {
+		snapshot := &BackendLatencySnapshot{
+			Samples: latency.samples,
+			Phases:  map[string]*LatencyHistogram{},
+		}
+		for phase, histogram := range latency.phases {
+			bounds := make([]metav1.Duration, 0, len(latencyBucketBounds))
+			for _, bound := range latencyBucketBounds {
+				bounds = append(bounds, metav1.Duration{Duration: bound})
+			}
+			snapshot.Phases[phase] = &LatencyHistogram{
+				BucketBounds: bounds,
+				Counts:       append([]int(nil), histogram.counts...),
+				P50:          metav1.Duration{Duration: histogram.quantile(0.50)},
+				P95:          metav1.Duration{Duration: histogram.quantile(0.95)},
+				P99:          metav1.Duration{Duration: histogram.quantile(0.99)},
+				Max:          metav1.Duration{Duration: histogram.max},
+			}
+		}
+		ret[locator] = snapshot
+	}
+	return ret
+}
@@ -0,0 +1,55 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestMonitor_RecordLatency(t *testing.T) {
+	m := NewMonitor()
+	locator := "disruption/kube-api connection/reused"
+	now := time.Now()
+	for i := 0; i < 98; i++ {
+		m.RecordLatency(locator, now, monitorapi.RequestTiming{FirstByte: 3 * time.Millisecond, Total: 4 * time.Millisecond})
+	}
+	m.RecordLatency(locator, now, monitorapi.RequestTiming{FirstByte: 300 * time.Millisecond, Total: 300 * time.Millisecond})
+	m.RecordLatency(locator, now, monitorapi.RequestTiming{FirstByte: 12 * time.Second, Total: 12 * time.Second})
+
+	latency, ok := m.CurrentLatencies()[locator]
+	if !ok {
+		t.Fatalf("no latency recorded for %s", locator)
+	}
+	if latency.Samples != 100 {
+		t.Errorf("expected 100 samples, got %d", latency.Samples)
+	}
+	if _, ok := latency.Phases[LatencyPhaseTLSHandshake]; ok {
+		t.Errorf("phases that did not happen should not be recorded")
+	}
+
+	total := latency.Phases[LatencyPhaseTotal]
+	if total.P50.Duration != 5*time.Millisecond || total.P95.Duration != 5*time.Millisecond {
+		t.Errorf("unexpected p50 %s or p95 %s", total.P50.Duration, total.P95.Duration)
+	}
+	if total.P99.Duration != 500*time.Millisecond {
+		t.Errorf("unexpected p99 %s", total.P99.Duration)
+	}
+	if total.Max.Duration != 12*time.Second || total.Counts[len(total.Counts)-1] != 1 {
+		t.Errorf("expected the slowest request in the overflow bucket, got max %s and counts %v", total.Max.Duration, total.Counts)
+	}
+}
+
+func Test_computeLatencyData(t *testing.T) {
+	m := NewMonitor()
+	m.RecordLatency("disruption/kube-api connection/new", time.Now(), monitorapi.RequestTiming{Total: time.Millisecond})
+
+	latencies := computeLatencyData(m.CurrentLatencies())
+	latency, ok := latencies.BackendLatencies["kube-api-new-connections"]
+	if !ok {
+		t.Fatalf("expected the latency to be keyed like the disruption data, got %v", latencies.BackendLatencies)
+	}
+	if latency.BackendName != "kube-api" || latency.ConnectionType != "New" || latency.Samples != 1 {
+		t.Errorf("unexpected latency %+v", latency)
+	}
+}
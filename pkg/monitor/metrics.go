@@ -0,0 +1,222 @@
+package monitor
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+const metricsNamespace = "openshift_tests_monitor"
+
+var (
+	backendDisruptionSecondsDesc = prometheus.NewDesc(
+		metricsNamespace+"_backend_disruption_seconds_total",
+		"Seconds a backend was unavailable, including a disruption still in progress.",
+		[]string{"backend", "connection_type"}, nil,
+	)
+	backendDisruptedDesc = prometheus.NewDesc(
+		metricsNamespace+"_backend_disrupted",
+		"1 while a backend is unavailable.",
+		[]string{"backend", "connection_type"}, nil,
+	)
+	containerExitsDesc = prometheus.NewDesc(
+		metricsNamespace+"_container_exits_total",
+		"Container exits seen by the monitor.",
+		[]string{"namespace", "reason"}, nil,
+	)
+	operatorConditionTransitionsDesc = prometheus.NewDesc(
+		metricsNamespace+"_operator_condition_transitions_total",
+		"Changes of the Degraded and Progressing conditions of cluster operators.",
+		[]string{"operator", "condition", "status"}, nil,
+	)
+	nodeNotReadyDesc = prometheus.NewDesc(
+		metricsNamespace+"_node_not_ready_total",
+		"Times a node's Ready condition changed away from True.",
+		[]string{"node"}, nil,
+	)
+	nodesNotReadyDesc = prometheus.NewDesc(
+		metricsNamespace+"_nodes_not_ready",
+		"Nodes whose last Ready condition change was away from True.",
+		nil, nil,
+	)
+	e2eTestsDesc = prometheus.NewDesc(
+		metricsNamespace+"_e2e_tests_total",
+		"Finished e2e tests by result.",
+		[]string{"status"}, nil,
+	)
+)
+
+// intervalCounters are the counters of the metrics, counted as the monitor records conditions and ends intervals
+// rather than from the intervals in memory, so they never go down when Evict drops intervals.  The zero value is ready
+// to use.
+type intervalCounters struct {
+	lock                sync.Mutex
+	containerExits      map[[2]string]int
+	operatorTransitions map[[3]string]int
+	nodeNotReady        map[string]int
+	// nodeReady is whether the last Ready condition change of each node was to True.
+	nodeReady map[string]bool
+	e2eTests  map[string]int
+	// disruptedSeconds are the seconds of the ended disruptions of each backend and connection type, and
+	// reportedDisruptedSeconds the last value reported, which includes disruptions in progress.
+	disruptedSeconds         map[[2]string]float64
+	reportedDisruptedSeconds map[[2]string]float64
+}
+
+// observe counts an instant condition.
+func (c *intervalCounters) observe(interval monitorapi.EventInterval) {
+	if !interval.From.Equal(interval.To) {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.containerExits == nil {
+		c.containerExits = map[[2]string]int{}
+		c.operatorTransitions = map[[3]string]int{}
+		c.nodeNotReady = map[string]int{}
+		c.nodeReady = map[string]bool{}
+		c.e2eTests = map[string]int{}
+	}
+
+	message := interval.StructuredMessage
+	switch {
+	case message.Reason == monitorapi.ContainerReasonContainerExit:
+		c.containerExits[[2]string{monitorapi.NamespaceFromLocator(interval.Locator), message.Cause}]++
+
+	case monitorapi.IsOperator(interval.Locator):
+		conditionType := message.Annotations[monitorapi.AnnotationCondition]
+		if conditionType != "Degraded" && conditionType != "Progressing" {
+			return
+		}
+		operator, _ := monitorapi.OperatorFromLocator(interval.Locator)
+		c.operatorTransitions[[3]string{operator, conditionType, message.Annotations[monitorapi.AnnotationStatus]}]++
+
+	case monitorapi.IsNode(interval.Locator):
+		if message.Annotations[monitorapi.AnnotationCondition] != "Ready" {
+			return
+		}
+		node, _ := monitorapi.NodeFromLocator(interval.Locator)
+		ready := message.Annotations[monitorapi.AnnotationStatus] == "True"
+		if !ready {
+			c.nodeNotReady[node]++
+		}
+		c.nodeReady[node] = ready
+
+	case monitorapi.IsE2ETest(interval.Locator):
+		if status, ok := message.Annotations["finishedStatus"]; ok {
+			c.e2eTests[status]++
+		}
+	}
+}
+
+// observeEnd counts the duration of an ended disruption.
+func (c *intervalCounters) observeEnd(interval monitorapi.EventInterval) {
+	if interval.To.IsZero() || !monitorapi.IsDisruptionEvent(interval) || !monitorapi.IsErrorEvent(interval) {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.disruptedSeconds == nil {
+		c.disruptedSeconds = map[[2]string]float64{}
+	}
+	backendName, _ := aggregatedDisruptionNameFor(interval.Locator)
+	key := [2]string{backendName, monitorapi.DisruptionConnectionTypeFrom(monitorapi.LocatorParts(interval.Locator))}
+	c.disruptedSeconds[key] += monitorapi.Intervals{interval}.Duration(1 * time.Second).Seconds()
+}
+
+// monitorCollector derives metrics from a Monitor each time it is scraped.  The gauges come from the current
+// intervals, the counters from what the monitor counted as it recorded.
+type monitorCollector struct {
+	m *Monitor
+}
+
+var _ prometheus.Collector = &monitorCollector{}
+
+// NewMetricsCollector returns a prometheus.Collector for the disruption, container exits, operator condition changes,
+// node readiness and e2e test results recorded by m.
+func NewMetricsCollector(m *Monitor) prometheus.Collector {
+	return &monitorCollector{m: m}
+}
+
+// MetricsHandler serves the metrics of NewMetricsCollector in the Prometheus text format.
+func (m *Monitor) MetricsHandler() http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(NewMetricsCollector(m))
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+func (c *monitorCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- backendDisruptionSecondsDesc
+	ch <- backendDisruptedDesc
+	ch <- containerExitsDesc
+	ch <- operatorConditionTransitionsDesc
+	ch <- nodeNotReadyDesc
+	ch <- nodesNotReadyDesc
+	ch <- e2eTestsDesc
+}
+
+func (c *monitorCollector) Collect(ch chan<- prometheus.Metric) {
+	collectIntervalMetrics(c.m.Intervals(time.Time{}, time.Time{}), &c.m.counters, time.Now(), ch)
+}
+
+func collectIntervalMetrics(intervals monitorapi.Intervals, counters *intervalCounters, now time.Time, ch chan<- prometheus.Metric) {
+	counters.lock.Lock()
+	defer counters.lock.Unlock()
+	if counters.reportedDisruptedSeconds == nil {
+		counters.reportedDisruptedSeconds = map[[2]string]float64{}
+	}
+
+	// a disruption in progress is only counted once it ends, until then it is added to what ended before
+	inProgress := map[[2]string]float64{}
+	for _, status := range computeDisruptionStatus(intervals, now).Backends {
+		key := [2]string{status.BackendName, status.ConnectionType}
+		disrupted := 0.0
+		if status.Disrupted {
+			disrupted = 1
+			inProgress[key] += monitorapi.Intervals{{From: status.DisruptedSince.Time, To: now}}.Duration(1 * time.Second).Seconds()
+		} else if _, ok := inProgress[key]; !ok {
+			inProgress[key] = 0
+		}
+		ch <- prometheus.MustNewConstMetric(backendDisruptedDesc, prometheus.GaugeValue, disrupted, status.BackendName, status.ConnectionType)
+	}
+	for key := range counters.disruptedSeconds {
+		inProgress[key] += 0
+	}
+	for key := range counters.reportedDisruptedSeconds {
+		inProgress[key] += 0
+	}
+	for key, seconds := range inProgress {
+		// an interval can end a little before the scrape that counted it in progress
+		value := counters.disruptedSeconds[key] + seconds
+		if reported := counters.reportedDisruptedSeconds[key]; value < reported {
+			value = reported
+		}
+		counters.reportedDisruptedSeconds[key] = value
+		ch <- prometheus.MustNewConstMetric(backendDisruptionSecondsDesc, prometheus.CounterValue, value, key[0], key[1])
+	}
+
+	for key, count := range counters.containerExits {
+		ch <- prometheus.MustNewConstMetric(containerExitsDesc, prometheus.CounterValue, float64(count), key[0], key[1])
+	}
+	for key, count := range counters.operatorTransitions {
+		ch <- prometheus.MustNewConstMetric(operatorConditionTransitionsDesc, prometheus.CounterValue, float64(count), key[0], key[1], key[2])
+	}
+	for node, count := range counters.nodeNotReady {
+		ch <- prometheus.MustNewConstMetric(nodeNotReadyDesc, prometheus.CounterValue, float64(count), node)
+	}
+	notReady := 0
+	for _, ready := range counters.nodeReady {
+		if !ready {
+			notReady++
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(nodesNotReadyDesc, prometheus.GaugeValue, float64(notReady))
+	for status, count := range counters.e2eTests {
+		ch <- prometheus.MustNewConstMetric(e2eTestsDesc, prometheus.CounterValue, float64(count), status)
+	}
+}
@@ -0,0 +1,99 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestMetricsCollector(t *testing.T) {
+	m := NewMonitor()
+	start := time.Now().Add(-time.Minute)
+	disruption := m.StartInterval(start, monitorapi.Condition{Level: monitorapi.Error, Locator: "disruption/kube-api connection/new", Message: "reason/DisruptionBegan stopped responding"})
+	m.EndInterval(disruption, start.Add(10*time.Second))
+	m.RecordAt(start,
+		monitorapi.Condition{Level: monitorapi.Error, Locator: "ns/e2e pod/a node/b uid/c container/d", Message: "reason/ContainerExit code/137 cause/OOMKilled"},
+		monitorapi.Condition{Level: monitorapi.Error, Locator: "ns/e2e pod/a node/b uid/c container/d", Message: "reason/ContainerExit code/137 cause/OOMKilled"},
+		monitorapi.Condition{Level: monitorapi.Warning, Locator: "clusteroperator/dns", Message: "condition/Degraded status/True reason/DNSDegraded changed: DNS default is degraded"},
+		monitorapi.Condition{Level: monitorapi.Warning, Locator: "clusteroperator/dns", Message: "condition/Available status/False changed: "},
+		monitorapi.Condition{Level: monitorapi.Warning, Locator: "node/a", Message: "condition/Ready status/False reason/KubeletNotReady roles/worker changed"},
+		monitorapi.Condition{Level: monitorapi.Warning, Locator: "node/b", Message: "condition/Ready status/Unknown reason/NodeStatusUnknown roles/worker changed"},
+	)
+	m.RecordAt(start.Add(time.Second), monitorapi.Condition{Level: monitorapi.Warning, Locator: "node/b", Message: "condition/Ready status/True reason/KubeletReady roles/worker changed"})
+	m.Record(
+		monitorapi.Condition{Level: monitorapi.Info, Locator: monitorapi.E2ETestLocator("passing"), Message: "finishedStatus/Passed"},
+		monitorapi.Condition{Level: monitorapi.Error, Locator: monitorapi.E2ETestLocator("failing"), Message: "finishedStatus/Failed  reason/Timeout"},
+	)
+
+	expected := `
+# HELP openshift_tests_monitor_backend_disrupted 1 while a backend is unavailable.
+# TYPE openshift_tests_monitor_backend_disrupted gauge
+openshift_tests_monitor_backend_disrupted{backend="kube-api",connection_type="new"} 0
+# HELP openshift_tests_monitor_backend_disruption_seconds_total Seconds a backend was unavailable, including a disruption still in progress.
+# TYPE openshift_tests_monitor_backend_disruption_seconds_total counter
+openshift_tests_monitor_backend_disruption_seconds_total{backend="kube-api",connection_type="new"} 10
+# HELP openshift_tests_monitor_container_exits_total Container exits seen by the monitor.
+# TYPE openshift_tests_monitor_container_exits_total counter
+openshift_tests_monitor_container_exits_total{namespace="e2e",reason="OOMKilled"} 2
+# HELP openshift_tests_monitor_e2e_tests_total Finished e2e tests by result.
+# TYPE openshift_tests_monitor_e2e_tests_total counter
+openshift_tests_monitor_e2e_tests_total{status="Failed"} 1
+openshift_tests_monitor_e2e_tests_total{status="Passed"} 1
+# HELP openshift_tests_monitor_node_not_ready_total Times a node's Ready condition changed away from True.
+# TYPE openshift_tests_monitor_node_not_ready_total counter
+openshift_tests_monitor_node_not_ready_total{node="a"} 1
+openshift_tests_monitor_node_not_ready_total{node="b"} 1
+# HELP openshift_tests_monitor_nodes_not_ready Nodes whose last Ready condition change was away from True.
+# TYPE openshift_tests_monitor_nodes_not_ready gauge
+openshift_tests_monitor_nodes_not_ready 1
+# HELP openshift_tests_monitor_operator_condition_transitions_total Changes of the Degraded and Progressing conditions of cluster operators.
+# TYPE openshift_tests_monitor_operator_condition_transitions_total counter
+openshift_tests_monitor_operator_condition_transitions_total{condition="Degraded",operator="dns",status="True"} 1
+`
+	if err := testutil.CollectAndCompare(NewMetricsCollector(m), strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMetricsCollector_countersSurviveEvict(t *testing.T) {
+	m := NewMonitor()
+	start := time.Now().Add(-time.Minute)
+	disruption := m.StartInterval(start, monitorapi.Condition{Level: monitorapi.Error, Locator: "disruption/kube-api connection/new", Message: "reason/DisruptionBegan stopped responding"})
+	m.EndInterval(disruption, start.Add(10*time.Second))
+	m.RecordAt(start, monitorapi.Condition{Level: monitorapi.Error, Locator: "ns/e2e pod/a node/b uid/c container/d", Message: "reason/ContainerExit code/137 cause/OOMKilled"})
+	m.Record(monitorapi.Condition{Level: monitorapi.Info, Locator: monitorapi.E2ETestLocator("passing"), Message: "finishedStatus/Passed"})
+
+	expected := `
+# HELP openshift_tests_monitor_backend_disruption_seconds_total Seconds a backend was unavailable, including a disruption still in progress.
+# TYPE openshift_tests_monitor_backend_disruption_seconds_total counter
+openshift_tests_monitor_backend_disruption_seconds_total{backend="kube-api",connection_type="new"} 10
+# HELP openshift_tests_monitor_container_exits_total Container exits seen by the monitor.
+# TYPE openshift_tests_monitor_container_exits_total counter
+openshift_tests_monitor_container_exits_total{namespace="e2e",reason="OOMKilled"} 2
+# HELP openshift_tests_monitor_e2e_tests_total Finished e2e tests by result.
+# TYPE openshift_tests_monitor_e2e_tests_total counter
+openshift_tests_monitor_e2e_tests_total{status="Passed"} 1
+`
+	counters := []string{
+		"openshift_tests_monitor_backend_disruption_seconds_total",
+		"openshift_tests_monitor_container_exits_total",
+		"openshift_tests_monitor_e2e_tests_total",
+	}
+	collector := NewMetricsCollector(m)
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(strings.Replace(expected, "} 2", "} 1", 1)), counters...); err != nil {
+		t.Fatal(err)
+	}
+
+	m.Evict(time.Now().Add(time.Second))
+	if intervals := m.Intervals(time.Time{}, time.Time{}); len(intervals) != 0 {
+		t.Fatalf("expected no intervals after the eviction, got %v", intervals)
+	}
+	m.RecordAt(time.Now().Add(2*time.Second), monitorapi.Condition{Level: monitorapi.Error, Locator: "ns/e2e pod/a node/b uid/c container/d", Message: "reason/ContainerExit code/137 cause/OOMKilled"})
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected), counters...); err != nil {
+		t.Error(err)
+	}
+}
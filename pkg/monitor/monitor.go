@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/cache"
@@ -25,9 +26,22 @@ type Monitor struct {
 	events         monitorapi.Intervals
 	unsortedEvents monitorapi.Intervals
 	samples        []*sample
+	// unsortedIDs are the ids returned for unsortedEvents, in the same order.  They stay stable when Evict drops
+	// earlier intervals.
+	unsortedIDs []int
+	nextID      int
 
 	recordedResourceLock sync.Mutex
 	recordedResources    monitorapi.ResourcesMap
+
+	// journal is set while StartJournal is active and is guarded by lock.
+	journal *journal
+
+	latencyLock sync.Mutex
+	latencies   map[string]*backendLatency
+
+	// counters are the counters of the metrics, see NewMetricsCollector.
+	counters intervalCounters
 }
 
 // NewMonitor creates a monitor with the default sampling interval.
@@ -93,6 +107,18 @@ func (m *Monitor) CurrentResourceState() monitorapi.ResourcesMap {
 	return ret
 }
 
+// RestoreResources replaces the recorded instances of resourceType, for instance with those saved by an earlier run.
+func (m *Monitor) RestoreResources(resourceType string, instances monitorapi.InstanceMap) {
+	m.recordedResourceLock.Lock()
+	defer m.recordedResourceLock.Unlock()
+
+	restored := monitorapi.InstanceMap{}
+	for key, obj := range instances {
+		restored[key] = obj.DeepCopyObject()
+	}
+	m.recordedResources[resourceType] = restored
+}
+
 func (m *Monitor) RecordResource(resourceType string, obj runtime.Object) {
 	m.recordedResourceLock.Lock()
 	defer m.recordedResourceLock.Unlock()
@@ -178,10 +204,14 @@ func (m *Monitor) Record(conditions ...monitorapi.Condition) {
 	t := time.Now().UTC()
 	for _, condition := range conditions {
 		m.events = append(m.events, monitorapi.EventInterval{
-			Condition: condition,
+			Condition: monitorapi.NormalizeCondition(condition),
 			From:      t,
 			To:        t,
 		})
+		m.counters.observe(m.events[len(m.events)-1])
+		if m.journal != nil {
+			m.journal.write(monitorserialization.IntervalJournalRecord(m.events[len(m.events)-1], nil))
+		}
 	}
 }
 
@@ -190,11 +220,23 @@ func (m *Monitor) Record(conditions ...monitorapi.Condition) {
 func (m *Monitor) StartInterval(t time.Time, condition monitorapi.Condition) int {
 	m.lock.Lock()
 	defer m.lock.Unlock()
-	m.unsortedEvents = append(m.unsortedEvents, monitorapi.EventInterval{
-		Condition: condition,
+	return m.appendUnsorted(monitorapi.EventInterval{
+		Condition: monitorapi.NormalizeCondition(condition),
 		From:      t,
 	})
-	return len(m.unsortedEvents) - 1
+}
+
+// appendUnsorted adds the interval to unsortedEvents and returns its id.  The lock must be held.
+func (m *Monitor) appendUnsorted(interval monitorapi.EventInterval) int {
+	id := m.nextID
+	m.nextID++
+	m.unsortedEvents = append(m.unsortedEvents, interval)
+	m.unsortedIDs = append(m.unsortedIDs, id)
+	m.counters.observe(interval)
+	if m.journal != nil {
+		m.journal.write(monitorserialization.IntervalJournalRecord(interval, &id))
+	}
+	return id
 }
 
 // EndInterval updates the To of the interval started by StartInterval if t is greater than
@@ -202,9 +244,14 @@ func (m *Monitor) StartInterval(t time.Time, condition monitorapi.Condition) int
 func (m *Monitor) EndInterval(startedInterval int, t time.Time) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
-	if startedInterval < len(m.unsortedEvents) {
-		if m.unsortedEvents[startedInterval].From.Before(t) {
-			m.unsortedEvents[startedInterval].To = t
+	i := sort.SearchInts(m.unsortedIDs, startedInterval)
+	if i < len(m.unsortedIDs) && m.unsortedIDs[i] == startedInterval {
+		if m.unsortedEvents[i].From.Before(t) {
+			m.unsortedEvents[i].To = t
+			m.counters.observeEnd(m.unsortedEvents[i])
+			if m.journal != nil {
+				m.journal.write(monitorserialization.EndJournalRecord(startedInterval, t))
+			}
 		}
 	}
 }
@@ -218,14 +265,141 @@ func (m *Monitor) RecordAt(t time.Time, conditions ...monitorapi.Condition) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 	for _, condition := range conditions {
-		m.unsortedEvents = append(m.unsortedEvents, monitorapi.EventInterval{
-			Condition: condition,
+		m.appendUnsorted(monitorapi.EventInterval{
+			Condition: monitorapi.NormalizeCondition(condition),
 			From:      t,
 			To:        t,
 		})
 	}
 }
 
+// Evict drops the events, intervals and samples that ended before t so a long running monitor does not grow without
+// bound.  Intervals that are still open or end at or after t are kept, and EndInterval keeps working for them.
+// Instant events that the interval creation functions derive an interval still open at t from are kept too, so a
+// condition that spans t, like a degraded operator, is still reported after it.  Recorded resources are kept because
+// they are the current state of the cluster.  The journal, if any, is compacted to what is kept.
+func (m *Monitor) Evict(t time.Time) {
+	recordedResources := m.CurrentResourceState()
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	// instant events are recorded either in events or, with RecordAt, in unsortedEvents
+	evicted := monitorapi.Intervals{}
+	first := sort.Search(len(m.events), func(i int) bool {
+		return !m.events[i].From.Before(t)
+	})
+	evicted = append(evicted, m.events[:first]...)
+	for _, interval := range m.unsortedEvents {
+		if interval.From.Equal(interval.To) && interval.To.Before(t) {
+			evicted = append(evicted, interval)
+		}
+	}
+	carried := m.eventsOfOpenIntervals(evicted, recordedResources, t)
+
+	// snapshots share the backing arrays, so the kept entries are always copied
+	events := monitorapi.Intervals{}
+	for _, event := range m.events[:first] {
+		if carried[keyForEvent(event)] {
+			events = append(events, event)
+		}
+	}
+	m.events = append(events, m.events[first:]...)
+
+	unsortedEvents := make(monitorapi.Intervals, 0, len(m.unsortedEvents))
+	unsortedIDs := make([]int, 0, len(m.unsortedIDs))
+	for i, interval := range m.unsortedEvents {
+		if !interval.To.IsZero() && interval.To.Before(t) && !(interval.From.Equal(interval.To) && carried[keyForEvent(interval)]) {
+			continue
+		}
+		unsortedEvents = append(unsortedEvents, interval)
+		unsortedIDs = append(unsortedIDs, m.unsortedIDs[i])
+	}
+	m.unsortedEvents, m.unsortedIDs = unsortedEvents, unsortedIDs
+
+	first = sort.Search(len(m.samples), func(i int) bool {
+		return !m.samples[i].at.Before(t)
+	})
+	m.samples = append([]*sample(nil), m.samples[first:]...)
+
+	if m.journal != nil {
+		m.journal.rewrite(m.journalRecords())
+	}
+}
+
+// eventKey identifies an instant event.  Conditions carry maps in their structured forms and cannot be compared
+// directly.
+type eventKey struct {
+	locator string
+	message string
+	at      int64
+}
+
+func keyForEvent(event monitorapi.EventInterval) eventKey {
+	return eventKey{locator: event.Locator, message: event.Message, at: event.From.UnixNano()}
+}
+
+// eventsOfOpenIntervals returns the evicted events that an interval creation function derives an interval still open
+// at t from.  The events of each locator are evaluated separately, and the ones from the start of the earliest open
+// interval on are kept.
+func (m *Monitor) eventsOfOpenIntervals(evicted monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, t time.Time) map[eventKey]bool {
+	carried := map[eventKey]bool{}
+	if len(m.intervalCreationFns) == 0 || len(evicted) == 0 {
+		return carried
+	}
+	sort.Sort(evicted)
+
+	locators := []string{}
+	eventsByLocator := map[string]monitorapi.Intervals{}
+	for _, event := range evicted {
+		if _, ok := eventsByLocator[event.Locator]; !ok {
+			locators = append(locators, event.Locator)
+		}
+		eventsByLocator[event.Locator] = append(eventsByLocator[event.Locator], event)
+	}
+
+	for _, locator := range locators {
+		events := eventsByLocator[locator]
+		var openFrom *time.Time
+		for _, createIntervals := range m.intervalCreationFns {
+			for _, interval := range createIntervals(events, recordedResources, time.Time{}, t) {
+				if interval.To.Before(t) {
+					continue
+				}
+				if from := interval.From; openFrom == nil || from.Before(*openFrom) {
+					openFrom = &from
+				}
+			}
+		}
+		if openFrom == nil {
+			continue
+		}
+		for _, event := range events {
+			if !event.From.Before(*openFrom) {
+				carried[keyForEvent(event)] = true
+			}
+		}
+	}
+	return carried
+}
+
+// journalRecords returns the records that rebuild the current state of the monitor with LoadJournal.  The lock must be
+// held.
+func (m *Monitor) journalRecords() []monitorserialization.JournalRecord {
+	records := make([]monitorserialization.JournalRecord, 0, len(m.events)+len(m.unsortedEvents)+len(m.samples))
+	for _, event := range m.events {
+		records = append(records, monitorserialization.IntervalJournalRecord(event, nil))
+	}
+	for i, interval := range m.unsortedEvents {
+		id := m.unsortedIDs[i]
+		records = append(records, monitorserialization.IntervalJournalRecord(interval, &id))
+	}
+	for _, sample := range m.samples {
+		records = append(records, monitorserialization.SampleJournalRecord(sample.at, sample.conditions))
+	}
+	return records
+}
+
 func (m *Monitor) sample(hasPrevious bool) bool {
 	m.lock.Lock()
 	samplers := m.samplers
@@ -234,7 +408,10 @@ func (m *Monitor) sample(hasPrevious bool) bool {
 	now := time.Now().UTC()
 	var conditions []*monitorapi.Condition
 	for _, fn := range samplers {
-		conditions = append(conditions, fn(now)...)
+		for _, condition := range fn(now) {
+			normalized := monitorapi.NormalizeCondition(*condition)
+			conditions = append(conditions, &normalized)
+		}
 	}
 	if len(conditions) == 0 {
 		if !hasPrevious {
@@ -248,6 +425,9 @@ func (m *Monitor) sample(hasPrevious bool) bool {
 		at:         now,
 		conditions: conditions,
 	})
+	if m.journal != nil {
+		m.journal.write(monitorserialization.SampleJournalRecord(now, conditions))
+	}
 	return len(conditions) > 0
 }
 
@@ -281,7 +461,10 @@ func (m *Monitor) Intervals(from, to time.Time) monitorapi.Intervals {
 	recordedResources := m.CurrentResourceState()
 	// create additional intervals from events
 	for _, createIntervals := range m.intervalCreationFns {
-		intervals = append(intervals, createIntervals(intervals, recordedResources, from, to)...)
+		for _, interval := range createIntervals(intervals, recordedResources, from, to) {
+			interval.Condition = monitorapi.NormalizeCondition(interval.Condition)
+			intervals = append(intervals, interval)
+		}
 	}
 
 	// we must sort the result
@@ -324,13 +507,14 @@ func filterSamples(samples []*sample, from, to time.Time) monitorapi.Intervals {
 	}
 
 	intervals := make(monitorapi.Intervals, 0, len(samples)*2)
-	last, next := make(map[monitorapi.Condition]*monitorapi.EventInterval), make(map[monitorapi.Condition]*monitorapi.EventInterval)
+	last, next := make(map[conditionKey]*monitorapi.EventInterval), make(map[conditionKey]*monitorapi.EventInterval)
 	for _, sample := range samples {
 		for _, condition := range sample.conditions {
-			interval, ok := last[*condition]
+			key := keyForCondition(condition)
+			interval, ok := last[key]
 			if ok {
 				interval.To = sample.at
-				next[*condition] = interval
+				next[key] = interval
 				continue
 			}
 			intervals = append(intervals, monitorapi.EventInterval{
@@ -338,7 +522,7 @@ func filterSamples(samples []*sample, from, to time.Time) monitorapi.Intervals {
 				From:      sample.at,
 				To:        sample.at.Add(time.Second),
 			})
-			next[*condition] = &intervals[len(intervals)-1]
+			next[key] = &intervals[len(intervals)-1]
 		}
 		for k := range last {
 			delete(last, k)
@@ -348,6 +532,22 @@ func filterSamples(samples []*sample, from, to time.Time) monitorapi.Intervals {
 	return intervals
 }
 
+// conditionKey identifies a sampled condition across samples.  Conditions carry maps in their structured forms and
+// cannot be used as map keys directly, so the string forms (which are always set after normalization) are used.
+type conditionKey struct {
+	level   monitorapi.EventLevel
+	locator string
+	message string
+}
+
+func keyForCondition(condition *monitorapi.Condition) conditionKey {
+	return conditionKey{
+		level:   condition.Level,
+		locator: condition.Locator,
+		message: condition.Message,
+	}
+}
+
 // mergeEvents returns a sorted list of all events provided as sources. This could be
 // more efficient by requiring all sources to be sorted and then performing a zipper
 // merge.
@@ -1,6 +1,7 @@
 package monitor
 
 import (
+	"io/ioutil"
 	"reflect"
 	"testing"
 	"time"
@@ -104,3 +105,119 @@ func TestMonitor_Events(t *testing.T) {
 		})
 	}
 }
+
+func TestMonitor_Evict(t *testing.T) {
+	m := NewMonitorWithInterval(0)
+	m.RecordAt(time.Unix(1, 0), monitorapi.Condition{Message: "old instant"})
+	ended := m.StartInterval(time.Unix(2, 0), monitorapi.Condition{Message: "ended"})
+	m.EndInterval(ended, time.Unix(5, 0))
+	open := m.StartInterval(time.Unix(3, 0), monitorapi.Condition{Message: "open"})
+	crossing := m.StartInterval(time.Unix(4, 0), monitorapi.Condition{Message: "crossing"})
+	m.EndInterval(crossing, time.Unix(20, 0))
+	m.RecordAt(time.Unix(11, 0), monitorapi.Condition{Message: "new instant"})
+	m.samples = []*sample{
+		{at: time.Unix(5, 0), conditions: []*monitorapi.Condition{{Message: "old sample"}}},
+		{at: time.Unix(15, 0), conditions: []*monitorapi.Condition{{Message: "new sample"}}},
+	}
+
+	m.Evict(time.Unix(10, 0))
+	m.EndInterval(open, time.Unix(30, 0))
+
+	want := monitorapi.Intervals{
+		{Condition: monitorapi.Condition{Message: "open"}, From: time.Unix(3, 0), To: time.Unix(30, 0)},
+		{Condition: monitorapi.Condition{Message: "crossing"}, From: time.Unix(4, 0), To: time.Unix(20, 0)},
+		{Condition: monitorapi.Condition{Message: "new instant"}, From: time.Unix(11, 0), To: time.Unix(11, 0)},
+		{Condition: monitorapi.Condition{Message: "new sample"}, From: time.Unix(15, 0), To: time.Unix(16, 0)},
+	}
+	got := m.Intervals(time.Time{}, time.Time{})
+	for i := range got {
+		got[i].Condition = monitorapi.Condition{Message: got[i].Message}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%s", diff.ObjectReflectDiff(want, got))
+	}
+}
+
+func TestOptions_writeWindow(t *testing.T) {
+	m := NewMonitorWithInterval(0)
+	m.StartInterval(time.Unix(5, 0), monitorapi.Condition{Message: "open"})
+	m.RecordAt(time.Unix(6, 0), monitorapi.Condition{Message: "instant"})
+
+	var windows []monitorapi.Intervals
+	opt := &Options{
+		Out:         ioutil.Discard,
+		ArtifactDir: t.TempDir(),
+		WriteWindow: func(artifactDir string, m *Monitor, events monitorapi.Intervals, from, to time.Time) error {
+			windows = append(windows, events)
+			return nil
+		},
+	}
+	if err := opt.writeWindow(m, time.Unix(0, 0), time.Unix(10, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := opt.writeWindow(m, time.Unix(10, 0), time.Unix(20, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(windows) != 2 || len(windows[0]) != 2 || len(windows[1]) != 1 {
+		t.Fatalf("unexpected windows: %v", windows)
+	}
+	if open := windows[0][0]; !open.From.Equal(time.Unix(5, 0)) || !open.To.Equal(time.Unix(10, 0)) {
+		t.Errorf("expected the open interval to end at the cut, got %v", open)
+	}
+	if open := windows[1][0]; !open.From.Equal(time.Unix(10, 0)) || !open.To.Equal(time.Unix(20, 0)) {
+		t.Errorf("expected the open interval to continue from the cut, got %v", open)
+	}
+}
+
+func TestOptions_writeWindowCarriesOpenConditions(t *testing.T) {
+	m := NewMonitorWithInterval(0)
+	m.intervalCreationFns = append(m.intervalCreationFns, defaultIntervalCreationFns...)
+	m.RecordAt(time.Unix(1, 0), monitorapi.Condition{Locator: monitorapi.OperatorLocator("etcd"), Message: "condition/Degraded status/True reason/Broken"})
+	m.RecordAt(time.Unix(2, 0), monitorapi.Condition{Locator: monitorapi.E2ETestLocator("my test"), Message: "started"})
+	m.RecordAt(time.Unix(3, 0), monitorapi.Condition{Locator: monitorapi.OperatorLocator("dns"), Message: "condition/Degraded status/True reason/Broken"})
+	m.RecordAt(time.Unix(4, 0), monitorapi.Condition{Locator: monitorapi.OperatorLocator("dns"), Message: "condition/Degraded status/False reason/AsExpected"})
+
+	var windows []monitorapi.Intervals
+	opt := &Options{
+		Out:         ioutil.Discard,
+		ArtifactDir: t.TempDir(),
+		WriteWindow: func(artifactDir string, m *Monitor, events monitorapi.Intervals, from, to time.Time) error {
+			windows = append(windows, events)
+			return nil
+		},
+	}
+	if err := opt.writeWindow(m, time.Unix(0, 0), time.Unix(10, 0)); err != nil {
+		t.Fatal(err)
+	}
+	m.RecordAt(time.Unix(12, 0), monitorapi.Condition{Locator: monitorapi.E2ETestLocator("my test"), Message: "finishedStatus/Passed"})
+	if err := opt.writeWindow(m, time.Unix(10, 0), time.Unix(20, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	type span struct {
+		locator  string
+		from, to int64
+	}
+	derived := func(events monitorapi.Intervals) []span {
+		ret := []span{}
+		for _, event := range events {
+			if !event.From.Equal(event.To) {
+				ret = append(ret, span{locator: event.Locator, from: event.From.Unix(), to: event.To.Unix()})
+			}
+		}
+		return ret
+	}
+	want := []span{
+		{locator: monitorapi.OperatorLocator("etcd"), from: 10, to: 20},
+		{locator: monitorapi.E2ETestLocator("my test"), from: 10, to: 12},
+	}
+	if got := derived(windows[1]); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the conditions open at the cut in the next window, got %v", got)
+	}
+	for _, event := range m.Intervals(time.Time{}, time.Time{}) {
+		if event.Locator == monitorapi.OperatorLocator("dns") {
+			t.Errorf("expected the events of the ended condition to be evicted, got %v", event)
+		}
+	}
+}
@@ -1,13 +1,21 @@
 package monitorapi
 
 import (
-	"fmt"
 	"strconv"
 	"strings"
 )
 
 func E2ETestLocator(testName string) string {
-	return fmt.Sprintf("e2e-test/%q", testName)
+	return NewE2ETestLocator(testName).OldLocator()
+}
+
+func NewE2ETestLocator(testName string) Locator {
+	return Locator{
+		Type: LocatorTypeE2ETest,
+		Keys: map[LocatorKey]string{
+			LocatorE2ETestKey: testName,
+		},
+	}
 }
 
 func IsE2ETest(locator string) bool {
@@ -29,7 +37,16 @@ func E2ETestFromLocator(locator string) (string, bool) {
 }
 
 func NodeLocator(testName string) string {
-	return fmt.Sprintf("node/%v", testName)
+	return NewNodeLocator(testName).OldLocator()
+}
+
+func NewNodeLocator(nodeName string) Locator {
+	return Locator{
+		Type: LocatorTypeNode,
+		Keys: map[LocatorKey]string{
+			LocatorNodeKey: nodeName,
+		},
+	}
 }
 
 func IsNode(locator string) bool {
@@ -46,7 +63,16 @@ func NodeFromLocator(locator string) (string, bool) {
 }
 
 func OperatorLocator(testName string) string {
-	return fmt.Sprintf("clusteroperator/%v", testName)
+	return NewOperatorLocator(testName).OldLocator()
+}
+
+func NewOperatorLocator(operatorName string) Locator {
+	return Locator{
+		Type: LocatorTypeClusterOperator,
+		Keys: map[LocatorKey]string{
+			LocatorClusterOperatorKey: operatorName,
+		},
+	}
 }
 
 func IsOperator(locator string) bool {
@@ -10,11 +10,30 @@ import (
 )
 
 func LocatePod(pod *corev1.Pod) string {
-	return fmt.Sprintf("ns/%s pod/%s node/%s uid/%s", pod.Namespace, pod.Name, pod.Spec.NodeName, pod.UID)
+	return NewPodLocator(pod).OldLocator()
+}
+
+func NewPodLocator(pod *corev1.Pod) Locator {
+	return Locator{
+		Type: LocatorTypePod,
+		Keys: map[LocatorKey]string{
+			LocatorNamespaceKey: pod.Namespace,
+			LocatorPodKey:       pod.Name,
+			LocatorNodeKey:      pod.Spec.NodeName,
+			LocatorUIDKey:       string(pod.UID),
+		},
+	}
 }
 
 func LocatePodContainer(pod *corev1.Pod, containerName string) string {
-	return fmt.Sprintf("ns/%s pod/%s node/%s uid/%s container/%s", pod.Namespace, pod.Name, pod.Spec.NodeName, pod.UID, containerName)
+	return NewContainerLocator(pod, containerName).OldLocator()
+}
+
+func NewContainerLocator(pod *corev1.Pod, containerName string) Locator {
+	locator := NewPodLocator(pod)
+	locator.Type = LocatorTypeContainer
+	locator.Keys[LocatorContainerKey] = containerName
+	return locator
 }
 
 // NonUniquePodLocator produces an inexact locator based on namespace and name.  This is useful when dealing with events
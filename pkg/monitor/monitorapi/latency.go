@@ -0,0 +1,14 @@
+package monitorapi
+
+import "time"
+
+// RequestTiming is how long the phases of one successful request to a disruption backend took.  Phases that did not
+// happen, like DNS and connecting on a reused connection, or that do not apply to the backend's probe, are zero.
+type RequestTiming struct {
+	DNS          time.Duration
+	Connect      time.Duration
+	TLSHandshake time.Duration
+	// FirstByte is measured from the start of the request until the first byte of the response.
+	FirstByte time.Duration
+	Total     time.Duration
+}
@@ -0,0 +1,243 @@
+package monitorapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// locatorKeyOrder is the order keys are written in when deriving a string locator.  It matches the order of the
+// historical helpers like LocatePod and LocateDisruptionCheck so derived strings are identical.  Keys not listed here
+// are appended in sorted order.
+var locatorKeyOrder = []LocatorKey{
+	LocatorNamespaceKey,
+	LocatorPodKey,
+	LocatorNodeKey,
+	LocatorUIDKey,
+	LocatorContainerKey,
+	LocatorRouteKey,
+	LocatorServiceKey,
+	LocatorClusterOperatorKey,
+	LocatorClusterVersionKey,
+	LocatorAlertKey,
+	LocatorDisruptionKey,
+	LocatorConnectionKey,
+	LocatorE2ETestKey,
+}
+
+// annotationKeyPattern matches the key of a key/value stanza.  It is intentionally strict so that things like URLs
+// at the start of a human message are not mistaken for annotations.
+var annotationKeyPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_.-]*$`)
+
+// NewCondition builds a condition from its structured forms and derives the string forms from them.
+func NewCondition(level EventLevel, locator Locator, message Message) Condition {
+	return Condition{
+		Level:             level,
+		Locator:           locator.OldLocator(),
+		Message:           message.OldMessage(),
+		StructuredLocator: locator,
+		StructuredMessage: message,
+	}
+}
+
+// NormalizeCondition returns a copy of condition with both the string and the structured forms set.  If a string form
+// is empty it is derived from the structured form.  If a structured form is empty it is parsed from the string form.
+// Non-empty string forms are never rewritten, because not every historical producer writes keys in canonical order.
+func NormalizeCondition(condition Condition) Condition {
+	switch {
+	case len(condition.Locator) == 0 && !condition.StructuredLocator.IsEmpty():
+		condition.Locator = condition.StructuredLocator.OldLocator()
+	case len(condition.Locator) > 0 && condition.StructuredLocator.IsEmpty():
+		condition.StructuredLocator = LocatorFromString(condition.Locator)
+	}
+	switch {
+	case len(condition.Message) == 0 && !condition.StructuredMessage.IsEmpty():
+		condition.Message = condition.StructuredMessage.OldMessage()
+	case len(condition.Message) > 0 && condition.StructuredMessage.IsEmpty():
+		condition.StructuredMessage = MessageFromString(condition.Message)
+	}
+	return condition
+}
+
+// IsEmpty returns true if the locator carries no information.
+func (l Locator) IsEmpty() bool {
+	return len(l.Type) == 0 && len(l.Keys) == 0
+}
+
+// OldLocator returns the space separated key/value string form of the locator.
+func (l Locator) OldLocator() string {
+	if len(l.Keys) == 0 {
+		return ""
+	}
+
+	stanzas := make([]string, 0, len(l.Keys))
+	seen := map[LocatorKey]bool{}
+	for _, key := range locatorKeyOrder {
+		value, ok := l.Keys[key]
+		if !ok {
+			continue
+		}
+		seen[key] = true
+		stanzas = append(stanzas, locatorStanza(key, value))
+	}
+
+	remaining := []string{}
+	for key := range l.Keys {
+		if !seen[key] {
+			remaining = append(remaining, string(key))
+		}
+	}
+	sort.Strings(remaining)
+	for _, key := range remaining {
+		stanzas = append(stanzas, locatorStanza(LocatorKey(key), l.Keys[LocatorKey(key)]))
+	}
+
+	return strings.Join(stanzas, " ")
+}
+
+func locatorStanza(key LocatorKey, value string) string {
+	if key == LocatorE2ETestKey {
+		return fmt.Sprintf("%s/%q", key, value)
+	}
+	return fmt.Sprintf("%s/%s", key, value)
+}
+
+// LocatorFromString parses the string form of a locator.  e2e-test locators are special cased because the quoted test
+// name may contain spaces.
+func LocatorFromString(locator string) Locator {
+	if len(locator) == 0 {
+		return Locator{}
+	}
+	if testName, ok := E2ETestFromLocator(locator); ok {
+		return NewE2ETestLocator(testName)
+	}
+
+	keys := map[LocatorKey]string{}
+	for key, value := range LocatorParts(locator) {
+		keys[LocatorKey(key)] = value
+	}
+	return Locator{
+		Type: locatorTypeFromKeys(keys),
+		Keys: keys,
+	}
+}
+
+func locatorTypeFromKeys(keys map[LocatorKey]string) LocatorType {
+	has := func(key LocatorKey) bool {
+		_, ok := keys[key]
+		return ok
+	}
+	switch {
+	case has(LocatorAlertKey):
+		return LocatorTypeAlert
+	case has(LocatorDisruptionKey):
+		return LocatorTypeDisruption
+	case has(LocatorE2ETestKey):
+		return LocatorTypeE2ETest
+	case has(LocatorContainerKey):
+		return LocatorTypeContainer
+	case has(LocatorPodKey):
+		return LocatorTypePod
+	case has(LocatorClusterOperatorKey):
+		return LocatorTypeClusterOperator
+	case has(LocatorClusterVersionKey):
+		return LocatorTypeClusterVersion
+	case has(LocatorNodeKey):
+		return LocatorTypeNode
+	default:
+		return LocatorTypeOther
+	}
+}
+
+// IsEmpty returns true if the message carries no information.
+func (m Message) IsEmpty() bool {
+	return len(m.Reason) == 0 && len(m.Cause) == 0 && len(m.HumanMessage) == 0 && len(m.Annotations) == 0
+}
+
+// OldMessage returns the string form of the message: reason/ first, then the remaining annotations (including cause/)
+// in sorted order, then the human readable text.
+func (m Message) OldMessage() string {
+	stanzas := []string{}
+	if len(m.Reason) > 0 {
+		stanzas = append(stanzas, fmt.Sprintf("%s/%s", AnnotationReason, m.Reason))
+	}
+
+	annotations := map[AnnotationKey]string{}
+	for key, value := range m.Annotations {
+		annotations[key] = value
+	}
+	if len(m.Cause) > 0 {
+		annotations[AnnotationCause] = m.Cause
+	}
+	keys := []string{}
+	for key := range annotations {
+		if key == AnnotationReason {
+			continue
+		}
+		keys = append(keys, string(key))
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		stanzas = append(stanzas, fmt.Sprintf("%s/%s", key, annotations[AnnotationKey(key)]))
+	}
+
+	if len(m.HumanMessage) > 0 {
+		stanzas = append(stanzas, m.HumanMessage)
+	}
+	return strings.Join(stanzas, " ")
+}
+
+// MessageFromString parses the string form of a message.  Leading key/value stanzas become annotations and the rest
+// of the string is the human message.
+func MessageFromString(message string) Message {
+	ret := Message{}
+	remaining := message
+	for len(remaining) > 0 {
+		stanza := remaining
+		rest := ""
+		if i := strings.Index(remaining, " "); i >= 0 {
+			stanza = remaining[:i]
+			rest = remaining[i+1:]
+		}
+		keyValue := strings.SplitN(stanza, "/", 2)
+		if len(keyValue) != 2 || !annotationKeyPattern.MatchString(keyValue[0]) {
+			break
+		}
+
+		switch key := AnnotationKey(keyValue[0]); key {
+		case AnnotationReason:
+			ret.Reason = IntervalReason(keyValue[1])
+		case AnnotationCause:
+			ret.Cause = keyValue[1]
+		default:
+			if ret.Annotations == nil {
+				ret.Annotations = map[AnnotationKey]string{}
+			}
+			ret.Annotations[key] = keyValue[1]
+		}
+		remaining = rest
+	}
+	ret.HumanMessage = remaining
+
+	return ret
+}
+
+// NewMessage builds a message with a reason and a human readable message.
+func NewMessage(reason IntervalReason, humanMessage string) Message {
+	return Message{
+		Reason:       reason,
+		HumanMessage: humanMessage,
+	}
+}
+
+// WithAnnotation returns a copy of the message with the annotation set.
+func (m Message) WithAnnotation(key AnnotationKey, value string) Message {
+	annotations := map[AnnotationKey]string{}
+	for k, v := range m.Annotations {
+		annotations[k] = v
+	}
+	annotations[key] = value
+	m.Annotations = annotations
+	return m
+}
@@ -0,0 +1,187 @@
+package monitorapi
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLocatorFromString(t *testing.T) {
+	tests := []struct {
+		name    string
+		locator string
+		want    Locator
+	}{
+		{
+			name:    "container",
+			locator: "ns/openshift-etcd pod/etcd-0 node/master-0 uid/1234 container/etcd",
+			want: Locator{
+				Type: LocatorTypeContainer,
+				Keys: map[LocatorKey]string{
+					LocatorNamespaceKey: "openshift-etcd",
+					LocatorPodKey:       "etcd-0",
+					LocatorNodeKey:      "master-0",
+					LocatorUIDKey:       "1234",
+					LocatorContainerKey: "etcd",
+				},
+			},
+		},
+		{
+			name:    "disruption",
+			locator: "disruption/oauth-api connection/new",
+			want: Locator{
+				Type: LocatorTypeDisruption,
+				Keys: map[LocatorKey]string{
+					LocatorDisruptionKey: "oauth-api",
+					LocatorConnectionKey: "new",
+				},
+			},
+		},
+		{
+			name:    "e2e test with spaces",
+			locator: `e2e-test/"[sig-node] pods should run"`,
+			want: Locator{
+				Type: LocatorTypeE2ETest,
+				Keys: map[LocatorKey]string{
+					LocatorE2ETestKey: "[sig-node] pods should run",
+				},
+			},
+		},
+		{
+			name:    "unknown",
+			locator: "kube-apiserver",
+			want: Locator{
+				Type: LocatorTypeOther,
+				Keys: map[LocatorKey]string{
+					"kube-apiserver": "",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LocatorFromString(tt.locator)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LocatorFromString() got = %v, want %v", got, tt.want)
+			}
+			if tt.want.Type == LocatorTypeOther {
+				return
+			}
+			if old := got.OldLocator(); old != tt.locator {
+				t.Errorf("OldLocator() got = %q, want %q", old, tt.locator)
+			}
+		})
+	}
+}
+
+func TestLocatorHelpersAreDerived(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "pod1", UID: "uid1"},
+		Spec:       corev1.PodSpec{NodeName: "node1"},
+	}
+	if got, want := LocatePod(pod), "ns/ns1 pod/pod1 node/node1 uid/uid1"; got != want {
+		t.Errorf("LocatePod() got = %q, want %q", got, want)
+	}
+	if got, want := LocatePodContainer(pod, "c1"), "ns/ns1 pod/pod1 node/node1 uid/uid1 container/c1"; got != want {
+		t.Errorf("LocatePodContainer() got = %q, want %q", got, want)
+	}
+	if got, want := E2ETestLocator("a b"), `e2e-test/"a b"`; got != want {
+		t.Errorf("E2ETestLocator() got = %q, want %q", got, want)
+	}
+	if got, want := OperatorLocator("dns"), "clusteroperator/dns"; got != want {
+		t.Errorf("OperatorLocator() got = %q, want %q", got, want)
+	}
+}
+
+func TestMessageFromString(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    Message
+		// canonical is set when OldMessage() reorders the annotations of the original string.
+		canonical string
+	}{
+		{
+			name:    "container exit",
+			message: "reason/ContainerExit code/137 cause/OOMKilled out of memory",
+			want: Message{
+				Reason:       ContainerReasonContainerExit,
+				Cause:        "OOMKilled",
+				HumanMessage: "out of memory",
+				Annotations: map[AnnotationKey]string{
+					AnnotationContainerExitCode: "137",
+				},
+			},
+			canonical: "reason/ContainerExit cause/OOMKilled code/137 out of memory",
+		},
+		{
+			name:    "only reason",
+			message: "reason/Ready",
+			want: Message{
+				Reason: ContainerReasonReady,
+			},
+		},
+		{
+			name:    "human message with url",
+			message: "https://example.com/foo is down",
+			want: Message{
+				HumanMessage: "https://example.com/foo is down",
+			},
+		},
+		{
+			name:    "operator condition",
+			message: "condition/Degraded status/True reason/DNSDegraded changed: DNS default is degraded",
+			want: Message{
+				Reason:       "DNSDegraded",
+				HumanMessage: "changed: DNS default is degraded",
+				Annotations: map[AnnotationKey]string{
+					AnnotationCondition: "Degraded",
+					AnnotationStatus:    "True",
+				},
+			},
+			canonical: "reason/DNSDegraded condition/Degraded status/True changed: DNS default is degraded",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MessageFromString(tt.message)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MessageFromString() got = %#v, want %#v", got, tt.want)
+			}
+			canonical := tt.canonical
+			if len(canonical) == 0 {
+				canonical = tt.message
+			}
+			if old := got.OldMessage(); old != canonical {
+				t.Errorf("OldMessage() got = %q, want %q", old, canonical)
+			}
+		})
+	}
+}
+
+func TestNormalizeCondition(t *testing.T) {
+	structured := NewCondition(Error, NewNodeLocator("node1"), NewMessage("NotReady", "node is not ready"))
+	if structured.Locator != "node/node1" || structured.Message != "reason/NotReady node is not ready" {
+		t.Errorf("NewCondition() derived %q %q", structured.Locator, structured.Message)
+	}
+
+	// a producer that only set the strings keeps them verbatim and gains the structured forms
+	legacy := NormalizeCondition(Condition{Level: Info, Locator: "alert/Foo node/node1", Message: "reason/Firing"})
+	if legacy.Locator != "alert/Foo node/node1" {
+		t.Errorf("NormalizeCondition() rewrote locator to %q", legacy.Locator)
+	}
+	if legacy.StructuredLocator.Type != LocatorTypeAlert || legacy.StructuredLocator.Keys[LocatorNodeKey] != "node1" {
+		t.Errorf("NormalizeCondition() got locator %v", legacy.StructuredLocator)
+	}
+	if legacy.StructuredMessage.Reason != "Firing" {
+		t.Errorf("NormalizeCondition() got message %v", legacy.StructuredMessage)
+	}
+
+	// a producer that only set the structured forms gains the strings
+	derived := NormalizeCondition(Condition{Level: Info, StructuredLocator: NewOperatorLocator("dns")})
+	if derived.Locator != "clusteroperator/dns" || len(derived.Message) != 0 {
+		t.Errorf("NormalizeCondition() derived %q %q", derived.Locator, derived.Message)
+	}
+}
@@ -61,6 +61,76 @@ type Condition struct {
 
 	Locator string
 	Message string
+
+	// StructuredLocator and StructuredMessage are the typed forms of Locator and Message.  Use NewCondition to build
+	// a condition from them so the string forms are derived, or NormalizeCondition to fill in whichever side is missing.
+	StructuredLocator Locator
+	StructuredMessage Message
+}
+
+// LocatorType describes what kind of thing a Locator identifies.
+type LocatorType string
+
+const (
+	LocatorTypePod             LocatorType = "Pod"
+	LocatorTypeContainer       LocatorType = "Container"
+	LocatorTypeNode            LocatorType = "Node"
+	LocatorTypeAlert           LocatorType = "Alert"
+	LocatorTypeClusterOperator LocatorType = "ClusterOperator"
+	LocatorTypeClusterVersion  LocatorType = "ClusterVersion"
+	LocatorTypeDisruption      LocatorType = "Disruption"
+	LocatorTypeE2ETest         LocatorType = "E2ETest"
+	LocatorTypeOther           LocatorType = "Other"
+)
+
+// LocatorKey is a key in a Locator.  The values match the key/ prefixes used in the string form.
+type LocatorKey string
+
+const (
+	LocatorNamespaceKey       LocatorKey = "ns"
+	LocatorPodKey             LocatorKey = "pod"
+	LocatorNodeKey            LocatorKey = "node"
+	LocatorUIDKey             LocatorKey = "uid"
+	LocatorContainerKey       LocatorKey = "container"
+	LocatorRouteKey           LocatorKey = "route"
+	LocatorServiceKey         LocatorKey = "service"
+	LocatorClusterOperatorKey LocatorKey = "clusteroperator"
+	LocatorClusterVersionKey  LocatorKey = "clusterversion"
+	LocatorAlertKey           LocatorKey = "alert"
+	LocatorDisruptionKey      LocatorKey = "disruption"
+	LocatorConnectionKey      LocatorKey = "connection"
+	LocatorE2ETestKey         LocatorKey = "e2e-test"
+)
+
+// Locator is the typed form of Condition.Locator.
+type Locator struct {
+	Type LocatorType
+	Keys map[LocatorKey]string
+}
+
+// IntervalReason is the reason/ annotation of a message.
+type IntervalReason string
+
+// AnnotationKey is a key of a key/value annotation in a message.
+type AnnotationKey string
+
+const (
+	AnnotationReason            AnnotationKey = "reason"
+	AnnotationCause             AnnotationKey = "cause"
+	AnnotationContainerExitCode AnnotationKey = "code"
+	AnnotationNode              AnnotationKey = "node"
+	AnnotationRoles             AnnotationKey = "roles"
+	AnnotationCondition         AnnotationKey = "condition"
+	AnnotationStatus            AnnotationKey = "status"
+)
+
+// Message is the typed form of Condition.Message.  Reason and Cause are pulled out of the annotations because nearly
+// every consumer cares about them.
+type Message struct {
+	Reason       IntervalReason
+	Cause        string
+	HumanMessage string
+	Annotations  map[AnnotationKey]string
 }
 
 type EventInterval struct {
@@ -57,8 +57,9 @@ func TestStartSampling(t *testing.T) {
 	events := m.Intervals(time.Time{}, time.Time{})
 	for _, interval := range events {
 		i := interval.To.Sub(interval.From)
-		describe = append(describe, fmt.Sprintf("%v %s", interval.Condition, i))
-		log = append(log, fmt.Sprintf("%v", interval.Condition))
+		condition := fmt.Sprintf("{%v %s %s}", interval.Level, interval.Locator, interval.Message)
+		describe = append(describe, fmt.Sprintf("%s %s", condition, i))
+		log = append(log, condition)
 	}
 
 	expected := []string{
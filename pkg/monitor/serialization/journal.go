@@ -0,0 +1,125 @@
+package monitorserialization
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type JournalRecordKind string
+
+const (
+	// JournalInterval records an interval.  Intervals with an ID may be closed by a later JournalEnd.
+	JournalInterval JournalRecordKind = "Interval"
+	// JournalEnd records the end time of an interval previously journaled with the same ID.
+	JournalEnd JournalRecordKind = "End"
+	// JournalSample records the conditions reported by the samplers at one sample time.
+	JournalSample JournalRecordKind = "Sample"
+	// JournalCheckpoint marks a point where every earlier record was flushed to disk.
+	JournalCheckpoint JournalRecordKind = "Checkpoint"
+)
+
+// JournalRecord is one line of an event journal.  The journal is append-only JSON lines so that a process killed
+// mid-run leaves behind everything up to the last complete line.
+type JournalRecord struct {
+	Kind JournalRecordKind `json:"kind"`
+
+	// ID identifies an interval that can still be updated.  It is unset for intervals that are complete when recorded.
+	ID *int `json:"id,omitempty"`
+
+	// At is the end time for JournalEnd, the sample time for JournalSample and the write time for JournalCheckpoint.
+	At metav1.Time `json:"at,omitempty"`
+
+	// Intervals holds the interval for JournalInterval and the sampled conditions for JournalSample.
+	Intervals []EventInterval `json:"intervals,omitempty"`
+}
+
+func IntervalJournalRecord(interval monitorapi.EventInterval, id *int) JournalRecord {
+	return JournalRecord{
+		Kind:      JournalInterval,
+		ID:        id,
+		Intervals: []EventInterval{monitorEventIntervalToEventInterval(interval)},
+	}
+}
+
+func EndJournalRecord(id int, t time.Time) JournalRecord {
+	return JournalRecord{
+		Kind: JournalEnd,
+		ID:   &id,
+		At:   metav1.Time{Time: t},
+	}
+}
+
+func SampleJournalRecord(t time.Time, conditions []*monitorapi.Condition) JournalRecord {
+	ret := JournalRecord{
+		Kind: JournalSample,
+		At:   metav1.Time{Time: t},
+	}
+	for _, condition := range conditions {
+		ret.Intervals = append(ret.Intervals, monitorEventIntervalToEventInterval(monitorapi.EventInterval{
+			Condition: *condition,
+			From:      t,
+			To:        t,
+		}))
+	}
+	return ret
+}
+
+func CheckpointJournalRecord(t time.Time) JournalRecord {
+	return JournalRecord{
+		Kind: JournalCheckpoint,
+		At:   metav1.Time{Time: t},
+	}
+}
+
+// MonitorIntervals converts the intervals carried by the record.
+func (r JournalRecord) MonitorIntervals() (monitorapi.Intervals, error) {
+	return eventIntervalsToMonitorIntervals(r.Intervals)
+}
+
+// WriteJournalRecord writes the record as a single line.
+func WriteJournalRecord(w io.Writer, record JournalRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	_, err = w.Write(data)
+	return err
+}
+
+// ReadJournal calls fn for every complete record in r, in order.  A final line that cannot be decoded is ignored
+// because that is what a writer killed mid-write leaves behind.  Any other undecodable line is an error.
+func ReadJournal(r io.Reader, fn func(record JournalRecord) error) error {
+	reader := bufio.NewReader(r)
+	var badLine int
+	var badErr error
+	for lineNumber := 1; ; lineNumber++ {
+		line, readErr := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			// only the final line may be torn
+			if badErr != nil {
+				return fmt.Errorf("line %d: %w", badLine, badErr)
+			}
+			record := JournalRecord{}
+			if err := json.Unmarshal(line, &record); err != nil {
+				badLine, badErr = lineNumber, err
+			} else if err := fn(record); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
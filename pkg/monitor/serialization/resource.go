@@ -4,13 +4,17 @@ import (
 	"archive/zip"
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/kube-openapi/pkg/util/sets"
 )
 
@@ -73,3 +77,52 @@ func InstanceMapToFile(filename string, resourceType string, instances monitorap
 
 	return ioutil.WriteFile(filename, byteBuffer.Bytes(), 0644)
 }
+
+// InstanceMapFromFile reads a file written by InstanceMapToFile.  Instances are returned as unstructured objects.
+func InstanceMapFromFile(filename string) (string, monitorapi.InstanceMap, error) {
+	zipReader, err := zip.OpenReader(filename)
+	if err != nil {
+		return "", nil, err
+	}
+	defer zipReader.Close()
+
+	resourceType := ""
+	instances := monitorapi.InstanceMap{}
+	for _, file := range zipReader.File {
+		// every file is <namespace>/<resourceType>.json
+		currResourceType := strings.TrimSuffix(path.Base(file.Name), ".json")
+		if len(resourceType) == 0 {
+			resourceType = currResourceType
+		}
+		if currResourceType != resourceType {
+			return "", nil, fmt.Errorf("%s contains both %q and %q", filename, resourceType, currResourceType)
+		}
+
+		nsReader, err := file.Open()
+		if err != nil {
+			return "", nil, err
+		}
+		data, err := ioutil.ReadAll(nsReader)
+		nsReader.Close()
+		if err != nil {
+			return "", nil, err
+		}
+		// the items are written without kind, which the unstructured decoders require, so decode them directly
+		nsItems := struct {
+			Items []map[string]interface{} `json:"items"`
+		}{}
+		if err := json.Unmarshal(data, &nsItems); err != nil {
+			return "", nil, fmt.Errorf("unable to read %s from %s: %w", file.Name, filename, err)
+		}
+		for _, item := range nsItems.Items {
+			obj := &unstructured.Unstructured{Object: item}
+			key, err := cache.MetaNamespaceKeyFunc(obj)
+			if err != nil {
+				return "", nil, err
+			}
+			instances[key] = obj
+		}
+	}
+
+	return resourceType, instances, nil
+}
@@ -0,0 +1,40 @@
+package monitorserialization
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestInstanceMapFileRoundTrip(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "resource-pods.zip")
+	in := monitorapi.InstanceMap{
+		"ns1/pod1": &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "pod1", UID: "uid1"}},
+		"ns2/pod2": &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "pod2"}, Spec: corev1.PodSpec{NodeName: "node1"}},
+	}
+	if err := InstanceMapToFile(filename, "pods", in); err != nil {
+		t.Fatal(err)
+	}
+
+	resourceType, out, err := InstanceMapFromFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resourceType != "pods" {
+		t.Errorf("unexpected resource type %q", resourceType)
+	}
+	if len(out) != len(in) {
+		t.Fatalf("expected %d instances, got %d", len(in), len(out))
+	}
+	pod2, ok := out["ns2/pod2"].(*unstructured.Unstructured)
+	if !ok {
+		t.Fatalf("unexpected instance %#v", out["ns2/pod2"])
+	}
+	if nodeName, _, _ := unstructured.NestedString(pod2.Object, "spec", "nodeName"); nodeName != "node1" {
+		t.Errorf("unexpected node name %q", nodeName)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"reflect"
 	"sort"
 
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
@@ -18,10 +19,27 @@ type EventInterval struct {
 	Locator string `json:"locator"`
 	Message string `json:"message"`
 
+	// StructuredLocator and StructuredMessage are absent in files written before they existed.  When they are missing
+	// they are parsed from Locator and Message on read.
+	StructuredLocator *Locator `json:"structuredLocator,omitempty"`
+	StructuredMessage *Message `json:"structuredMessage,omitempty"`
+
 	From metav1.Time `json:"from"`
 	To   metav1.Time `json:"to"`
 }
 
+type Locator struct {
+	Type string            `json:"type"`
+	Keys map[string]string `json:"keys,omitempty"`
+}
+
+type Message struct {
+	Reason       string            `json:"reason,omitempty"`
+	Cause        string            `json:"cause,omitempty"`
+	HumanMessage string            `json:"humanMessage,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+}
+
 // EventList is not an interval.  It is an instant.  The instant removes any ambiguity about "when"
 type EventIntervalList struct {
 	Items []EventInterval `json:"items"`
@@ -48,18 +66,29 @@ func EventsFromJSON(data []byte) (monitorapi.Intervals, error) {
 	if err := json.Unmarshal(data, &list); err != nil {
 		return nil, err
 	}
-	events := make(monitorapi.Intervals, 0, len(list.Items))
-	for _, interval := range list.Items {
+	return eventIntervalsToMonitorIntervals(list.Items)
+}
+
+func eventIntervalsToMonitorIntervals(items []EventInterval) (monitorapi.Intervals, error) {
+	events := make(monitorapi.Intervals, 0, len(items))
+	for _, interval := range items {
 		level, err := monitorapi.EventLevelFromString(interval.Level)
 		if err != nil {
 			return nil, err
 		}
+		condition := monitorapi.Condition{
+			Level:   level,
+			Locator: interval.Locator,
+			Message: interval.Message,
+		}
+		if interval.StructuredLocator != nil {
+			condition.StructuredLocator = locatorToMonitorLocator(*interval.StructuredLocator)
+		}
+		if interval.StructuredMessage != nil {
+			condition.StructuredMessage = messageToMonitorMessage(*interval.StructuredMessage)
+		}
 		events = append(events, monitorapi.EventInterval{
-			Condition: monitorapi.Condition{
-				Level:   level,
-				Locator: interval.Locator,
-				Message: interval.Message,
-			},
+			Condition: monitorapi.NormalizeCondition(condition),
 
 			From: interval.From.Time,
 			To:   interval.To.Time,
@@ -103,15 +132,82 @@ func EventsIntervalsToJSON(events monitorapi.Intervals) ([]byte, error) {
 }
 
 func monitorEventIntervalToEventInterval(interval monitorapi.EventInterval) EventInterval {
+	condition := monitorapi.NormalizeCondition(interval.Condition)
 	ret := EventInterval{
-		Level:   fmt.Sprintf("%v", interval.Level),
-		Locator: interval.Locator,
-		Message: interval.Message,
+		Level:   fmt.Sprintf("%v", condition.Level),
+		Locator: condition.Locator,
+		Message: condition.Message,
 
 		From: metav1.Time{Time: interval.From},
 		To:   metav1.Time{Time: interval.To},
 	}
+	// the structured forms are only written when they carry more than the strings do.  Reading parses missing
+	// structured forms from the strings, so this still round trips and keeps files from legacy producers unchanged.
+	if !reflect.DeepEqual(condition.StructuredLocator, monitorapi.LocatorFromString(condition.Locator)) {
+		locator := monitorLocatorToLocator(condition.StructuredLocator)
+		ret.StructuredLocator = &locator
+	}
+	if !reflect.DeepEqual(condition.StructuredMessage, monitorapi.MessageFromString(condition.Message)) {
+		message := monitorMessageToMessage(condition.StructuredMessage)
+		ret.StructuredMessage = &message
+	}
+
+	return ret
+}
+
+func monitorLocatorToLocator(locator monitorapi.Locator) Locator {
+	ret := Locator{
+		Type: string(locator.Type),
+	}
+	if len(locator.Keys) > 0 {
+		ret.Keys = map[string]string{}
+		for k, v := range locator.Keys {
+			ret.Keys[string(k)] = v
+		}
+	}
+	return ret
+}
+
+func locatorToMonitorLocator(locator Locator) monitorapi.Locator {
+	ret := monitorapi.Locator{
+		Type: monitorapi.LocatorType(locator.Type),
+	}
+	if len(locator.Keys) > 0 {
+		ret.Keys = map[monitorapi.LocatorKey]string{}
+		for k, v := range locator.Keys {
+			ret.Keys[monitorapi.LocatorKey(k)] = v
+		}
+	}
+	return ret
+}
+
+func monitorMessageToMessage(message monitorapi.Message) Message {
+	ret := Message{
+		Reason:       string(message.Reason),
+		Cause:        message.Cause,
+		HumanMessage: message.HumanMessage,
+	}
+	if len(message.Annotations) > 0 {
+		ret.Annotations = map[string]string{}
+		for k, v := range message.Annotations {
+			ret.Annotations[string(k)] = v
+		}
+	}
+	return ret
+}
 
+func messageToMonitorMessage(message Message) monitorapi.Message {
+	ret := monitorapi.Message{
+		Reason:       monitorapi.IntervalReason(message.Reason),
+		Cause:        message.Cause,
+		HumanMessage: message.HumanMessage,
+	}
+	if len(message.Annotations) > 0 {
+		ret.Annotations = map[monitorapi.AnnotationKey]string{}
+		for k, v := range message.Annotations {
+			ret.Annotations[monitorapi.AnnotationKey(k)] = v
+		}
+	}
 	return ret
 }
 
@@ -0,0 +1,79 @@
+package monitorserialization
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestEventsFromJSON_OldFormat(t *testing.T) {
+	// written before structured locators and messages existed
+	data := []byte(`{
+    "items": [
+        {
+            "level": "Error",
+            "locator": "ns/openshift-etcd pod/etcd-0 node/master-0 uid/1234 container/etcd",
+            "message": "reason/ContainerExit code/1 cause/Error bad things",
+            "from": "2022-04-01T10:00:00Z",
+            "to": "2022-04-01T10:00:00Z"
+        }
+    ]
+}`)
+	events, err := EventsFromJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected one event, got %d", len(events))
+	}
+	event := events[0]
+	if event.Locator != "ns/openshift-etcd pod/etcd-0 node/master-0 uid/1234 container/etcd" {
+		t.Errorf("locator was rewritten: %q", event.Locator)
+	}
+	if event.StructuredLocator.Type != monitorapi.LocatorTypeContainer || event.StructuredLocator.Keys[monitorapi.LocatorContainerKey] != "etcd" {
+		t.Errorf("unexpected structured locator: %v", event.StructuredLocator)
+	}
+	if event.StructuredMessage.Reason != monitorapi.ContainerReasonContainerExit || event.StructuredMessage.Cause != "Error" {
+		t.Errorf("unexpected structured message: %v", event.StructuredMessage)
+	}
+}
+
+func TestEventsJSONRoundTrip(t *testing.T) {
+	from := time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)
+	message := monitorapi.NewMessage("NotReady", "node is not ready").WithAnnotation(monitorapi.AnnotationRoles, "master")
+	in := monitorapi.Intervals{
+		{
+			Condition: monitorapi.NewCondition(monitorapi.Warning, monitorapi.NewNodeLocator("master-0"), message),
+			From:      from,
+			To:        from.Add(time.Minute),
+		},
+		{
+			// the human message looks like an annotation, so only the structured form preserves it
+			Condition: monitorapi.NewCondition(monitorapi.Error, monitorapi.NewOperatorLocator("kube-apiserver"), monitorapi.Message{HumanMessage: "apiserver/kube is unreachable"}),
+			From:      from.Add(time.Second),
+			To:        from.Add(time.Minute),
+		},
+	}
+
+	data, err := EventsToJSON(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := EventsFromJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != len(in) {
+		t.Fatalf("expected %d events, got %d", len(in), len(out))
+	}
+	for i := range in {
+		if !reflect.DeepEqual(out[i].Condition, in[i].Condition) {
+			t.Errorf("condition did not round trip: got %#v, want %#v", out[i].Condition, in[i].Condition)
+		}
+		if !out[i].From.Equal(in[i].From) || !out[i].To.Equal(in[i].To) {
+			t.Errorf("times did not round trip: got %v-%v", out[i].From, out[i].To)
+		}
+	}
+}
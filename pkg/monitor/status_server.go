@@ -0,0 +1,200 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/intervalcreation"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BackendDisruptionStatusList is the current disruption state of every backend the monitor has seen, keyed the same
+// way as the backend disruption job run data.
+type BackendDisruptionStatusList struct {
+	Backends map[string]*BackendDisruptionStatus
+}
+
+type BackendDisruptionStatus struct {
+	BackendName    string
+	ConnectionType string
+	Locator        string
+
+	// Disrupted is true while the backend is unavailable.
+	Disrupted bool
+	// DisruptedSince is when the current disruption began.  It is only set while Disrupted.
+	DisruptedSince *metav1.Time `json:",omitempty"`
+	// DisruptedDuration is the total time the backend has been unavailable so far, including the current disruption.
+	DisruptedDuration metav1.Duration
+	// LastDisruptionMessage is the message of the most recent disruption.
+	LastDisruptionMessage string `json:",omitempty"`
+}
+
+// StatusHandler serves the live state of the monitor.
+//
+//	/intervals   the intervals as JSON, filtered by the optional from and to (RFC3339), level and locator (a prefix)
+//	             query parameters
+//	/disruption  the current disruption state of each backend as JSON
+//	/metrics     metrics derived from the intervals, see NewMetricsCollector
+//	/            the e2e chart of the intervals so far
+func (m *Monitor) StatusHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/intervals", m.serveIntervals)
+	mux.HandleFunc("/disruption", m.serveDisruption)
+	mux.Handle("/metrics", m.MetricsHandler())
+	mux.HandleFunc("/", m.serveChart)
+	return mux
+}
+
+// StartStatusServer serves StatusHandler on address until ctx is done.  It returns the address it listens on, which
+// differs from address when the port is 0.
+func StartStatusServer(ctx context.Context, address string, m *Monitor) (string, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return "", fmt.Errorf("could not listen on %s: %v", address, err)
+	}
+	server := &http.Server{Handler: m.StatusHandler()}
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			fmt.Fprintf(os.Stderr, "error: monitor status server stopped: %v\n", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	return listener.Addr().String(), nil
+}
+
+func (m *Monitor) serveIntervals(w http.ResponseWriter, req *http.Request) {
+	filter, err := intervalFilterFromQuery(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	data, err := monitorserialization.EventsToJSON(m.Intervals(time.Time{}, time.Time{}).Filter(filter))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// intervalFilterFromQuery builds a filter from the from, to, level and locator query parameters.  level may be
+// repeated or comma separated.  Intervals still open are treated as ending now.
+func intervalFilterFromQuery(req *http.Request) (monitorapi.EventIntervalMatchesFunc, error) {
+	query := req.URL.Query()
+	filters := []monitorapi.EventIntervalMatchesFunc{}
+
+	if from := query.Get("from"); len(from) > 0 {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from: %v", err)
+		}
+		filters = append(filters, func(i monitorapi.EventInterval) bool {
+			return i.To.IsZero() || !i.To.Before(t)
+		})
+	}
+	if to := query.Get("to"); len(to) > 0 {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to: %v", err)
+		}
+		filters = append(filters, func(i monitorapi.EventInterval) bool {
+			return !i.From.After(t)
+		})
+	}
+	if levels := query["level"]; len(levels) > 0 {
+		allowed := map[monitorapi.EventLevel]bool{}
+		for _, value := range levels {
+			for _, s := range strings.Split(value, ",") {
+				level, err := monitorapi.EventLevelFromString(s)
+				if err != nil {
+					return nil, fmt.Errorf("invalid level: %v", err)
+				}
+				allowed[level] = true
+			}
+		}
+		filters = append(filters, func(i monitorapi.EventInterval) bool {
+			return allowed[i.Level]
+		})
+	}
+	if locator := query.Get("locator"); len(locator) > 0 {
+		filters = append(filters, func(i monitorapi.EventInterval) bool {
+			return strings.HasPrefix(i.Locator, locator)
+		})
+	}
+	return monitorapi.And(filters...), nil
+}
+
+func (m *Monitor) serveDisruption(w http.ResponseWriter, req *http.Request) {
+	data, err := json.MarshalIndent(computeDisruptionStatus(m.Intervals(time.Time{}, time.Time{}), time.Now()), "", "    ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func computeDisruptionStatus(intervals monitorapi.Intervals, now time.Time) *BackendDisruptionStatusList {
+	ret := &BackendDisruptionStatusList{
+		Backends: map[string]*BackendDisruptionStatus{},
+	}
+	disruptions := intervals.Filter(monitorapi.IsDisruptionEvent)
+	locators := map[string]bool{}
+	for _, interval := range disruptions {
+		locators[interval.Locator] = true
+	}
+	for locator := range locators {
+		backendName, name := aggregatedDisruptionNameFor(locator)
+		status := &BackendDisruptionStatus{
+			BackendName:    backendName,
+			ConnectionType: monitorapi.DisruptionConnectionTypeFrom(monitorapi.LocatorParts(locator)),
+			Locator:        locator,
+		}
+
+		// copy so open intervals can be ended now without changing the monitor's intervals
+		errors := append(monitorapi.Intervals{}, disruptions.Filter(func(i monitorapi.EventInterval) bool {
+			return i.Locator == locator && monitorapi.IsErrorEvent(i)
+		})...)
+		sort.Sort(errors)
+		for i := range errors {
+			if errors[i].To.IsZero() {
+				status.Disrupted = true
+				status.DisruptedSince = &metav1.Time{Time: errors[i].From}
+				errors[i].To = now
+			}
+		}
+		if len(errors) > 0 {
+			status.LastDisruptionMessage = errors[len(errors)-1].Message
+		}
+		status.DisruptedDuration = metav1.Duration{Duration: errors.Duration(1 * time.Second)}
+		ret.Backends[name] = status
+	}
+	return ret
+}
+
+func (m *Monitor) serveChart(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != "/" {
+		http.NotFound(w, req)
+		return
+	}
+	intervals := m.Intervals(time.Time{}, time.Time{}).Filter(intervalcreation.BelongsInSpyglass)
+	data, err := intervalcreation.RenderE2EChart(fmt.Sprintf("Intervals - live %s", time.Now().UTC().Format(time.RFC3339)), intervals)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(data)
+}
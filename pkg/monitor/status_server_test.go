@@ -0,0 +1,91 @@
+package monitor
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+)
+
+func TestMonitor_StatusHandler(t *testing.T) {
+	m := NewMonitor()
+	start := time.Now().Add(-time.Minute)
+	ended := m.StartInterval(start, monitorapi.Condition{Level: monitorapi.Error, Locator: "disruption/kube-api connection/new", Message: "reason/DisruptionBegan stopped responding"})
+	m.EndInterval(ended, start.Add(10*time.Second))
+	m.StartInterval(start.Add(30*time.Second), monitorapi.Condition{Level: monitorapi.Error, Locator: "disruption/kube-api connection/new", Message: "reason/DisruptionBegan still not responding"})
+	m.RecordAt(start, monitorapi.Condition{Level: monitorapi.Info, Locator: "ns/e2e pod/a", Message: "reason/Created"})
+
+	server := httptest.NewServer(m.StatusHandler())
+	defer server.Close()
+	get := func(path string) (int, []byte) {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, body
+	}
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{path: "/intervals", want: 3},
+		{path: "/intervals?level=Error", want: 2},
+		{path: "/intervals?level=Info,Warning", want: 1},
+		{path: "/intervals?locator=ns/e2e", want: 1},
+		{path: "/intervals?from=" + start.Add(20*time.Second).Format(time.RFC3339), want: 1},
+		{path: "/intervals?to=" + start.Add(20*time.Second).Format(time.RFC3339), want: 2},
+	}
+	for _, tt := range tests {
+		code, body := get(tt.path)
+		if code != http.StatusOK {
+			t.Fatalf("%s: unexpected status %d: %s", tt.path, code, body)
+		}
+		intervals, err := monitorserialization.EventsFromJSON(body)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.path, err)
+		}
+		if len(intervals) != tt.want {
+			t.Errorf("%s: expected %d intervals, got %v", tt.path, tt.want, intervals)
+		}
+	}
+	if code, _ := get("/intervals?level=Critical"); code != http.StatusBadRequest {
+		t.Errorf("expected an invalid level to be rejected, got %d", code)
+	}
+
+	code, body := get("/disruption")
+	if code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", code, body)
+	}
+	statuses := &BackendDisruptionStatusList{}
+	if err := json.Unmarshal(body, statuses); err != nil {
+		t.Fatal(err)
+	}
+	status, ok := statuses.Backends["kube-api-new-connections"]
+	if !ok {
+		t.Fatalf("expected the disruption state keyed like the disruption data, got %s", body)
+	}
+	if !status.Disrupted || status.DisruptedSince == nil || !strings.Contains(status.LastDisruptionMessage, "still not responding") {
+		t.Errorf("expected the backend to be disrupted, got %+v", status)
+	}
+	if status.DisruptedDuration.Duration < 40*time.Second {
+		t.Errorf("expected the open disruption to count until now, got %s", status.DisruptedDuration.Duration)
+	}
+
+	code, body = get("/")
+	if code != http.StatusOK || !strings.Contains(string(body), "still not responding") {
+		t.Errorf("expected the chart to include the open disruption, got %d", code)
+	}
+}
@@ -35,7 +35,11 @@ func WriteTrackedResourcesForJobRun(artifactDir string, monitor *Monitor, events
 
 func WriteBackendDisruptionForJobRun(artifactDir string, monitor *Monitor, events monitorapi.Intervals, timeSuffix string) error {
 	backendDisruption := computeDisruptionData(events)
-	return writeDisruptionData(filepath.Join(artifactDir, fmt.Sprintf("backend-disruption%s.json", timeSuffix)), backendDisruption)
+	if err := writeDisruptionData(filepath.Join(artifactDir, fmt.Sprintf("backend-disruption%s.json", timeSuffix)), backendDisruption); err != nil {
+		return err
+	}
+	backendLatency := computeLatencyData(monitor.CurrentLatencies())
+	return writeDisruptionData(filepath.Join(artifactDir, fmt.Sprintf("backend-latency%s.json", timeSuffix)), backendLatency)
 }
 
 type BackendDisruptionList struct {
@@ -54,7 +58,25 @@ type BackendDisruption struct {
 	DisruptionMessages []string
 }
 
-func writeDisruptionData(filename string, disruption *BackendDisruptionList) error {
+type BackendLatencyList struct {
+	// BackendLatencies is keyed by name, the same as BackendDisruptions
+	BackendLatencies map[string]*BackendLatency
+}
+
+type BackendLatency struct {
+	// Name ensure self-identification, it includes the connection type
+	Name string
+	// BackendName is the name of backend.  It is the same across all connection types.
+	BackendName string
+	// ConnectionType is New or Reused
+	ConnectionType string
+	// Samples is the number of successful requests
+	Samples int
+	// Phases is keyed by the phase of the request: DNS, Connect, TLSHandshake, FirstByte, and Total
+	Phases map[string]*LatencyHistogram
+}
+
+func writeDisruptionData(filename string, disruption interface{}) error {
 	jsonContent, err := json.MarshalIndent(disruption, "", "    ")
 	if err != nil {
 		return err
@@ -74,10 +96,7 @@ func computeDisruptionData(eventIntervals monitorapi.Intervals) *BackendDisrupti
 	}
 
 	for _, locator := range allBackendLocators.List() {
-		locatorParts := monitorapi.LocatorParts(locator)
-		disruptionBackend := monitorapi.DisruptionFrom(locatorParts)
-		connectionType := monitorapi.DisruptionConnectionTypeFrom(locatorParts)
-		aggregatedDisruptionName := strings.ToLower(fmt.Sprintf("%s-%s-connections", disruptionBackend, connectionType))
+		disruptionBackend, aggregatedDisruptionName := aggregatedDisruptionNameFor(locator)
 
 		disruptionDuration, disruptionMessages, connectionType := monitorapi.BackendDisruptionSeconds(locator, allDisruptionEventsIntervals)
 		ret.BackendDisruptions[aggregatedDisruptionName] = &BackendDisruption{
@@ -91,3 +110,31 @@ func computeDisruptionData(eventIntervals monitorapi.Intervals) *BackendDisrupti
 
 	return ret
 }
+
+func computeLatencyData(latencies map[string]*BackendLatencySnapshot) *BackendLatencyList {
+	ret := &BackendLatencyList{
+		BackendLatencies: map[string]*BackendLatency{},
+	}
+
+	for locator, latency := range latencies {
+		backendName, aggregatedName := aggregatedDisruptionNameFor(locator)
+		ret.BackendLatencies[aggregatedName] = &BackendLatency{
+			Name:           aggregatedName,
+			BackendName:    backendName,
+			ConnectionType: strings.Title(monitorapi.DisruptionConnectionTypeFrom(monitorapi.LocatorParts(locator))),
+			Samples:        latency.Samples,
+			Phases:         latency.Phases,
+		}
+	}
+
+	return ret
+}
+
+// aggregatedDisruptionNameFor returns the backend name and the name that identifies the backend and connection type
+// in the job run data.
+func aggregatedDisruptionNameFor(locator string) (string, string) {
+	locatorParts := monitorapi.LocatorParts(locator)
+	disruptionBackend := monitorapi.DisruptionFrom(locatorParts)
+	connectionType := monitorapi.DisruptionConnectionTypeFrom(locatorParts)
+	return disruptionBackend, strings.ToLower(fmt.Sprintf("%s-%s-connections", disruptionBackend, connectionType))
+}
@@ -30,6 +30,7 @@ func testAlerts(events monitorapi.Intervals, restConfig *rest.Config) []*junitap
 		}
 		ret = append(ret, junit...)
 	}
+	ret = append(ret, allowedalerts.GenericAlertInvariants(context.TODO(), restConfig, events)...)
 
 	return ret
 }
@@ -0,0 +1,120 @@
+package allowedalerts
+
+import (
+	_ "embed"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+)
+
+// AllowedAlert is an alert the generic alert invariant does not fail or flake on.
+type AllowedAlert struct {
+	AlertName string `json:"alertName"`
+	// Namespace limits the entry to the alert in one namespace.  It applies to all namespaces when it is empty.  The
+	// generated namespaces of e2e tests are all e2e-*.
+	Namespace string `json:"namespace,omitempty"`
+	// State limits the entry to pending or firing.  It applies to both when it is empty.
+	State string `json:"state,omitempty"`
+	// Reason explains why the alert is allowed.
+	Reason string `json:"reason"`
+	// Bug links the bug to fix before the entry is removed.
+	Bug string `json:"bug,omitempty"`
+}
+
+func (a AllowedAlert) String() string {
+	if len(a.Bug) == 0 {
+		return a.Reason
+	}
+	return fmt.Sprintf("%s (%s)", a.Reason, a.Bug)
+}
+
+//go:embed allowed_alerts.yaml
+var allowedAlertsYAML []byte
+
+var (
+	allowlistLock  sync.Mutex
+	extraAllowlist []AllowedAlert
+)
+
+// SetAllowlist adds extra to the embedded list of alerts the generic alert invariant allows.
+func SetAllowlist(extra []AllowedAlert) {
+	allowlistLock.Lock()
+	defer allowlistLock.Unlock()
+
+	extraAllowlist = extra
+}
+
+// SetAllowlistFromFile calls SetAllowlist with the content of a file read by ReadAllowlistFile.
+func SetAllowlistFromFile(filename string) error {
+	allowlist, err := ReadAllowlistFile(filename)
+	if err != nil {
+		return err
+	}
+	SetAllowlist(allowlist)
+	return nil
+}
+
+// ReadAllowlistFile reads a YAML or JSON list of AllowedAlerts, like
+// [{"alertName": "KubeJobFailed", "namespace": "openshift-marketplace", "reason": "...", "bug": "https://..."}].
+func ReadAllowlistFile(filename string) ([]AllowedAlert, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	allowlist, err := allowlistFromYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %v", filename, err)
+	}
+	return allowlist, nil
+}
+
+func allowlistFromYAML(data []byte) ([]AllowedAlert, error) {
+	allowlist := []AllowedAlert{}
+	if err := yaml.UnmarshalStrict(data, &allowlist); err != nil {
+		return nil, err
+	}
+	for i, allowed := range allowlist {
+		if len(allowed.AlertName) == 0 {
+			return nil, fmt.Errorf("entry %d has no alertName", i)
+		}
+		if len(allowed.Reason) == 0 {
+			return nil, fmt.Errorf("the entry for %s must have a reason", allowed.AlertName)
+		}
+		switch allowed.State {
+		case "", alertStatePending, alertStateFiring:
+		default:
+			return nil, fmt.Errorf("the entry for %s has state %q, it must be %s or %s", allowed.AlertName, allowed.State, alertStatePending, alertStateFiring)
+		}
+	}
+	return allowlist, nil
+}
+
+func currentAllowlist() []AllowedAlert {
+	allowlist, err := allowlistFromYAML(allowedAlertsYAML)
+	if err != nil {
+		panic(err)
+	}
+
+	allowlistLock.Lock()
+	defer allowlistLock.Unlock()
+	return append(allowlist, extraAllowlist...)
+}
+
+// allowedBy returns the entry of the allowlist that allows the alert in namespace and state.
+func allowedBy(allowlist []AllowedAlert, alertName, namespace, state string) (AllowedAlert, bool) {
+	for _, allowed := range allowlist {
+		if allowed.AlertName != alertName {
+			continue
+		}
+		if len(allowed.Namespace) > 0 && allowed.Namespace != namespace {
+			continue
+		}
+		if len(allowed.State) > 0 && allowed.State != state {
+			continue
+		}
+		return allowed, true
+	}
+	return AllowedAlert{}, false
+}
@@ -0,0 +1,195 @@
+package allowedalerts
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/synthetictests/platformidentification"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	alertStatePending = "pending"
+	alertStateFiring  = "firing"
+
+	// e2eNamespaces stands for the namespaces e2e tests create, which get a new generated name every run, so that
+	// alerts in them are reported under the same test every run.
+	e2eNamespaces = "e2e-*"
+)
+
+// genericAlert is an alert in one state and namespace, which the generic alert invariant reports on separately.
+type genericAlert struct {
+	alertName string
+	namespace string
+	state     string
+}
+
+func (a genericAlert) testName() string {
+	name := fmt.Sprintf("[sig-instrumentation][invariant] alert/%s should not be %s", a.alertName, a.state)
+	if len(a.namespace) > 0 {
+		name += " in ns/" + a.namespace
+	}
+	return name
+}
+
+// GenericAlertInvariants checks every alert in alertIntervals that has no test of its own in AllAlertTests, with one
+// JUnit for each alert, state and namespace, where the e2e-* namespaces of tests count as one.  An alert fails when it
+// fires for longer than the P99 of its historical data, and flakes for longer than the P95.  Without historical data,
+// alerts with a critical severity label fail, info alerts pass and all others, including those of an unknown or
+// missing severity, flake.  Pending alerts always pass, the historical data is only of firing alerts.  Alerts in the allowlist, see SetAllowlist, are skipped
+// instead of failing or flaking.
+func GenericAlertInvariants(ctx context.Context, restConfig *rest.Config, alertIntervals monitorapi.Intervals) []*junitapi.JUnitTestCase {
+	covered := sets.NewString("Watchdog")
+	for _, alertTest := range AllAlertTests(ctx, nil) {
+		covered.Insert(alertTest.AlertName())
+	}
+
+	intervalsByAlert := map[genericAlert]monitorapi.Intervals{}
+	for _, interval := range alertIntervals {
+		locatorParts := monitorapi.LocatorParts(interval.Locator)
+		alertName := monitorapi.AlertFrom(locatorParts)
+		if len(alertName) == 0 || covered.Has(alertName) {
+			continue
+		}
+		alert := genericAlert{alertName: alertName, namespace: testNamespace(monitorapi.NamespaceFrom(locatorParts))}
+		switch {
+		case strings.Contains(interval.Message, `alertstate="pending"`):
+			alert.state = alertStatePending
+		case strings.Contains(interval.Message, `alertstate="firing"`):
+			alert.state = alertStateFiring
+		default:
+			continue
+		}
+		intervalsByAlert[alert] = append(intervalsByAlert[alert], interval)
+	}
+	if len(intervalsByAlert) == 0 {
+		return nil
+	}
+
+	alerts := []genericAlert{}
+	for alert := range intervalsByAlert {
+		alerts = append(alerts, alert)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].testName() < alerts[j].testName()
+	})
+
+	// without the job type, the historical data can not be used and the severity decides
+	jobType, _ := platformidentification.GetJobType(ctx, restConfig)
+	allowlist := currentAllowlist()
+
+	ret := []*junitapi.JUnitTestCase{}
+	for _, alert := range alerts {
+		ret = append(ret, alert.check(intervalsByAlert[alert], jobType, allowlist)...)
+	}
+	return ret
+}
+
+// testNamespace returns the namespace a test reports an alert in, e2eNamespaces for the generated ones of e2e tests.
+func testNamespace(namespace string) string {
+	if strings.HasPrefix(namespace, "e2e-") {
+		return e2eNamespaces
+	}
+	return namespace
+}
+
+func (a genericAlert) check(intervals monitorapi.Intervals, jobType *platformidentification.JobType, allowlist []AllowedAlert) []*junitapi.JUnitTestCase {
+	state, message := a.failOrFlake(intervals, jobType)
+	if state != pass {
+		if allowed, ok := allowedBy(allowlist, a.alertName, a.namespace, a.state); ok {
+			return []*junitapi.JUnitTestCase{
+				{
+					Name: a.testName(),
+					SkipMessage: &junitapi.SkipMessage{
+						Message: fmt.Sprintf("allowed: %s\n\n%s", allowed, message),
+					},
+				},
+			}
+		}
+	}
+
+	switch state {
+	case flake:
+		return []*junitapi.JUnitTestCase{
+			{
+				Name: a.testName(),
+			},
+			{
+				Name: a.testName(),
+				FailureOutput: &junitapi.FailureOutput{
+					Output: message,
+				},
+				SystemOut: message,
+			},
+		}
+
+	case fail:
+		return []*junitapi.JUnitTestCase{
+			{
+				Name: a.testName(),
+				FailureOutput: &junitapi.FailureOutput{
+					Output: message,
+				},
+				SystemOut: message,
+			},
+		}
+
+	default:
+		return []*junitapi.JUnitTestCase{
+			{
+				Name: a.testName(),
+			},
+		}
+	}
+}
+
+func (a genericAlert) failOrFlake(intervals monitorapi.Intervals, jobType *platformidentification.JobType) (testState, string) {
+	duration := intervals.Duration(1 * time.Second)
+	describe := strings.Join(intervals.Strings(), "\n")
+
+	// the historical data is of how long alerts fired, it says nothing about how long they may be pending
+	if a.state != alertStateFiring {
+		return pass, ""
+	}
+
+	if jobType != nil && getCurrentResults().HasMatch(a.alertName, *jobType) {
+		allowed, details, _ := getClosestPercentilesValues(a.alertName, *jobType)
+		if len(details) > 0 {
+			details = " " + details
+		}
+		switch {
+		case duration > allowed.P99:
+			return fail, fmt.Sprintf("%s was %s for %s on %#v (maxAllowed=%s)%s:\n\n%s",
+				a.alertName, a.state, duration, *jobType, allowed.P99, details, describe)
+		case duration > allowed.P95:
+			return flake, fmt.Sprintf("%s was %s for %s on %#v (maxAllowed=%s)%s:\n\n%s",
+				a.alertName, a.state, duration, *jobType, allowed.P95, details, describe)
+		}
+		return pass, ""
+	}
+
+	// the level of the intervals is an error for an unknown severity, so the severity label is used instead
+	state := pass
+	for _, interval := range intervals {
+		switch {
+		case strings.Contains(interval.Message, `severity="critical"`):
+			state = fail
+		case strings.Contains(interval.Message, `severity="info"`):
+		case state == pass:
+			state = flake
+		}
+	}
+	switch state {
+	case fail:
+		return fail, fmt.Sprintf("critical alert %s fired for %s and there is no historical data to allow it:\n\n%s", a.alertName, duration, describe)
+	case flake:
+		return flake, fmt.Sprintf("alert %s fired for %s and there is no historical data to allow it:\n\n%s", a.alertName, duration, describe)
+	}
+	return pass, ""
+}
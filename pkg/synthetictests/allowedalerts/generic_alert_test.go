@@ -0,0 +1,155 @@
+package allowedalerts
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/synthetictests/historicaldata"
+	"github.com/openshift/origin/pkg/synthetictests/platformidentification"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+func alertInterval(locator, state, severity string, level monitorapi.EventLevel, duration time.Duration) monitorapi.EventInterval {
+	from := time.Date(2022, 4, 1, 12, 0, 0, 0, time.UTC)
+	return monitorapi.EventInterval{
+		Condition: monitorapi.Condition{
+			Level:   level,
+			Locator: locator,
+			Message: `{alertname="` + monitorapi.AlertFrom(monitorapi.LocatorParts(locator)) + `", alertstate="` + state + `", severity="` + severity + `"}`,
+		},
+		From: from,
+		To:   from.Add(duration),
+	}
+}
+
+func testResult(junits []*junitapi.JUnitTestCase, name string) string {
+	count := 0
+	result := ""
+	for _, junit := range junits {
+		if junit.Name != name {
+			continue
+		}
+		count++
+		switch {
+		case junit.SkipMessage != nil:
+			result = "skip"
+		case junit.FailureOutput != nil:
+			result = "fail"
+		case len(result) == 0:
+			result = "pass"
+		}
+	}
+	if count == 2 && result == "fail" {
+		return "flake"
+	}
+	return result
+}
+
+func TestGenericAlertInvariants(t *testing.T) {
+	jobType := &platformidentification.JobType{Release: "4.11", FromRelease: "4.11", Platform: "aws", Architecture: "amd64", Network: "ovn", Topology: "ha"}
+	platformidentification.SetOfflineJobType(jobType)
+	defer platformidentification.SetOfflineJobType(nil)
+
+	matcher, err := historicaldata.NewMatcher([]byte(`[
+  {"Name": "MyHistoricalAlert", "Release": "4.11", "FromRelease": "4.11", "Platform": "aws", "Architecture": "amd64", "Network": "ovn", "Topology": "ha", "P95": "60", "P99": "120"}
+]`), defaultReturn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resultsLock.Lock()
+	previous := historicalData
+	historicalData = matcher
+	resultsLock.Unlock()
+	defer func() {
+		resultsLock.Lock()
+		historicalData = previous
+		resultsLock.Unlock()
+	}()
+
+	SetAllowlist([]AllowedAlert{{AlertName: "MyAllowedAlert", Namespace: "openshift-foo", Reason: "known", Bug: "https://bugzilla.redhat.com/show_bug.cgi?id=1"}})
+	defer SetAllowlist(nil)
+
+	intervals := monitorapi.Intervals{
+		alertInterval("alert/MyCriticalAlert ns/openshift-foo", "firing", "critical", monitorapi.Error, time.Minute),
+		alertInterval("alert/MyWarningAlert", "firing", "warning", monitorapi.Warning, time.Minute),
+		alertInterval("alert/MyInfoAlert", "firing", "info", monitorapi.Info, time.Minute),
+		// the monitor records an error for an unknown or missing severity
+		alertInterval("alert/MyNoneAlert", "firing", "none", monitorapi.Error, time.Minute),
+		alertInterval("alert/MyUnlabeledAlert", "firing", "", monitorapi.Error, time.Minute),
+		alertInterval("alert/MyPendingAlert", "pending", "critical", monitorapi.Info, time.Minute),
+		alertInterval("alert/MyAllowedAlert ns/openshift-foo", "firing", "critical", monitorapi.Error, time.Minute),
+		alertInterval("alert/MyAllowedAlert ns/openshift-bar", "firing", "critical", monitorapi.Error, time.Minute),
+		alertInterval("alert/MyE2EAlert ns/e2e-test-foo-abcde", "firing", "warning", monitorapi.Warning, time.Minute),
+		alertInterval("alert/MyE2EAlert ns/e2e-test-bar-fghij", "firing", "warning", monitorapi.Warning, time.Minute),
+		alertInterval("alert/MyHistoricalAlert", "firing", "critical", monitorapi.Error, 90*time.Second),
+		// pending for longer than the P95 of firing does not matter
+		alertInterval("alert/MyHistoricalAlert", "pending", "critical", monitorapi.Info, 90*time.Second),
+		alertInterval("alert/Watchdog", "firing", "none", monitorapi.Error, time.Hour),
+		alertInterval("alert/KubeAPIErrorBudgetBurn", "firing", "critical", monitorapi.Error, time.Hour),
+	}
+
+	junits := GenericAlertInvariants(context.TODO(), nil, intervals)
+	expected := map[string]string{
+		"[sig-instrumentation][invariant] alert/MyCriticalAlert should not be firing in ns/openshift-foo": "fail",
+		"[sig-instrumentation][invariant] alert/MyWarningAlert should not be firing":                      "flake",
+		"[sig-instrumentation][invariant] alert/MyInfoAlert should not be firing":                         "pass",
+		"[sig-instrumentation][invariant] alert/MyNoneAlert should not be firing":                         "flake",
+		"[sig-instrumentation][invariant] alert/MyUnlabeledAlert should not be firing":                    "flake",
+		"[sig-instrumentation][invariant] alert/MyPendingAlert should not be pending":                     "pass",
+		"[sig-instrumentation][invariant] alert/MyAllowedAlert should not be firing in ns/openshift-foo":  "skip",
+		"[sig-instrumentation][invariant] alert/MyAllowedAlert should not be firing in ns/openshift-bar":  "fail",
+		"[sig-instrumentation][invariant] alert/MyE2EAlert should not be firing in ns/e2e-*":              "flake",
+		"[sig-instrumentation][invariant] alert/MyHistoricalAlert should not be firing":                   "flake",
+		"[sig-instrumentation][invariant] alert/MyHistoricalAlert should not be pending":                  "pass",
+	}
+	for name, result := range expected {
+		if actual := testResult(junits, name); actual != result {
+			t.Errorf("%s: expected %s, got %q", name, result, actual)
+		}
+	}
+	for _, junit := range junits {
+		if strings.Contains(junit.Name, "e2e-test-") {
+			t.Errorf("unexpected test for a generated namespace %s", junit.Name)
+		}
+		if strings.Contains(junit.Name, "Watchdog") || strings.Contains(junit.Name, "KubeAPIErrorBudgetBurn") {
+			t.Errorf("unexpected test %s", junit.Name)
+		}
+	}
+}
+
+func TestReadAllowlistFile(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedError string
+	}{
+		{name: "valid", content: "- alertName: MyAlert\n  state: pending\n  reason: known\n"},
+		{name: "no-reason", content: "- alertName: MyAlert\n", expectedError: "must have a reason"},
+		{name: "bad-state", content: "- alertName: MyAlert\n  state: resolved\n  reason: known\n", expectedError: `has state "resolved"`},
+		{name: "unknown-field", content: "- alertName: MyAlert\n  reason: known\n  expires: tomorrow\n", expectedError: "unknown field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "allowlist.yaml")
+			if err := ioutil.WriteFile(filename, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := ReadAllowlistFile(filename)
+			switch {
+			case len(tt.expectedError) == 0 && err != nil:
+				t.Fatal(err)
+			case len(tt.expectedError) > 0 && (err == nil || !strings.Contains(err.Error(), tt.expectedError)):
+				t.Fatalf("expected %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+
+	if _, err := allowlistFromYAML(allowedAlertsYAML); err != nil {
+		t.Errorf("the embedded allowlist is invalid: %v", err)
+	}
+}
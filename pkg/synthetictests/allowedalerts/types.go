@@ -46,8 +46,12 @@ order by
 var queryResults []byte
 
 var (
-	readResults    sync.Once
+	resultsLock    sync.Mutex
 	historicalData historicaldata.BestMatcher
+	// historicalJSON is the query results with the generic Name, see SetHistoricalData.
+	historicalJSON = bytes.ReplaceAll(queryResults, []byte(`    "AlertName": "`), []byte(`    "Name": "`))
+	// matcherOptions configure how historicalJSON is matched to job types, see SetMatching.
+	matcherOptions = historicaldata.MatcherOptions{DefaultReturn: defaultReturn}
 )
 
 // if data is missing for a particular jobtype combination, this is the value returned.  Choose a unique value that will
@@ -55,15 +59,47 @@ var (
 const defaultReturn = 3.141
 
 func getCurrentResults() historicaldata.BestMatcher {
-	readResults.Do(
-		func() {
-			var err error
-			genericBytes := bytes.ReplaceAll(queryResults, []byte(`    "AlertName": "`), []byte(`    "Name": "`))
-			historicalData, err = historicaldata.NewMatcher(genericBytes, defaultReturn)
-			if err != nil {
-				panic(err)
-			}
-		})
+	resultsLock.Lock()
+	defer resultsLock.Unlock()
+
+	if historicalData == nil {
+		var err error
+		historicalData, err = historicaldata.NewMatcherWithOptions(historicalJSON, matcherOptions)
+		if err != nil {
+			panic(err)
+		}
+	}
 
 	return historicalData
 }
+
+// SetHistoricalData replaces the embedded query results with newHistoricalJSON, in the format
+// historicaldata.NewMatcher reads, for instance computed from past job runs by historicaldata.ComputeFromJobRuns.
+func SetHistoricalData(newHistoricalJSON []byte) error {
+	if _, err := historicaldata.NewMatcher(newHistoricalJSON, defaultReturn); err != nil {
+		return err
+	}
+
+	resultsLock.Lock()
+	defer resultsLock.Unlock()
+	historicalJSON = newHistoricalJSON
+	historicalData = nil
+	return nil
+}
+
+// SetMatching replaces how the historical data is matched to job types, and the default used without a match.
+func SetMatching(config historicaldata.MatchingConfig) {
+	options := historicaldata.MatcherOptions{
+		DefaultReturn: defaultReturn,
+		MaxDistance:   config.MaxDistance,
+		Weights:       config.Weights,
+	}
+	if config.AlertDefault != nil {
+		options.DefaultReturn = *config.AlertDefault
+	}
+
+	resultsLock.Lock()
+	defer resultsLock.Unlock()
+	matcherOptions = options
+	historicalData = nil
+}
@@ -13,6 +13,7 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	configclient "github.com/openshift/client-go/config/clientset/versioned"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/synthetictests/platformidentification"
 	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
 	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -76,6 +77,13 @@ func IsWatchdogAlert(eventInterval monitorapi.EventInterval) bool {
 }
 
 func isSNOUpgradeTest(ctx context.Context, restConfig *rest.Config) (bool, error) {
+	if restConfig == nil {
+		jobType, err := platformidentification.GetJobType(ctx, restConfig)
+		if err != nil {
+			return false, err
+		}
+		return jobType.Topology == "single" && len(jobType.FromRelease) > 0, nil
+	}
 	configClient, err := configclient.NewForConfig(restConfig)
 	if err != nil {
 		return false, fmt.Errorf("Failed to establish API server connection")
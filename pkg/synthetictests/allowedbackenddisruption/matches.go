@@ -1,13 +1,26 @@
 package allowedbackenddisruption
 
 import (
+	"strings"
 	"time"
 
 	"github.com/openshift/origin/pkg/synthetictests/platformidentification"
 )
 
 // GetAllowedDisruption uses the backend and information about the cluster to choose the best historical p95 to operate against.
-// We enforce "don't get worse" for disruption by watching the aggregate data in CI over many runs.
+// We enforce "don't get worse" for disruption by watching the aggregate data in CI over many runs.  Backend names are
+// not case sensitive, the same as in the job run data.
 func GetAllowedDisruption(backendName string, jobType platformidentification.JobType) (*time.Duration, string, error) {
-	return getCurrentResults().BestMatchP99(backendName, jobType)
+	return getCurrentResults().BestMatchP99(strings.ToLower(backendName), jobType)
+}
+
+// HasAllowedDisruptionOverride returns true if an allowed disruption was provided for the backend with SetOverrides.
+func HasAllowedDisruptionOverride(backendName string) bool {
+	return getCurrentResults().HasOverride(strings.ToLower(backendName))
+}
+
+// HasAllowedDisruptionOverrideForJobType returns true if an allowed disruption provided for the backend with
+// SetOverrides applies to the job type.
+func HasAllowedDisruptionOverrideForJobType(backendName string, jobType platformidentification.JobType) bool {
+	return getCurrentResults().HasMatchingOverride(strings.ToLower(backendName), jobType)
 }
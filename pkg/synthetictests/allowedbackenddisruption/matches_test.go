@@ -1,10 +1,14 @@
 package allowedbackenddisruption
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/openshift/origin/pkg/synthetictests/historicaldata"
 	"github.com/openshift/origin/pkg/synthetictests/platformidentification"
 )
 
@@ -57,7 +61,7 @@ func TestGetClosestP95Value(t *testing.T) {
 				},
 			},
 			expectedDuration: mustDuration("2h13m13.76s"),
-			expectedDetails:  `(no exact match for historicaldata.DataKey{Name:"ingress-to-oauth-server-reused-connections", JobType:platformidentification.JobType{Release:"4.11", FromRelease:"4.11", Platform:"azure", Architecture:"amd64", Network:"sdn", Topology:"ha"}}, fell back to historicaldata.DataKey{Name:"ingress-to-oauth-server-reused-connections", JobType:platformidentification.JobType{Release:"4.11", FromRelease:"4.10", Platform:"azure", Architecture:"amd64", Network:"sdn", Topology:"ha"}})`,
+			expectedDetails:  `(no exact match for historicaldata.DataKey{Name:"ingress-to-oauth-server-reused-connections", JobType:platformidentification.JobType{Release:"4.11", FromRelease:"4.11", Platform:"azure", Architecture:"amd64", Network:"sdn", Topology:"ha", IPStack:"", FeatureSet:"", InstallType:"", FIPS:false, Proxy:false}}, fell back to historicaldata.DataKey{Name:"ingress-to-oauth-server-reused-connections", JobType:platformidentification.JobType{Release:"4.11", FromRelease:"4.10", Platform:"azure", Architecture:"amd64", Network:"sdn", Topology:"ha", IPStack:"", FeatureSet:"", InstallType:"", FIPS:false, Proxy:false}} at distance 4)`,
 		},
 		{
 			name: "fuzzy-match-single-ovn-on-sdn",
@@ -73,7 +77,7 @@ func TestGetClosestP95Value(t *testing.T) {
 				},
 			},
 			expectedDuration: mustDuration("261.2s"),
-			expectedDetails:  `(no exact match for historicaldata.DataKey{Name:"image-registry-reused-connections", JobType:platformidentification.JobType{Release:"4.10", FromRelease:"4.10", Platform:"aws", Architecture:"amd64", Network:"ovn", Topology:"single"}}, fell back to historicaldata.DataKey{Name:"image-registry-reused-connections", JobType:platformidentification.JobType{Release:"4.10", FromRelease:"4.10", Platform:"aws", Architecture:"amd64", Network:"sdn", Topology:"single"}})`,
+			expectedDetails:  `(no exact match for historicaldata.DataKey{Name:"image-registry-reused-connections", JobType:platformidentification.JobType{Release:"4.10", FromRelease:"4.10", Platform:"aws", Architecture:"amd64", Network:"ovn", Topology:"single", IPStack:"", FeatureSet:"", InstallType:"", FIPS:false, Proxy:false}}, fell back to historicaldata.DataKey{Name:"image-registry-reused-connections", JobType:platformidentification.JobType{Release:"4.10", FromRelease:"4.10", Platform:"aws", Architecture:"amd64", Network:"sdn", Topology:"single", IPStack:"", FeatureSet:"", InstallType:"", FIPS:false, Proxy:false}} at distance 5)`,
 		},
 		{
 			name: "fuzzy-match-single-ovn-on-sdn-previous",
@@ -89,7 +93,25 @@ func TestGetClosestP95Value(t *testing.T) {
 				},
 			},
 			expectedDuration: mustDuration("263.3s"),
-			expectedDetails:  `(no exact match for historicaldata.DataKey{Name:"image-registry-new-connections", JobType:platformidentification.JobType{Release:"4.11", FromRelease:"4.11", Platform:"aws", Architecture:"amd64", Network:"ovn", Topology:"single"}}, fell back to historicaldata.DataKey{Name:"image-registry-new-connections", JobType:platformidentification.JobType{Release:"4.10", FromRelease:"4.10", Platform:"aws", Architecture:"amd64", Network:"sdn", Topology:"single"}})`,
+			expectedDetails:  `(no exact match for historicaldata.DataKey{Name:"image-registry-new-connections", JobType:platformidentification.JobType{Release:"4.11", FromRelease:"4.11", Platform:"aws", Architecture:"amd64", Network:"ovn", Topology:"single", IPStack:"", FeatureSet:"", InstallType:"", FIPS:false, Proxy:false}}, fell back to historicaldata.DataKey{Name:"image-registry-new-connections", JobType:platformidentification.JobType{Release:"4.10", FromRelease:"4.10", Platform:"aws", Architecture:"amd64", Network:"sdn", Topology:"single", IPStack:"", FeatureSet:"", InstallType:"", FIPS:false, Proxy:false}} at distance 19)`,
+		},
+		{
+			name: "coarser-match-dual-stack-fips",
+			args: args{
+				backendName: "ingress-to-oauth-server-reused-connections",
+				jobType: platformidentification.JobType{
+					Release:      "4.10",
+					FromRelease:  "4.10",
+					Platform:     "gcp",
+					Architecture: "amd64",
+					Network:      "sdn",
+					Topology:     "ha",
+					IPStack:      "dual",
+					FIPS:         true,
+				},
+			},
+			expectedDuration: mustDuration("26.74s"),
+			expectedDetails:  `(no exact match for historicaldata.DataKey{Name:"ingress-to-oauth-server-reused-connections", JobType:platformidentification.JobType{Release:"4.10", FromRelease:"4.10", Platform:"gcp", Architecture:"amd64", Network:"sdn", Topology:"ha", IPStack:"dual", FeatureSet:"", InstallType:"", FIPS:true, Proxy:false}}, fell back to historicaldata.DataKey{Name:"ingress-to-oauth-server-reused-connections", JobType:platformidentification.JobType{Release:"4.10", FromRelease:"4.10", Platform:"gcp", Architecture:"amd64", Network:"sdn", Topology:"ha", IPStack:"", FeatureSet:"", InstallType:"", FIPS:false, Proxy:false}} at distance 18)`,
 		},
 		{
 			name: "missing",
@@ -104,7 +126,7 @@ func TestGetClosestP95Value(t *testing.T) {
 				},
 			},
 			expectedDuration: mustDuration("2.718s"),
-			expectedDetails:  `(no exact or fuzzy match for jobType=platformidentification.JobType{Release:"4.10", FromRelease:"4.10", Platform:"azure", Architecture:"amd64", Network:"", Topology:"missing"})`,
+			expectedDetails:  `(no exact or fuzzy match within distance 50 for jobType=platformidentification.JobType{Release:"4.10", FromRelease:"4.10", Platform:"azure", Architecture:"amd64", Network:"", Topology:"missing", IPStack:"", FeatureSet:"", InstallType:"", FIPS:false, Proxy:false})`,
 		},
 	}
 	for _, tt := range tests {
@@ -122,3 +144,85 @@ func TestGetClosestP95Value(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAllowedDisruptionWithOverrides(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "budget.yaml")
+	err := ioutil.WriteFile(filename, []byte(`
+- Name: my-app-new-connections
+  P95: 1
+  P99: 2
+- Name: my-app-new-connections
+  Platform: aws
+  P95: 3
+  P99: 4
+- Name: kube-api-reused-connections
+  Release: "4.10"
+  P95: 5
+  P99: 6
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SetOverridesFromFile(filename); err != nil {
+		t.Fatal(err)
+	}
+	defer SetOverrides(nil)
+
+	jobType := platformidentification.JobType{
+		Release:      "4.10",
+		FromRelease:  "4.10",
+		Platform:     "azure",
+		Architecture: "amd64",
+		Network:      "sdn",
+		Topology:     "ha",
+	}
+	awsJobType := platformidentification.CloneJobType(jobType)
+	awsJobType.Platform = "aws"
+	tests := []struct {
+		name             string
+		backendName      string
+		jobType          platformidentification.JobType
+		expectedDuration time.Duration
+	}{
+		{name: "any-job-type", backendName: "my-app-new-connections", jobType: jobType, expectedDuration: 2 * time.Second},
+		{name: "most-specific", backendName: "my-app-new-connections", jobType: awsJobType, expectedDuration: 4 * time.Second},
+		{name: "replaces-historical-data", backendName: "kube-api-reused-connections", jobType: jobType, expectedDuration: 6 * time.Second},
+		{name: "no-override", backendName: "my-app-reused-connections", jobType: jobType, expectedDuration: 2718 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actualDuration, _, err := GetAllowedDisruption(tt.backendName, tt.jobType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *actualDuration != tt.expectedDuration {
+				t.Errorf("expected %s, got %s", tt.expectedDuration, *actualDuration)
+			}
+		})
+	}
+
+	if !HasAllowedDisruptionOverride("my-app-new-connections") || HasAllowedDisruptionOverride("my-app-reused-connections") {
+		t.Errorf("unexpected overrides")
+	}
+	laterJobType := platformidentification.CloneJobType(jobType)
+	laterJobType.Release = "4.11"
+	if !HasAllowedDisruptionOverrideForJobType("kube-api-reused-connections", jobType) || HasAllowedDisruptionOverrideForJobType("kube-api-reused-connections", laterJobType) {
+		t.Errorf("expected the override for 4.10 to only apply to 4.10")
+	}
+}
+
+func TestSetMatching(t *testing.T) {
+	disruptionDefault := 10.0
+	SetMatching(historicaldata.MatchingConfig{DisruptionDefault: &disruptionDefault, MaxDistance: 1})
+	defer SetMatching(historicaldata.MatchingConfig{})
+
+	// the closest data is at distance 18, further than the configured max distance
+	jobType := platformidentification.JobType{Release: "4.10", FromRelease: "4.10", Platform: "gcp", Architecture: "amd64", Network: "sdn", Topology: "ha", IPStack: "dual", FIPS: true}
+	actualDuration, details, err := GetAllowedDisruption("ingress-to-oauth-server-reused-connections", jobType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *actualDuration != 10*time.Second || !strings.Contains(details, "within distance 1 ") {
+		t.Errorf("expected the configured default, got %s %s", *actualDuration, details)
+	}
+}
@@ -3,6 +3,7 @@ package allowedbackenddisruption
 import (
 	"bytes"
 	_ "embed"
+	"strings"
 	"sync"
 
 	"github.com/openshift/origin/pkg/synthetictests/historicaldata"
@@ -53,9 +54,16 @@ order by
 //go:embed query_results.json
 var queryResults []byte
 
+// historicalJSON is the query results with the generic Name, see SetHistoricalData.
+var historicalJSON = bytes.ReplaceAll(queryResults, []byte(`    "BackendName": "`), []byte(`    "Name": "`))
+
 var (
-	readResults    sync.Once
+	resultsLock    sync.Mutex
 	historicalData historicaldata.BestMatcher
+	// overrides are merged on top of the query results, see SetOverrides.
+	overrides []historicaldata.StatisticalData
+	// matcherOptions configure how historicalJSON is matched to job types, see SetMatching.
+	matcherOptions = historicaldata.MatcherOptions{DefaultReturn: defaultReturn}
 )
 
 // if data is missing for a particular jobtype combination, this is the value returned.  Choose a unique value that will
@@ -63,15 +71,78 @@ var (
 const defaultReturn = 2.718
 
 func getCurrentResults() historicaldata.BestMatcher {
-	readResults.Do(
-		func() {
-			var err error
-			genericBytes := bytes.ReplaceAll(queryResults, []byte(`    "BackendName": "`), []byte(`    "Name": "`))
-			historicalData, err = historicaldata.NewMatcher(genericBytes, defaultReturn)
-			if err != nil {
-				panic(err)
-			}
-		})
+	resultsLock.Lock()
+	defer resultsLock.Unlock()
+
+	if historicalData == nil {
+		var err error
+		options := matcherOptions
+		options.Overrides = overrides
+		historicalData, err = historicaldata.NewMatcherWithOptions(historicalJSON, options)
+		if err != nil {
+			panic(err)
+		}
+	}
 
 	return historicalData
 }
+
+// SetOverrides replaces the allowed disruption for the backends and job types in newOverrides.  This is how backends
+// that are not in the CI data, like those from --disruption-backends, get a budget.  Backend names are lowercased, the
+// same as in the job run data.
+func SetOverrides(newOverrides []historicaldata.StatisticalData) {
+	lowercased := make([]historicaldata.StatisticalData, 0, len(newOverrides))
+	for _, override := range newOverrides {
+		override.Name = strings.ToLower(override.Name)
+		lowercased = append(lowercased, override)
+	}
+
+	resultsLock.Lock()
+	defer resultsLock.Unlock()
+
+	overrides = lowercased
+	historicalData = nil
+}
+
+// SetHistoricalData replaces the embedded query results with newHistoricalJSON, in the format
+// historicaldata.NewMatcher reads, for instance computed from past job runs by historicaldata.ComputeFromJobRuns.
+// Overrides still take precedence over it.
+func SetHistoricalData(newHistoricalJSON []byte) error {
+	if _, err := historicaldata.NewMatcher(newHistoricalJSON, defaultReturn); err != nil {
+		return err
+	}
+
+	resultsLock.Lock()
+	defer resultsLock.Unlock()
+
+	historicalJSON = newHistoricalJSON
+	historicalData = nil
+	return nil
+}
+
+// SetMatching replaces how the historical data is matched to job types, and the default used without a match.
+func SetMatching(config historicaldata.MatchingConfig) {
+	options := historicaldata.MatcherOptions{
+		DefaultReturn: defaultReturn,
+		MaxDistance:   config.MaxDistance,
+		Weights:       config.Weights,
+	}
+	if config.DisruptionDefault != nil {
+		options.DefaultReturn = *config.DisruptionDefault
+	}
+
+	resultsLock.Lock()
+	defer resultsLock.Unlock()
+	matcherOptions = options
+	historicalData = nil
+}
+
+// SetOverridesFromFile calls SetOverrides with the content of a file read by historicaldata.ReadOverridesFile.
+func SetOverridesFromFile(filename string) error {
+	newOverrides, err := historicaldata.ReadOverridesFile(filename)
+	if err != nil {
+		return err
+	}
+	SetOverrides(newOverrides)
+	return nil
+}
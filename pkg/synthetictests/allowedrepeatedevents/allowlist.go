@@ -0,0 +1,194 @@
+package allowedrepeatedevents
+
+import (
+	_ "embed"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ScopeStable entries apply only to runs without an upgrade.
+	ScopeStable = "stable"
+	// ScopeUpgrade entries apply only to upgrade runs.
+	ScopeUpgrade = "upgrade"
+	// ScopeBoth entries apply to every run.
+	ScopeBoth = "both"
+
+	// expiresFormat is the format of Entry.Expires.
+	expiresFormat = "2006-01-02"
+)
+
+// Entry is a pattern of repeated events that "events should not repeat pathologically" allows or reports as a known bug.
+type Entry struct {
+	// Pattern is a regular expression matched against "<locator> - <message>" of the repeated event.
+	Pattern string `json:"pattern"`
+	// Scope is stable, upgrade or both.
+	Scope string `json:"scope"`
+	// Platform limits the entry to one platform, like AWS.  It applies to all platforms when it is empty.
+	Platform string `json:"platform,omitempty"`
+	// Topology limits the entry to one control plane topology, like SingleReplica.  It applies to all topologies when
+	// it is empty.
+	Topology string `json:"topology,omitempty"`
+	// TestSuite limits the entry to one test suite, like openshift/build.  It applies to all suites when it is empty.
+	TestSuite string `json:"testSuite,omitempty"`
+	// Bug links the bug to fix before the entry is removed.  It is required for known bugs.
+	Bug string `json:"bug,omitempty"`
+	// Expires is the first day, as YYYY-MM-DD in UTC, the entry no longer applies.  An event that still matches it
+	// fails the test, so the stale entry is removed or renewed.
+	Expires string `json:"expires,omitempty"`
+}
+
+func (e Entry) String() string {
+	ret := fmt.Sprintf("%q", e.Pattern)
+	if len(e.Bug) > 0 {
+		ret += " (" + e.Bug + ")"
+	}
+	if len(e.Expires) > 0 {
+		ret += " expires " + e.Expires
+	}
+	return ret
+}
+
+// ExpiresAt returns when the entry no longer applies, or nil if it never expires.
+func (e Entry) ExpiresAt() *time.Time {
+	if len(e.Expires) == 0 {
+		return nil
+	}
+	// the format was checked when the entry was read
+	expires, err := time.Parse(expiresFormat, e.Expires)
+	if err != nil {
+		return nil
+	}
+	return &expires
+}
+
+func (e Entry) validate() error {
+	if len(e.Pattern) == 0 {
+		return fmt.Errorf("an entry has no pattern")
+	}
+	if _, err := regexp.Compile(e.Pattern); err != nil {
+		return fmt.Errorf("the entry %q is not a valid regular expression: %v", e.Pattern, err)
+	}
+	switch e.Scope {
+	case ScopeStable, ScopeUpgrade, ScopeBoth:
+	default:
+		return fmt.Errorf("the entry %q has scope %q, it must be %s, %s or %s", e.Pattern, e.Scope, ScopeStable, ScopeUpgrade, ScopeBoth)
+	}
+	if len(e.Expires) > 0 {
+		if _, err := time.Parse(expiresFormat, e.Expires); err != nil {
+			return fmt.Errorf("the entry %q expires %q, it must be YYYY-MM-DD", e.Pattern, e.Expires)
+		}
+	}
+	return nil
+}
+
+// Allowlist is the content of an allowlist file.
+type Allowlist struct {
+	// Allowed are repeated events that are expected, for instance because a test causes them on purpose.
+	Allowed []Entry `json:"allowed,omitempty"`
+	// KnownBugs are repeated events caused by a bug.  They flake the test instead of failing it.
+	KnownBugs []Entry `json:"knownBugs,omitempty"`
+}
+
+// forScope returns the entries that apply to runs of scope.
+func (a Allowlist) forScope(scope string) Allowlist {
+	ret := Allowlist{}
+	for _, entry := range a.Allowed {
+		if entry.Scope == ScopeBoth || entry.Scope == scope {
+			ret.Allowed = append(ret.Allowed, entry)
+		}
+	}
+	for _, entry := range a.KnownBugs {
+		if entry.Scope == ScopeBoth || entry.Scope == scope {
+			ret.KnownBugs = append(ret.KnownBugs, entry)
+		}
+	}
+	return ret
+}
+
+//go:embed allowed_repeated_events.yaml
+var allowedRepeatedEventsYAML []byte
+
+var (
+	allowlistLock   sync.Mutex
+	extraAllowlists []Allowlist
+)
+
+// SetAllowlists merges extra into the embedded allowlist.
+func SetAllowlists(extra []Allowlist) {
+	allowlistLock.Lock()
+	defer allowlistLock.Unlock()
+
+	extraAllowlists = extra
+}
+
+// SetAllowlistsFromFiles calls SetAllowlists with the content of the files read by ReadAllowlistFile.
+func SetAllowlistsFromFiles(filenames []string) error {
+	extra := []Allowlist{}
+	for _, filename := range filenames {
+		allowlist, err := ReadAllowlistFile(filename)
+		if err != nil {
+			return err
+		}
+		extra = append(extra, *allowlist)
+	}
+	SetAllowlists(extra)
+	return nil
+}
+
+// ReadAllowlistFile reads a YAML or JSON Allowlist, like
+// {"knownBugs": [{"pattern": "ns/openshift-etcd .*", "scope": "both", "bug": "https://...", "expires": "2022-06-01"}]}.
+func ReadAllowlistFile(filename string) (*Allowlist, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	allowlist, err := allowlistFromYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %v", filename, err)
+	}
+	return allowlist, nil
+}
+
+func allowlistFromYAML(data []byte) (*Allowlist, error) {
+	allowlist := &Allowlist{}
+	if err := yaml.UnmarshalStrict(data, allowlist); err != nil {
+		return nil, err
+	}
+	for _, entry := range allowlist.Allowed {
+		if err := entry.validate(); err != nil {
+			return nil, err
+		}
+	}
+	for _, entry := range allowlist.KnownBugs {
+		if err := entry.validate(); err != nil {
+			return nil, err
+		}
+		if len(entry.Bug) == 0 {
+			return nil, fmt.Errorf("the known bug %q must have a bug", entry.Pattern)
+		}
+	}
+	return allowlist, nil
+}
+
+// Current returns the entries of the embedded allowlist and the ones set by SetAllowlists that apply to runs of scope,
+// ScopeStable or ScopeUpgrade.
+func Current(scope string) Allowlist {
+	allowlist, err := allowlistFromYAML(allowedRepeatedEventsYAML)
+	if err != nil {
+		panic(err)
+	}
+
+	allowlistLock.Lock()
+	defer allowlistLock.Unlock()
+	for _, extra := range extraAllowlists {
+		allowlist.Allowed = append(allowlist.Allowed, extra.Allowed...)
+		allowlist.KnownBugs = append(allowlist.KnownBugs, extra.KnownBugs...)
+	}
+	return allowlist.forScope(scope)
+}
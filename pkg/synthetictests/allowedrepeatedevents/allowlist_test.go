@@ -0,0 +1,75 @@
+package allowedrepeatedevents
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadAllowlistFile(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedError string
+	}{
+		{name: "valid", content: "knownBugs:\n- pattern: 'reason/Foo'\n  scope: upgrade\n  bug: https://bugzilla.redhat.com/show_bug.cgi?id=1\n  expires: \"2022-06-01\"\n"},
+		{name: "bad-scope", content: "allowed:\n- pattern: 'reason/Foo'\n  scope: always\n", expectedError: `has scope "always"`},
+		{name: "bad-pattern", content: "allowed:\n- pattern: 'reason/(Foo'\n  scope: both\n", expectedError: "not a valid regular expression"},
+		{name: "bad-expires", content: "allowed:\n- pattern: 'reason/Foo'\n  scope: both\n  expires: June\n", expectedError: "must be YYYY-MM-DD"},
+		{name: "bug-without-link", content: "knownBugs:\n- pattern: 'reason/Foo'\n  scope: both\n", expectedError: "must have a bug"},
+		{name: "unknown-field", content: "allowed:\n- pattern: 'reason/Foo'\n  scope: both\n  reason: why\n", expectedError: "unknown field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "allowlist.yaml")
+			if err := ioutil.WriteFile(filename, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := ReadAllowlistFile(filename)
+			switch {
+			case len(tt.expectedError) == 0 && err != nil:
+				t.Fatal(err)
+			case len(tt.expectedError) > 0 && (err == nil || !strings.Contains(err.Error(), tt.expectedError)):
+				t.Fatalf("expected %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "allowlist.yaml")
+	err := ioutil.WriteFile(filename, []byte(`
+allowed:
+- pattern: 'reason/StableOnly'
+  scope: stable
+- pattern: 'reason/UpgradeOnly'
+  scope: upgrade
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SetAllowlistsFromFiles([]string{filename}); err != nil {
+		t.Fatal(err)
+	}
+	defer SetAllowlists(nil)
+
+	has := func(allowlist Allowlist, pattern string) bool {
+		for _, entry := range allowlist.Allowed {
+			if entry.Pattern == pattern {
+				return true
+			}
+		}
+		return false
+	}
+	stable, upgrade := Current(ScopeStable), Current(ScopeUpgrade)
+	if !has(stable, "reason/StableOnly") || has(stable, "reason/UpgradeOnly") {
+		t.Errorf("unexpected stable allowlist %v", stable.Allowed)
+	}
+	if has(upgrade, "reason/StableOnly") || !has(upgrade, "reason/UpgradeOnly") {
+		t.Errorf("unexpected upgrade allowlist %v", upgrade.Allowed)
+	}
+	if len(stable.KnownBugs) == 0 || len(stable.KnownBugs) != len(upgrade.KnownBugs) {
+		t.Errorf("expected the embedded known bugs in both scopes, got %d and %d", len(stable.KnownBugs), len(upgrade.KnownBugs))
+	}
+}
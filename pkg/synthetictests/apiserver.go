@@ -1,17 +1,23 @@
 package synthetictests
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/openshift/origin/pkg/monitor/backenddisruption"
+	"github.com/openshift/origin/pkg/synthetictests/allowedbackenddisruption"
+	"github.com/openshift/origin/pkg/synthetictests/platformidentification"
 	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
 
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
 )
 
-func testServerAvailability(owner, locator string, events monitorapi.Intervals, jobRunDuration time.Duration) []*junitapi.JUnitTestCase {
+func testServerAvailability(owner, locator string, events monitorapi.Intervals, jobRunDuration time.Duration, kubeClientConfig *rest.Config) []*junitapi.JUnitTestCase {
 	errDuration, errMessages, _ := monitorapi.BackendDisruptionSeconds(locator, events)
 
 	testName := fmt.Sprintf("[%s] %s should be available throughout the test", owner, locator)
@@ -19,6 +25,30 @@ func testServerAvailability(owner, locator string, events monitorapi.Intervals,
 		Name:     testName,
 		Duration: jobRunDuration.Seconds(),
 	}
+
+	allowedDisruption, allowedDisruptionDetails, err := disruptionBudget(locator, kubeClientConfig)
+	if err != nil {
+		return []*junitapi.JUnitTestCase{{
+			Name:     testName,
+			Duration: jobRunDuration.Seconds(),
+			FailureOutput: &junitapi.FailureOutput{
+				Output: fmt.Sprintf("unable to determine the allowed disruption for %s: %v", locator, err),
+			},
+			SystemOut: strings.Join(errMessages, "\n"),
+		}}
+	}
+
+	if allowedDisruption != nil && errDuration > *allowedDisruption {
+		return []*junitapi.JUnitTestCase{{
+			Name:     testName,
+			Duration: jobRunDuration.Seconds(),
+			FailureOutput: &junitapi.FailureOutput{
+				Output: fmt.Sprintf("%s was failing for %s seconds which is more than the allowed %s (test duration: %s) %s", locator, errDuration.Round(time.Second), allowedDisruption.Round(time.Second), jobRunDuration.Round(time.Second), allowedDisruptionDetails),
+			},
+			SystemOut: strings.Join(errMessages, "\n"),
+		}}
+	}
+
 	if errDuration > 0 {
 		test := &junitapi.JUnitTestCase{
 			Name:     testName,
@@ -37,42 +67,123 @@ func testServerAvailability(owner, locator string, events monitorapi.Intervals,
 	}
 }
 
-func testAllAPIAvailability(events monitorapi.Intervals, jobRunDuration time.Duration) []*junitapi.JUnitTestCase {
+// disruptionBudget returns the allowed disruption for the backend of the locator if one was provided with
+// --disruption-budget for the job type.  Without one, nil is returned and disruption is only reported as a flake.
+func disruptionBudget(locator string, kubeClientConfig *rest.Config) (*time.Duration, string, error) {
+	locatorParts := monitorapi.LocatorParts(locator)
+	backendName := fmt.Sprintf("%s-%s-connections", monitorapi.DisruptionFrom(locatorParts), monitorapi.DisruptionConnectionTypeFrom(locatorParts))
+	if !allowedbackenddisruption.HasAllowedDisruptionOverride(backendName) {
+		return nil, "", nil
+	}
+
+	jobType, err := platformidentification.GetJobType(context.TODO(), kubeClientConfig)
+	if err != nil {
+		return nil, "", err
+	}
+	// an override for other job types does not make the default of the historical data a budget
+	if !allowedbackenddisruption.HasAllowedDisruptionOverrideForJobType(backendName, *jobType) {
+		return nil, "", nil
+	}
+	return allowedbackenddisruption.GetAllowedDisruption(backendName, *jobType)
+}
+
+func testAllAPIAvailability(events monitorapi.Intervals, jobRunDuration time.Duration, kubeClientConfig *rest.Config) []*junitapi.JUnitTestCase {
 	allAPIServerLocators := sets.String{}
 	allDisruptionEventsIntervals := events.Filter(monitorapi.IsDisruptionEvent)
 	for _, eventInterval := range allDisruptionEventsIntervals {
-		backend := monitorapi.DisruptionFrom(monitorapi.LocatorParts(eventInterval.Locator))
-		if strings.HasSuffix(backend, "-api") {
+		if isAPIDisruptionLocator(eventInterval.Locator) {
 			allAPIServerLocators.Insert(eventInterval.Locator)
 		}
 	}
 
 	ret := []*junitapi.JUnitTestCase{}
 	for _, apiServerLocator := range allAPIServerLocators.List() {
-		ret = append(ret, testServerAvailability("sig-api-machinery", apiServerLocator, allDisruptionEventsIntervals, jobRunDuration)...)
+		ret = append(ret, testServerAvailability("sig-api-machinery", apiServerLocator, allDisruptionEventsIntervals, jobRunDuration, kubeClientConfig)...)
 	}
 
 	return ret
 }
 
-func testAllIngressAvailability(events monitorapi.Intervals, jobRunDuration time.Duration) []*junitapi.JUnitTestCase {
+func testAllIngressAvailability(events monitorapi.Intervals, jobRunDuration time.Duration, kubeClientConfig *rest.Config) []*junitapi.JUnitTestCase {
 	allAPIServerLocators := sets.String{}
 	allDisruptionEventsIntervals := events.Filter(monitorapi.IsDisruptionEvent)
 	for _, eventInterval := range allDisruptionEventsIntervals {
-		backend := monitorapi.DisruptionFrom(monitorapi.LocatorParts(eventInterval.Locator))
-		if strings.HasPrefix(backend, "ingress-") {
+		if isIngressDisruptionLocator(eventInterval.Locator) {
 			allAPIServerLocators.Insert(eventInterval.Locator)
 		}
 	}
 
 	ret := []*junitapi.JUnitTestCase{}
 	for _, apiServerLocator := range allAPIServerLocators.List() {
-		ret = append(ret, testServerAvailability("sig-network-edge", apiServerLocator, allDisruptionEventsIntervals, jobRunDuration)...)
+		ret = append(ret, testServerAvailability("sig-network-edge", apiServerLocator, allDisruptionEventsIntervals, jobRunDuration, kubeClientConfig)...)
 	}
 
 	return ret
 }
 
+var (
+	configuredBackendsLock sync.Mutex
+	// configuredBackendNames are the lowercased names of the backends added with --disruption-backends.
+	configuredBackendNames = sets.String{}
+)
+
+// SetConfiguredDisruptionBackends sets the backends added with --disruption-backends.  Each gets a [sig-disruption]
+// availability test.
+func SetConfiguredDisruptionBackends(backends *backenddisruption.BackendConfigList) {
+	names := sets.String{}
+	for _, backend := range backends.Backends {
+		names.Insert(strings.ToLower(backend.Name))
+	}
+
+	configuredBackendsLock.Lock()
+	defer configuredBackendsLock.Unlock()
+	configuredBackendNames = names
+}
+
+// SetConfiguredDisruptionBackendsFromFile calls SetConfiguredDisruptionBackends with the content of a file read by
+// backenddisruption.ReadBackendConfigFile.
+func SetConfiguredDisruptionBackendsFromFile(filename string) error {
+	backends, err := backenddisruption.ReadBackendConfigFile(filename)
+	if err != nil {
+		return err
+	}
+	SetConfiguredDisruptionBackends(backends)
+	return nil
+}
+
+// testAllConfiguredAvailability covers the backends added with --disruption-backends.  Other backends, like the image
+// registry, have tests of their own.
+func testAllConfiguredAvailability(events monitorapi.Intervals, jobRunDuration time.Duration, kubeClientConfig *rest.Config) []*junitapi.JUnitTestCase {
+	allConfiguredLocators := sets.String{}
+	allDisruptionEventsIntervals := events.Filter(monitorapi.IsDisruptionEvent)
+	for _, eventInterval := range allDisruptionEventsIntervals {
+		if isConfiguredDisruptionLocator(eventInterval.Locator) {
+			allConfiguredLocators.Insert(eventInterval.Locator)
+		}
+	}
+
+	ret := []*junitapi.JUnitTestCase{}
+	for _, configuredLocator := range allConfiguredLocators.List() {
+		ret = append(ret, testServerAvailability("sig-disruption", configuredLocator, allDisruptionEventsIntervals, jobRunDuration, kubeClientConfig)...)
+	}
+
+	return ret
+}
+
+func isConfiguredDisruptionLocator(locator string) bool {
+	configuredBackendsLock.Lock()
+	defer configuredBackendsLock.Unlock()
+	return configuredBackendNames.Has(strings.ToLower(monitorapi.DisruptionFrom(monitorapi.LocatorParts(locator))))
+}
+
+func isAPIDisruptionLocator(locator string) bool {
+	return strings.HasSuffix(monitorapi.DisruptionFrom(monitorapi.LocatorParts(locator)), "-api")
+}
+
+func isIngressDisruptionLocator(locator string) bool {
+	return strings.HasPrefix(monitorapi.DisruptionFrom(monitorapi.LocatorParts(locator)), "ingress-")
+}
+
 func testPodNodeNameIsImmutable(events monitorapi.Intervals) []*junitapi.JUnitTestCase {
 	const testName = "[sig-api-machinery] the pod.spec.nodeName field is immutable, once set cannot be changed"
 
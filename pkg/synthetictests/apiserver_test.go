@@ -0,0 +1,93 @@
+package synthetictests
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/origin/pkg/monitor/backenddisruption"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/synthetictests/allowedbackenddisruption"
+	"github.com/openshift/origin/pkg/synthetictests/historicaldata"
+	"github.com/openshift/origin/pkg/synthetictests/platformidentification"
+)
+
+func Test_testServerAvailability(t *testing.T) {
+	platformidentification.SetOfflineJobType(&platformidentification.JobType{Release: "4.11", Platform: "aws"})
+	defer platformidentification.SetOfflineJobType(nil)
+	allowedbackenddisruption.SetOverrides([]historicaldata.StatisticalData{
+		{DataKey: historicaldata.DataKey{Name: "my-app-new-connections"}, P95: 5, P99: 10},
+		{DataKey: historicaldata.DataKey{Name: "My-Other-App-new-connections"}, P95: 5, P99: 10},
+		{DataKey: historicaldata.DataKey{Name: "gcp-app-new-connections", JobType: platformidentification.JobType{Platform: "gcp"}}, P95: 5, P99: 10},
+	})
+	defer allowedbackenddisruption.SetOverrides(nil)
+
+	from := time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)
+	disruption := func(locator string, duration time.Duration) monitorapi.Intervals {
+		return monitorapi.Intervals{
+			{
+				Condition: monitorapi.Condition{Level: monitorapi.Error, Locator: locator, Message: "reason/DisruptionBegan stopped responding"},
+				From:      from,
+				To:        from.Add(duration),
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		locator     string
+		disruption  time.Duration
+		wantResults int
+		wantFailure string
+	}{
+		{name: "within-budget", locator: "disruption/my-app connection/new", disruption: 5 * time.Second, wantResults: 2},
+		{name: "over-budget", locator: "disruption/my-app connection/new", disruption: time.Minute, wantResults: 1, wantFailure: "more than the allowed 10s"},
+		{name: "mixed-case", locator: "disruption/my-other-app connection/new", disruption: time.Minute, wantResults: 1, wantFailure: "more than the allowed 10s"},
+		{name: "mixed-case-locator", locator: "disruption/My-App connection/new", disruption: time.Minute, wantResults: 1, wantFailure: "more than the allowed 10s"},
+		{name: "no-budget-flakes", locator: "disruption/my-app connection/reused", disruption: time.Minute, wantResults: 2},
+		{name: "budget-for-other-platform-flakes", locator: "disruption/gcp-app connection/new", disruption: time.Minute, wantResults: 2},
+		{name: "no-disruption", locator: "disruption/my-app connection/new", wantResults: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events monitorapi.Intervals
+			if tt.disruption > 0 {
+				events = disruption(tt.locator, tt.disruption)
+			}
+			results := testServerAvailability("sig-disruption", tt.locator, events, time.Hour, nil)
+			if len(results) != tt.wantResults {
+				t.Fatalf("expected %d results, got %d", tt.wantResults, len(results))
+			}
+			if len(tt.wantFailure) > 0 && (results[0].FailureOutput == nil || !strings.Contains(results[0].FailureOutput.Output, tt.wantFailure)) {
+				t.Errorf("expected a failure containing %q, got %#v", tt.wantFailure, results[0].FailureOutput)
+			}
+		})
+	}
+}
+
+func Test_testAllConfiguredAvailability(t *testing.T) {
+	SetConfiguredDisruptionBackends(&backenddisruption.BackendConfigList{Backends: []backenddisruption.BackendConfig{{Name: "My-App"}}})
+	defer SetConfiguredDisruptionBackends(&backenddisruption.BackendConfigList{})
+
+	from := time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)
+	events := monitorapi.Intervals{}
+	for _, locator := range []string{"disruption/my-app connection/new", "disruption/image-registry connection/new", "disruption/kube-api connection/new"} {
+		events = append(events, monitorapi.EventInterval{
+			Condition: monitorapi.Condition{Level: monitorapi.Error, Locator: locator, Message: "reason/DisruptionBegan stopped responding"},
+			From:      from,
+			To:        from.Add(time.Second),
+		})
+	}
+
+	names := sets.String{}
+	for _, junit := range testAllConfiguredAvailability(events, time.Hour, nil) {
+		names.Insert(junit.Name)
+	}
+	expected := []string{"[sig-disruption] disruption/my-app connection/new should be available throughout the test"}
+	if !reflect.DeepEqual(names.List(), expected) {
+		t.Errorf("expected only the configured backend to be tested, got %v", names.List())
+	}
+}
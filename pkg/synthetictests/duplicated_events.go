@@ -7,7 +7,9 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/openshift/origin/pkg/synthetictests/allowedrepeatedevents"
 	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
 
 	v1 "github.com/openshift/api/config/v1"
@@ -38,65 +40,9 @@ func combinedRegexp(arr ...*regexp.Regexp) *regexp.Regexp {
 	return regexp.MustCompile(s)
 }
 
+// allowedRepeatedEventPatterns are repeated events with a separate test.  The other allowed repeated events and the
+// known bugs are in the allowedrepeatedevents allowlist.
 var allowedRepeatedEventPatterns = []*regexp.Regexp{
-	// [sig-apps] StatefulSet Basic StatefulSet functionality [StatefulSetBasic] should not deadlock when a pod's predecessor fails [Suite:openshift/conformance/parallel] [Suite:k8s]
-	// PauseNewPods intentionally causes readiness probe to fail.
-	regexp.MustCompile(`ns/e2e-statefulset-[0-9]+ pod/ss-[0-9] node/[a-z0-9.-]+ - reason/Unhealthy Readiness probe failed: `),
-
-	// [sig-apps] StatefulSet Basic StatefulSet functionality [StatefulSetBasic] should perform rolling updates and roll backs of template modifications [Conformance] [Suite:openshift/conformance/parallel/minimal] [Suite:k8s]
-	// breakPodHTTPProbe intentionally causes readiness probe to fail.
-	regexp.MustCompile(`ns/e2e-statefulset-[0-9]+ pod/ss2-[0-9] node/[a-z0-9.-]+ - reason/Unhealthy Readiness probe failed: HTTP probe failed with statuscode: 404`),
-
-	// [sig-node] Probing container ***
-	// these tests intentionally cause repeated probe failures to ensure good handling
-	regexp.MustCompile(`ns/e2e-container-probe-[0-9]+ .* probe failed: `),
-	regexp.MustCompile(`ns/e2e-container-probe-[0-9]+ .* probe warning: `),
-
-	// Kubectl Port forwarding ***
-	// The same pod name is used many times for all these tests with a tight readiness check to make the tests fast.
-	// This results in hundreds of events while the pod isn't ready.
-	regexp.MustCompile(`ns/e2e-port-forwarding-[0-9]+ pod/pfpod node/[a-z0-9.-]+ - reason/Unhealthy Readiness probe failed:`),
-
-	// should not start app containers if init containers fail on a RestartAlways pod
-	// the init container intentionally fails to start
-	regexp.MustCompile(`ns/e2e-init-container-[0-9]+ pod/pod-init-[a-z0-9.-]+ node/[a-z0-9.-]+ - reason/BackOff Back-off restarting failed container`),
-
-	// TestAllowedSCCViaRBAC and TestPodUpdateSCCEnforcement
-	// The pod is shaped to intentionally not be scheduled.  Looks like an artifact of the old integration testing.
-	regexp.MustCompile(`ns/e2e-test-scc-[a-z0-9]+ pod/.* - reason/FailedScheduling.*`),
-
-	// Security Context ** should not run with an explicit root user ID
-	// Security Context ** should not run without a specified user ID
-	// This container should never run
-	regexp.MustCompile(`ns/e2e-security-context-test-[0-9]+ pod/.*-root-uid node/[a-z0-9.-]+ - reason/Failed Error: container's runAsUser breaks non-root policy.*"`),
-
-	// PersistentVolumes-local tests should not run the pod when there is a volume node
-	// affinity and node selector conflicts.
-	regexp.MustCompile(`ns/e2e-persistent-local-volumes-test-[0-9]+ pod/pod-[a-z0-9.-]+ reason/FailedScheduling`),
-
-	// various DeploymentConfig tests trigger this by canceling multiple rollouts
-	regexp.MustCompile(`reason/DeploymentAwaitingCancellation Deployment of version [0-9]+ awaiting cancellation of older running deployments`),
-
-	// this image is used specifically to be one that cannot be pulled in our tests
-	regexp.MustCompile(`.*reason/BackOff Back-off pulling image "webserver:404"`),
-
-	// If image pulls in e2e namespaces fail catastrophically we'd expect them to lead to test failures
-	// We are deliberately not ignoring image pull failures for core component namespaces
-	regexp.MustCompile(`ns/e2e-.* reason/BackOff Back-off pulling image`),
-
-	// promtail crashlooping as its being started by sideloading manifests.  per @vrutkovs
-	regexp.MustCompile("ns/openshift-e2e-loki pod/loki-promtail.*Readiness probe"),
-
-	// kube-apiserver guard probe failing due to kube-apiserver operands getting rolled out
-	// multiple times during the bootstrapping phase of a cluster installation
-	regexp.MustCompile("ns/openshift-kube-apiserver pod/kube-apiserver-guard.*ProbeError Readiness probe error"),
-	regexp.MustCompile("ns/openshift-kube-apiserver pod/kube-apiserver-guard.*Unhealthy Readiness probe failed"),
-	// the same thing happens for kube-controller-manager and kube-scheduler
-	regexp.MustCompile("ns/openshift-kube-controller-manager pod/kube-controller-manager-guard.*ProbeError Readiness probe error"),
-	regexp.MustCompile("ns/openshift-kube-controller-manager pod/kube-controller-manager-guard.*Unhealthy Readiness probe failed"),
-	regexp.MustCompile("ns/openshift-kube-scheduler pod/kube-scheduler-guard.*ProbeError Readiness probe error"),
-	regexp.MustCompile("ns/openshift-kube-scheduler pod/kube-scheduler-guard.*Unhealthy Readiness probe failed"),
-
 	// we have a separate test for this
 	regexp.MustCompile(ovnReadinessRegExpStr),
 
@@ -111,83 +57,13 @@ var allowedRepeatedEventFns = []isRepeatedEventOKFunc{
 	isConsoleReadinessDuringInstallation,
 }
 
-// allowedUpgradeRepeatedEventPatterns are patterns of events that we should only allow during upgrades, not during normal execution.
-var allowedUpgradeRepeatedEventPatterns = []*regexp.Regexp{
-	// Operators that use library-go can report about multiple versions during upgrades.
-	regexp.MustCompile(`ns/openshift-etcd-operator deployment/etcd-operator - reason/MultipleVersions multiple versions found, probably in transition: .*`),
-	regexp.MustCompile(`ns/openshift-kube-apiserver-operator deployment/kube-apiserver-operator - reason/MultipleVersions multiple versions found, probably in transition: .*`),
-	regexp.MustCompile(`ns/openshift-kube-controller-manager-operator deployment/kube-controller-manager-operator - reason/MultipleVersions multiple versions found, probably in transition: .*`),
-	regexp.MustCompile(`ns/openshift-kube-scheduler-operator deployment/openshift-kube-scheduler-operator - reason/MultipleVersions multiple versions found, probably in transition: .*`),
-
-	// etcd-quorum-guard can fail during upgrades.
-	regexp.MustCompile(`ns/openshift-etcd pod/etcd-quorum-guard-[a-z0-9-]+ node/[a-z0-9.-]+ - reason/Unhealthy Readiness probe failed: `),
-	// etcd can have unhealthy members during an upgrade
-	regexp.MustCompile(`ns/openshift-etcd-operator deployment/etcd-operator - reason/UnhealthyEtcdMember unhealthy members: .*`),
-	// etcd-operator began to version etcd-endpoints configmap in 4.10 as part of static-pod-resource. During upgrade existing revisions will not contain the resource.
-	// The condition reconciles with the next revision which the result of the upgrade. TODO(hexfusion) remove in 4.11
-	regexp.MustCompile(`ns/openshift-etcd-operator deployment/etcd-operator - reason/RequiredInstallerResourcesMissing configmaps: etcd-endpoints-[0-9]+`),
-	// There is a separate test to catch this specific case
-	regexp.MustCompile(requiredResourcesMissingRegEx),
-}
-
-var knownEventsBugs = []knownProblem{
-	{
-		Regexp: regexp.MustCompile(`ns/openshift-multus pod/network-metrics-daemon-[a-z0-9]+ node/[a-z0-9.-]+ - reason/NetworkNotReady network is not ready: container runtime network not ready: NetworkReady=false reason:NetworkPluginNotReady message:Network plugin returns error: No CNI configuration file in /etc/kubernetes/cni/net\.d/\. Has your network provider started\?`),
-		BZ:     "https://bugzilla.redhat.com/show_bug.cgi?id=1986370",
-	},
-	{
-		Regexp: regexp.MustCompile(`ns/openshift-e2e-loki pod/loki-promtail-[a-z0-9]+ node/[a-z0-9.-]+ - reason/NetworkNotReady network is not ready: container runtime network not ready: NetworkReady=false reason:NetworkPluginNotReady message:Network plugin returns error: No CNI configuration file in /etc/kubernetes/cni/net\.d/\. Has your network provider started\?`),
-		BZ:     "https://bugzilla.redhat.com/show_bug.cgi?id=1986370",
-	},
-	{
-		Regexp: regexp.MustCompile(`ns/openshift-network-diagnostics pod/network-check-target-[a-z0-9]+ node/[a-z0-9.-]+ - reason/NetworkNotReady network is not ready: container runtime network not ready: NetworkReady=false reason:NetworkPluginNotReady message:Network plugin returns error: No CNI configuration file in /etc/kubernetes/cni/net\.d/\. Has your network provider started\?`),
-		BZ:     "https://bugzilla.redhat.com/show_bug.cgi?id=1986370",
-	},
-	{
-		Regexp: regexp.MustCompile(`ns/.* service/.* - reason/FailedToDeleteOVNLoadBalancer .*`),
-		BZ:     "https://bugzilla.redhat.com/show_bug.cgi?id=1990631",
-	},
-	{
-		Regexp: regexp.MustCompile(`ns/.*horizontalpodautoscaler.*failed to get cpu utilization: unable to get metrics for resource cpu: no metrics returned from resource metrics API.*`),
-		BZ:     "https://bugzilla.redhat.com/show_bug.cgi?id=1993985",
-	},
-	{
-		Regexp: regexp.MustCompile(`ns/.*unable to ensure pod container exists: failed to create container.*slice already exists.*`),
-		BZ:     "https://bugzilla.redhat.com/show_bug.cgi?id=1993980",
-	},
-	{
-		Regexp: regexp.MustCompile(`ns/openshift-etcd pod/etcd-quorum-guard-[a-z0-9-]+ node/[a-z0-9.-]+ - reason/Unhealthy Readiness probe failed: `),
-		BZ:     "https://bugzilla.redhat.com/show_bug.cgi?id=2000234",
-	},
-	{
-		Regexp: regexp.MustCompile(`ns/openshift-etcd pod/etcd-guard-.* node/.* - reason/ProbeError Readiness probe error: .* connect: connection refused`),
-		BZ:     "https://bugzilla.redhat.com/show_bug.cgi?id=2075204",
-	},
-	{
-		Regexp: regexp.MustCompile("ns/openshift-etcd-operator namespace/openshift-etcd-operator -.*rpc error: code = Canceled desc = grpc: the client connection is closing.*"),
-		BZ:     "https://bugzilla.redhat.com/show_bug.cgi?id=2006975",
-	},
-	{
-		Regexp:   regexp.MustCompile("ns/.*reason/.*APICheckFailed.*503.*"),
-		BZ:       "https://bugzilla.redhat.com/show_bug.cgi?id=2017435",
-		Topology: topologyPointer(v1.SingleReplicaTopologyMode),
-	},
-	{
-		Regexp:    regexp.MustCompile(`ns/openshift-controller-manager daemonset/controller-manager - reason/SuccessfulDelete \(combined from similar events\): Deleted pod: controller-manager-[a-z0-9-]+`),
-		BZ:        "https://bugzilla.redhat.com/show_bug.cgi?id=2034984",
-		TestSuite: stringPointer("openshift/build"),
-	},
-	//{ TODO this should only be skipped for single-node
-	//	name:    "single=node-storage",
-	//  BZ: https://bugzilla.redhat.com/show_bug.cgi?id=1990662
-	//	message: "ns/openshift-cluster-csi-drivers pod/aws-ebs-csi-driver-controller-66469455cd-2thfv node/ip-10-0-161-38.us-east-2.compute.internal - reason/BackOff Back-off restarting failed container",
-	//},
-}
-
 type duplicateEventsEvaluator struct {
 	allowedRepeatedEventPatterns []*regexp.Regexp
 	allowedRepeatedEventFns      []isRepeatedEventOKFunc
 
+	// allowedRepeatedEvents are duplicates that are expected on some clusters and test suites
+	allowedRepeatedEvents []knownProblem
+
 	// knownRepeatedEventsBugs are duplicates that are considered bugs and should flake, but not  fail a test
 	knownRepeatedEventsBugs []knownProblem
 
@@ -213,19 +89,106 @@ type knownProblem struct {
 
 	// TestSuite limits the exception to a specific test suite (e.g. openshift/builds)
 	TestSuite *string
+
+	// Expires, if set, is when the exception stops applying.  Events that still match it fail the test.
+	Expires *time.Time
 }
 
-func testDuplicatedEventForUpgrade(events monitorapi.Intervals, kubeClientConfig *rest.Config, testSuite string) []*junitapi.JUnitTestCase {
-	allowedPatterns := []*regexp.Regexp{}
-	allowedPatterns = append(allowedPatterns, allowedRepeatedEventPatterns...)
-	allowedPatterns = append(allowedPatterns, allowedUpgradeRepeatedEventPatterns...)
+func (kp knownProblem) String() string {
+	ret := fmt.Sprintf("%q", kp.Regexp.String())
+	if len(kp.BZ) > 0 {
+		ret += " (" + kp.BZ + ")"
+	}
+	return ret
+}
+
+func (kp knownProblem) expired() bool {
+	return kp.Expires != nil && !time.Now().Before(*kp.Expires)
+}
 
-	evaluator := duplicateEventsEvaluator{
-		allowedRepeatedEventPatterns: allowedPatterns,
+// knownProblemsFrom returns the entries of an allowedrepeatedevents allowlist as knownProblems.
+func knownProblemsFrom(entries []allowedrepeatedevents.Entry) []knownProblem {
+	ret := []knownProblem{}
+	for _, entry := range entries {
+		kp := knownProblem{
+			Regexp:  regexp.MustCompile(entry.Pattern),
+			BZ:      entry.Bug,
+			Expires: entry.ExpiresAt(),
+		}
+		if len(entry.Platform) > 0 {
+			kp.Platform = platformPointer(v1.PlatformType(entry.Platform))
+		}
+		if len(entry.Topology) > 0 {
+			kp.Topology = topologyPointer(v1.TopologyMode(entry.Topology))
+		}
+		if len(entry.TestSuite) > 0 {
+			kp.TestSuite = stringPointer(entry.TestSuite)
+		}
+		ret = append(ret, kp)
+	}
+	return ret
+}
+
+// newDuplicateEventsEvaluator returns an evaluator with the allowlist entries of scope, allowedrepeatedevents.ScopeStable
+// or allowedrepeatedevents.ScopeUpgrade.
+func newDuplicateEventsEvaluator(scope, testSuite string) duplicateEventsEvaluator {
+	allowlist := allowedrepeatedevents.Current(scope)
+	return duplicateEventsEvaluator{
+		allowedRepeatedEventPatterns: allowedRepeatedEventPatterns,
 		allowedRepeatedEventFns:      allowedRepeatedEventFns,
-		knownRepeatedEventsBugs:      knownEventsBugs,
+		allowedRepeatedEvents:        knownProblemsFrom(allowlist.Allowed),
+		knownRepeatedEventsBugs:      knownProblemsFrom(allowlist.KnownBugs),
 		testSuite:                    testSuite,
 	}
+}
+
+// matches returns true if kp matches the display message of an event and applies to the platform, topology and test
+// suite under test.
+func (d duplicateEventsEvaluator) matches(kp knownProblem, display string) bool {
+	if kp.Regexp == nil || !kp.Regexp.MatchString(display) {
+		return false
+	}
+	// Check if this exception only applies to our specific platform
+	if kp.Platform != nil && *kp.Platform != d.platform {
+		return false
+	}
+	// Check if this exception only applies to a specific topology
+	if kp.Topology != nil && *kp.Topology != d.topology {
+		return false
+	}
+	// Check if this exception only applies to a specific test suite
+	if kp.TestSuite != nil && *kp.TestSuite != d.testSuite {
+		return false
+	}
+	return true
+}
+
+// matchingProblems returns the problems that apply to the display message of an event, and the expired ones that
+// would have.
+func (d duplicateEventsEvaluator) matchingProblems(problems []knownProblem, display string) (current, expired []knownProblem) {
+	for _, kp := range problems {
+		if !d.matches(kp, display) {
+			continue
+		}
+		if kp.expired() {
+			expired = append(expired, kp)
+		} else {
+			current = append(current, kp)
+		}
+	}
+	return current, expired
+}
+
+func expiredMessage(count int, display string, expired []knownProblem) string {
+	entries := []string{}
+	for _, kp := range expired {
+		entries = append(entries, fmt.Sprintf("%s expired %s", kp, kp.Expires.Format("2006-01-02")))
+	}
+	return fmt.Sprintf("event happened %d times and the allowlist entry for it is stale, remove or renew %s: %v", count, strings.Join(entries, ", "), display)
+}
+
+func testDuplicatedEventForUpgrade(events monitorapi.Intervals, kubeClientConfig *rest.Config, testSuite string) []*junitapi.JUnitTestCase {
+	evaluator := newDuplicateEventsEvaluator(allowedrepeatedevents.ScopeUpgrade, testSuite)
 
 	if err := evaluator.getClusterInfo(kubeClientConfig); err != nil {
 		e2e.Logf("could not fetch cluster info: %w", err)
@@ -238,12 +201,7 @@ func testDuplicatedEventForUpgrade(events monitorapi.Intervals, kubeClientConfig
 }
 
 func testDuplicatedEventForStableSystem(events monitorapi.Intervals, kubeClientConfig *rest.Config, testSuite string) []*junitapi.JUnitTestCase {
-	evaluator := duplicateEventsEvaluator{
-		allowedRepeatedEventPatterns: allowedRepeatedEventPatterns,
-		allowedRepeatedEventFns:      allowedRepeatedEventFns,
-		knownRepeatedEventsBugs:      knownEventsBugs,
-		testSuite:                    testSuite,
-	}
+	evaluator := newDuplicateEventsEvaluator(allowedrepeatedevents.ScopeStable, testSuite)
 
 	if err := evaluator.getClusterInfo(kubeClientConfig); err != nil {
 		e2e.Logf("could not fetch cluster info: %w", err)
@@ -301,14 +259,25 @@ func (d duplicateEventsEvaluator) testDuplicatedE2ENamespaceEvents(events monito
 // is easier to author, but less complete in its view.
 // I hate regexes, so I only do this because I really have to.
 func (d duplicateEventsEvaluator) testDuplicatedEvents(testName string, flakeOnly bool, events monitorapi.Intervals, kubeClientConfig *rest.Config) []*junitapi.JUnitTestCase {
-	allowedRepeatedEventsRegex := combinedRegexp(d.allowedRepeatedEventPatterns...)
+	var allowedRepeatedEventsRegex *regexp.Regexp
+	if len(d.allowedRepeatedEventPatterns) > 0 {
+		allowedRepeatedEventsRegex = combinedRegexp(d.allowedRepeatedEventPatterns...)
+	}
 
 	var failures []string
 	displayToCount := map[string]int{}
 	for _, event := range events {
 		eventDisplayMessage, times := getTimesAnEventHappened(fmt.Sprintf("%s - %s", event.Locator, event.Message))
 		if times > duplicateEventThreshold {
-			if allowedRepeatedEventsRegex.MatchString(eventDisplayMessage) {
+			if allowedRepeatedEventsRegex != nil && allowedRepeatedEventsRegex.MatchString(eventDisplayMessage) {
+				continue
+			}
+			current, expired := d.matchingProblems(d.allowedRepeatedEvents, eventDisplayMessage)
+			if len(current) > 0 {
+				continue
+			}
+			if len(expired) > 0 {
+				failures = append(failures, expiredMessage(times, eventDisplayMessage, expired))
 				continue
 			}
 			allowed := false
@@ -334,26 +303,14 @@ func (d duplicateEventsEvaluator) testDuplicatedEvents(testName string, flakeOnl
 	for display, count := range displayToCount {
 		msg := fmt.Sprintf("event happened %d times, something is wrong: %v", count, display)
 		flake := false
-		for _, kp := range d.knownRepeatedEventsBugs {
-			if kp.Regexp != nil && kp.Regexp.MatchString(display) {
-				// Check if this exception only applies to our specific platform
-				if kp.Platform != nil && *kp.Platform != d.platform {
-					continue
-				}
-
-				// Check if this exception only applies to a specific topology
-				if kp.Topology != nil && *kp.Topology != d.topology {
-					continue
-				}
-
-				// Check if this exception only applies to a specific test suite
-				if kp.TestSuite != nil && *kp.TestSuite != d.testSuite {
-					continue
-				}
-
-				msg += " - " + kp.BZ
-				flake = true
-			}
+		current, expired := d.matchingProblems(d.knownRepeatedEventsBugs, display)
+		if len(current) == 0 && len(expired) > 0 {
+			failures = append(failures, expiredMessage(count, display, expired))
+			continue
+		}
+		for _, kp := range current {
+			msg += " - " + kp.BZ
+			flake = true
 		}
 
 		if flake || flakeOnly {
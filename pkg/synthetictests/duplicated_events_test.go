@@ -13,6 +13,7 @@ import (
 
 	v1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/synthetictests/allowedrepeatedevents"
 )
 
 var (
@@ -95,7 +96,7 @@ func TestEventCountExtractor(t *testing.T) {
 }
 
 func TestEventRegexExcluder(t *testing.T) {
-	allowedRepeatedEventsRegex := combinedRegexp(allowedRepeatedEventPatterns...)
+	evaluator := newDuplicateEventsEvaluator(allowedrepeatedevents.ScopeStable, "unit-test")
 
 	tests := []struct {
 		name    string
@@ -133,8 +134,8 @@ func TestEventRegexExcluder(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			actual := allowedRepeatedEventsRegex.MatchString(test.message)
-			if !actual {
+			current, _ := evaluator.matchingProblems(evaluator.allowedRepeatedEvents, test.message)
+			if len(current) == 0 {
 				t.Fatal("did not match")
 			}
 		})
@@ -143,7 +144,7 @@ func TestEventRegexExcluder(t *testing.T) {
 }
 
 func TestUpgradeEventRegexExcluder(t *testing.T) {
-	allowedRepeatedEventsRegex := combinedRegexp(allowedUpgradeRepeatedEventPatterns...)
+	evaluator := newDuplicateEventsEvaluator(allowedrepeatedevents.ScopeUpgrade, "unit-test")
 
 	tests := []struct {
 		name    string
@@ -157,8 +158,8 @@ func TestUpgradeEventRegexExcluder(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			actual := allowedRepeatedEventsRegex.MatchString(test.message)
-			if !actual {
+			current, _ := evaluator.matchingProblems(evaluator.allowedRepeatedEvents, test.message)
+			if len(current) == 0 {
 				t.Fatal("did not match")
 			}
 		})
@@ -283,3 +284,66 @@ func TestKnownBugEvents(t *testing.T) {
 		})
 	}
 }
+
+func TestUpgradeOnlyEventsFailStableSystem(t *testing.T) {
+	message := `ns/openshift-etcd-operator deployment/etcd-operator - reason/UnhealthyEtcdMember unhealthy members: ip-10-0-198-128.ec2.internal`
+	evaluator := newDuplicateEventsEvaluator(allowedrepeatedevents.ScopeStable, "unit-test")
+	current, _ := evaluator.matchingProblems(evaluator.allowedRepeatedEvents, message)
+	if len(current) > 0 {
+		t.Fatalf("upgrade only entry matched a stable system: %v", current)
+	}
+}
+
+func TestExpiredEvents(t *testing.T) {
+	past := time.Now().Add(-24 * time.Hour)
+	future := time.Now().Add(24 * time.Hour)
+	evaluator := duplicateEventsEvaluator{
+		allowedRepeatedEvents: []knownProblem{
+			{Regexp: regexp.MustCompile(`reason/AllowedExpired`), BZ: "https://bugzilla.redhat.com/show_bug.cgi?id=1", Expires: &past},
+			{Regexp: regexp.MustCompile(`reason/AllowedCurrent`), Expires: &future},
+			{Regexp: regexp.MustCompile(`reason/AllowedRenewed`), Expires: &past},
+			{Regexp: regexp.MustCompile(`reason/AllowedRenewed`), Expires: &future},
+		},
+		knownRepeatedEventsBugs: []knownProblem{
+			{Regexp: regexp.MustCompile(`reason/BugExpired`), BZ: "https://bugzilla.redhat.com/show_bug.cgi?id=2", Expires: &past},
+			{Regexp: regexp.MustCompile(`reason/BugCurrent`), BZ: "https://bugzilla.redhat.com/show_bug.cgi?id=3", Expires: &future},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		message  string
+		expected string
+	}{
+		{name: "expired-allowed", message: `ns/e2e - reason/AllowedExpired foo (21 times)`, expected: `stale, remove or renew "reason/AllowedExpired" (https://bugzilla.redhat.com/show_bug.cgi?id=1) expired`},
+		{name: "current-allowed", message: `ns/e2e - reason/AllowedCurrent foo (21 times)`},
+		{name: "renewed-allowed", message: `ns/e2e - reason/AllowedRenewed foo (21 times)`},
+		{name: "expired-bug", message: `ns/e2e - reason/BugExpired foo (21 times)`, expected: `stale, remove or renew "reason/BugExpired" (https://bugzilla.redhat.com/show_bug.cgi?id=2) expired`},
+		{name: "current-bug", message: `ns/e2e - reason/BugCurrent foo (21 times)`, expected: "1 events with known BZs"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			events := monitorapi.Intervals{
+				{
+					Condition: monitorapi.Condition{Message: test.message},
+					From:      time.Unix(1, 0),
+					To:        time.Unix(1, 0),
+				},
+			}
+			junits := evaluator.testDuplicatedEvents("events should not repeat", true, events, nil)
+			if len(test.expected) == 0 {
+				if len(junits) != 1 || junits[0].FailureOutput != nil {
+					t.Fatal(spew.Sdump(junits))
+				}
+				return
+			}
+			if junits[0].FailureOutput == nil || !strings.Contains(junits[0].FailureOutput.Output, test.expected) {
+				t.Fatal(spew.Sdump(junits))
+			}
+			// expired entries fail even where repeated events only flake
+			if strings.Contains(test.expected, "stale") && len(junits) != 1 {
+				t.Fatalf("expected a failure, got %s", spew.Sdump(junits))
+			}
+		})
+	}
+}
@@ -23,8 +23,9 @@ func StableSystemEventInvariants(events monitorapi.Intervals, duration time.Dura
 	tests = append(tests, testPodTransitions(events)...)
 	tests = append(tests, testPodSandboxCreation(events)...)
 	tests = append(tests, testOvnNodeReadinessProbe(events, kubeClientConfig)...)
-	tests = append(tests, testAllAPIAvailability(events, duration)...)
-	tests = append(tests, testAllIngressAvailability(events, duration)...)
+	tests = append(tests, testAllAPIAvailability(events, duration, kubeClientConfig)...)
+	tests = append(tests, testAllIngressAvailability(events, duration, kubeClientConfig)...)
+	tests = append(tests, testAllConfiguredAvailability(events, duration, kubeClientConfig)...)
 	tests = append(tests, testStableSystemOperatorStateTransitions(events)...)
 	tests = append(tests, testDuplicatedEventForStableSystem(events, kubeClientConfig, testSuite)...)
 	tests = append(tests, testStaticPodLifecycleFailure(events, kubeClientConfig, testSuite)...)
@@ -57,6 +58,7 @@ func SystemUpgradeEventInvariants(events monitorapi.Intervals, duration time.Dur
 	tests = append(tests, testOvnNodeReadinessProbe(events, kubeClientConfig)...)
 	tests = append(tests, testNodeUpgradeTransitions(events, kubeClientConfig)...)
 	tests = append(tests, testUpgradeOperatorStateTransitions(events)...)
+	tests = append(tests, testAllConfiguredAvailability(events, duration, kubeClientConfig)...)
 	tests = append(tests, testDuplicatedEventForUpgrade(events, kubeClientConfig, testSuite)...)
 	tests = append(tests, testStaticPodLifecycleFailure(events, kubeClientConfig, testSuite)...)
 	tests = append(tests, testErrImagePullConnTimeoutOpenShiftNamespaces(events)...)
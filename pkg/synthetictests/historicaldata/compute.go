@@ -0,0 +1,249 @@
+package historicaldata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/origin/pkg/synthetictests/platformidentification"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	backendDisruptionPrefix = "backend-disruption"
+	alertsPrefix            = "alerts"
+	jobTypePrefix           = "job-type"
+)
+
+// ComputedData is the historical data computed from the artifacts of past job runs.
+type ComputedData struct {
+	Data []StatisticalData
+	// JobRuns is the number of job runs the data was computed from.
+	JobRuns int
+	// Unidentified are the files that were skipped because no job type was recorded for their job run.
+	Unidentified []string
+}
+
+// ComputeFromJobRuns walks the roots for the backend-disruption_*.json and alerts_*.json files written by job runs,
+// and computes the P95 and P99 of the disruption and alert durations in seconds for every backend or alert and job
+// type, the same as the BigQuery queries that produce the embedded data.  The job type of a run is read from the
+// job-type_*.json file with the same time suffix, or else from the closest job-type.json in the directory of the
+// file or the directories above it.  An alert missing from an alerts_*.json file did not fire in that run, so every
+// alert seen in any of the files counts as zero seconds in the files it is missing from.
+func ComputeFromJobRuns(roots []string) (*ComputedData, error) {
+	samples := map[DataKey][]float64{}
+	type alertRun struct {
+		jobType platformidentification.JobType
+		seconds map[string]float64
+	}
+	var alertRuns []alertRun
+	alertNames := map[string]struct{}{}
+	jobRuns := map[string]struct{}{}
+	computed := &ComputedData{}
+	for _, root := range roots {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || filepath.Ext(path) != ".json" {
+				return nil
+			}
+			name := strings.TrimSuffix(filepath.Base(path), ".json")
+			var readSeconds func(string) (map[string]float64, error)
+			var timeSuffix string
+			switch {
+			case strings.HasPrefix(name, backendDisruptionPrefix):
+				readSeconds, timeSuffix = readDisruptionSeconds, strings.TrimPrefix(name, backendDisruptionPrefix)
+			case strings.HasPrefix(name, alertsPrefix):
+				readSeconds, timeSuffix = readAlertSeconds, strings.TrimPrefix(name, alertsPrefix)
+			default:
+				return nil
+			}
+
+			jobType, jobTypeFile, err := jobTypeForJobRun(root, filepath.Dir(path), timeSuffix)
+			if err != nil {
+				return err
+			}
+			if jobType == nil {
+				computed.Unidentified = append(computed.Unidentified, path)
+				return nil
+			}
+			seconds, err := readSeconds(path)
+			if err != nil {
+				return fmt.Errorf("unable to read %s: %v", path, err)
+			}
+			if strings.HasPrefix(name, alertsPrefix) {
+				alertRuns = append(alertRuns, alertRun{jobType: *jobType, seconds: seconds})
+				for alertName := range seconds {
+					alertNames[alertName] = struct{}{}
+				}
+			} else {
+				for name, value := range seconds {
+					key := DataKey{Name: name, JobType: *jobType}
+					samples[key] = append(samples[key], value)
+				}
+			}
+			jobRuns[jobTypeFile+timeSuffix] = struct{}{}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, run := range alertRuns {
+		for name := range alertNames {
+			key := DataKey{Name: name, JobType: run.jobType}
+			samples[key] = append(samples[key], run.seconds[name])
+		}
+	}
+
+	for key, values := range samples {
+		sort.Float64s(values)
+		computed.Data = append(computed.Data, StatisticalData{
+			DataKey: key,
+			P95:     percentile(values, 0.95),
+			P99:     percentile(values, 0.99),
+		})
+	}
+	sort.Slice(computed.Data, func(i, j int) bool {
+		return computed.Data[i].DataKey.less(computed.Data[j].DataKey)
+	})
+	computed.JobRuns = len(jobRuns)
+	return computed, nil
+}
+
+// jobTypeForJobRun returns the job type recorded for the job run that wrote the files with timeSuffix in dir, and the
+// file it was read from.  It returns nil when none was recorded.
+func jobTypeForJobRun(root, dir, timeSuffix string) (*platformidentification.JobType, string, error) {
+	candidates := []string{filepath.Join(dir, jobTypePrefix+timeSuffix+".json")}
+	for current := dir; ; current = filepath.Dir(current) {
+		candidates = append(candidates, filepath.Join(current, jobTypePrefix+".json"))
+		if rel, err := filepath.Rel(root, current); err != nil || rel == "." || filepath.Dir(current) == current {
+			break
+		}
+	}
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err != nil {
+			continue
+		}
+		jobType, err := platformidentification.JobTypeFromFile(candidate)
+		if err != nil {
+			return nil, "", err
+		}
+		return jobType, candidate, nil
+	}
+	return nil, "", nil
+}
+
+// readDisruptionSeconds returns the disruption of every backend and connection type in a backend-disruption_*.json
+// file.
+func readDisruptionSeconds(path string) (map[string]float64, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	disruption := struct {
+		BackendDisruptions map[string]struct {
+			Name              string
+			DisruptedDuration metav1.Duration
+		}
+	}{}
+	if err := json.Unmarshal(data, &disruption); err != nil {
+		return nil, err
+	}
+	seconds := map[string]float64{}
+	for name, backend := range disruption.BackendDisruptions {
+		if len(backend.Name) > 0 {
+			name = backend.Name
+		}
+		seconds[name] = backend.DisruptedDuration.Seconds()
+	}
+	return seconds, nil
+}
+
+// readAlertSeconds returns how long every alert in an alerts_*.json file fired.  The durations of an alert in all
+// namespaces are added up, and the level it fired at for longest is used.
+func readAlertSeconds(path string) (map[string]float64, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	alerts := struct {
+		Alerts []struct {
+			Name     string
+			Level    string
+			Duration metav1.Duration
+		}
+	}{}
+	if err := json.Unmarshal(data, &alerts); err != nil {
+		return nil, err
+	}
+	byLevel := map[string]map[string]float64{}
+	for _, alert := range alerts.Alerts {
+		if byLevel[alert.Name] == nil {
+			byLevel[alert.Name] = map[string]float64{}
+		}
+		byLevel[alert.Name][alert.Level] += alert.Duration.Seconds()
+	}
+	seconds := map[string]float64{}
+	for name, levels := range byLevel {
+		for _, value := range levels {
+			seconds[name] = math.Max(seconds[name], value)
+		}
+	}
+	return seconds, nil
+}
+
+// percentile interpolates between the closest sorted values like PERCENTILE_CONT in BigQuery.
+func percentile(sorted []float64, p float64) float64 {
+	rank := p * float64(len(sorted)-1)
+	lower, upper := int(math.Floor(rank)), int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+// less orders the keys like the BigQuery queries that produce the embedded data.
+func (k DataKey) less(other DataKey) bool {
+	for _, field := range []struct{ a, b string }{
+		{k.Name, other.Name},
+		{k.Release, other.Release},
+		{k.FromRelease, other.FromRelease},
+		{k.Topology, other.Topology},
+		{k.Platform, other.Platform},
+		{k.Network, other.Network},
+		{k.Architecture, other.Architecture},
+		{k.IPStack, other.IPStack},
+		{k.FeatureSet, other.FeatureSet},
+		{k.InstallType, other.InstallType},
+		{strconv.FormatBool(k.FIPS), strconv.FormatBool(other.FIPS)},
+		{strconv.FormatBool(k.Proxy), strconv.FormatBool(other.Proxy)},
+	} {
+		if field.a != field.b {
+			return field.a < field.b
+		}
+	}
+	return false
+}
+
+// MarshalStatisticalData serializes data in the format NewMatcher reads.
+func MarshalStatisticalData(data []StatisticalData) ([]byte, error) {
+	type encodingPercentile struct {
+		DataKey `json:",inline"`
+		P95     string
+		P99     string
+	}
+	encoded := []encodingPercentile{}
+	for _, curr := range data {
+		encoded = append(encoded, encodingPercentile{
+			DataKey: curr.DataKey,
+			P95:     strconv.FormatFloat(curr.P95, 'f', -1, 64),
+			P99:     strconv.FormatFloat(curr.P99, 'f', -1, 64),
+		})
+	}
+	return json.MarshalIndent(encoded, "", "  ")
+}
@@ -0,0 +1,89 @@
+package historicaldata
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/origin/pkg/synthetictests/platformidentification"
+)
+
+func TestComputeFromJobRuns(t *testing.T) {
+	aws := platformidentification.JobType{Release: "4.11", Platform: "aws", Architecture: "amd64", Network: "ovn", Topology: "ha"}
+	gcp := platformidentification.JobType{Release: "4.11", Platform: "gcp", Architecture: "amd64", Network: "sdn", Topology: "ha"}
+
+	root := t.TempDir()
+	write := func(path, content string) {
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	disruption := func(seconds string) string {
+		return `{"BackendDisruptions": {"kube-api-new-connections": {"Name": "kube-api-new-connections", "DisruptedDuration": "` + seconds + `"}}}`
+	}
+	for i, seconds := range []string{"0s", "1s", "2s", "3s", "10s"} {
+		dir := filepath.Join("aws", string(rune('a'+i)))
+		write(filepath.Join(dir, "job-type_20220401-100000.json"), `{"Release": "4.11", "Platform": "aws", "Architecture": "amd64", "Network": "ovn", "Topology": "ha"}`)
+		write(filepath.Join(dir, "backend-disruption_20220401-100000.json"), disruption(seconds))
+		// an alert that fired in one of the runs, which did not fire in the others
+		alerts := `{"Alerts": []}`
+		if i == 0 {
+			alerts = `{"Alerts": [{"Name": "KubePodNotReady", "Namespace": "openshift-etcd", "Level": "Warning", "Duration": "100s"}]}`
+		}
+		write(filepath.Join(dir, "alerts_20220401-100000.json"), alerts)
+	}
+	// a run that did not record its job type, with one provided for the whole directory
+	write("gcp/job-type.json", `{"Release": "4.11", "Platform": "gcp", "Architecture": "amd64", "Network": "sdn", "Topology": "ha"}`)
+	write("gcp/run/artifacts/backend-disruption_20220401-100000.json", disruption("4s"))
+	write("gcp/run/artifacts/alerts_20220401-100000.json", `{"Alerts": [
+		{"Name": "KubeAPIErrorBudgetBurn", "Namespace": "openshift-kube-apiserver", "Level": "Warning", "Duration": "30s"},
+		{"Name": "KubeAPIErrorBudgetBurn", "Namespace": "openshift-apiserver", "Level": "Warning", "Duration": "20s"},
+		{"Name": "KubeAPIErrorBudgetBurn", "Level": "Critical", "Duration": "40s"}
+	]}`)
+	write("unknown/backend-disruption_20220401-100000.json", disruption("100s"))
+
+	computed, err := ComputeFromJobRuns([]string{root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if computed.JobRuns != 6 {
+		t.Errorf("expected 6 job runs, got %d", computed.JobRuns)
+	}
+	if expected := []string{filepath.Join(root, "unknown/backend-disruption_20220401-100000.json")}; !reflect.DeepEqual(computed.Unidentified, expected) {
+		t.Errorf("expected %v to be unidentified, got %v", expected, computed.Unidentified)
+	}
+	expected := []StatisticalData{
+		{DataKey: DataKey{Name: "KubeAPIErrorBudgetBurn", JobType: aws}, P95: 0, P99: 0},
+		{DataKey: DataKey{Name: "KubeAPIErrorBudgetBurn", JobType: gcp}, P95: 50, P99: 50},
+		{DataKey: DataKey{Name: "KubePodNotReady", JobType: aws}, P95: 80, P99: 96},
+		{DataKey: DataKey{Name: "KubePodNotReady", JobType: gcp}, P95: 0, P99: 0},
+		{DataKey: DataKey{Name: "kube-api-new-connections", JobType: aws}, P95: 8.6, P99: 9.72},
+		{DataKey: DataKey{Name: "kube-api-new-connections", JobType: gcp}, P95: 4, P99: 4},
+	}
+	for i := range computed.Data {
+		// allow for rounding in the interpolation
+		computed.Data[i].P95 = float64(int(computed.Data[i].P95*1000+0.5)) / 1000
+		computed.Data[i].P99 = float64(int(computed.Data[i].P99*1000+0.5)) / 1000
+	}
+	if !reflect.DeepEqual(computed.Data, expected) {
+		t.Fatalf("expected %#v, got %#v", expected, computed.Data)
+	}
+
+	data, err := MarshalStatisticalData(computed.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matcher, err := NewMatcher(data, 2.718)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if match, details, _ := matcher.BestMatch("kube-api-new-connections", aws); match != expected[4] {
+		t.Errorf("expected the computed data to be read back, got %#v %s", match, details)
+	}
+}
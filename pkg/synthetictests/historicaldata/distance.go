@@ -0,0 +1,114 @@
+package historicaldata
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/openshift/origin/pkg/synthetictests/platformidentification"
+)
+
+// DistanceWeights are how far apart two job types are for each dimension they differ in.  The closest historical
+// data to a job type is the data whose job type has the smallest sum of distances.
+type DistanceWeights struct {
+	// Release is the distance per minor release between the releases.
+	Release float64
+	// FromRelease is the distance per minor release between the releases two upgrades start from.
+	FromRelease float64
+	// Upgrade is the distance between an upgrade and a job that does not upgrade.
+	Upgrade float64
+
+	Platform     float64
+	Architecture float64
+	Network      float64
+	Topology     float64
+	IPStack      float64
+	FeatureSet   float64
+	InstallType  float64
+	FIPS         float64
+	Proxy        float64
+}
+
+// DefaultDistanceWeights prefer data for the same release over the same platform over the same network and
+// architecture.  Single node and highly available clusters, and upgrades and installs, are never close enough.
+var DefaultDistanceWeights = DistanceWeights{
+	Release:      10,
+	FromRelease:  4,
+	Upgrade:      100,
+	Platform:     20,
+	Architecture: 8,
+	Network:      5,
+	Topology:     100,
+	IPStack:      15,
+	FeatureSet:   5,
+	InstallType:  3,
+	FIPS:         3,
+	Proxy:        3,
+}
+
+// DefaultMaxDistance is how far the closest historical data may be from a job type before the default is returned
+// instead.
+const DefaultMaxDistance = 50
+
+// Distance returns how far apart the job types are.
+func (w DistanceWeights) Distance(a, b platformidentification.JobType) float64 {
+	distance := w.Release * releaseDistance(a.Release, b.Release)
+	switch {
+	case len(a.FromRelease) == 0 && len(b.FromRelease) == 0:
+	case len(a.FromRelease) == 0 || len(b.FromRelease) == 0:
+		distance += w.Upgrade
+	default:
+		distance += w.FromRelease * releaseDistance(a.FromRelease, b.FromRelease)
+	}
+	for _, field := range []struct {
+		a, b   string
+		weight float64
+	}{
+		{a.Platform, b.Platform, w.Platform},
+		{a.Architecture, b.Architecture, w.Architecture},
+		{a.Network, b.Network, w.Network},
+		{a.Topology, b.Topology, w.Topology},
+		{a.IPStack, b.IPStack, w.IPStack},
+		{a.FeatureSet, b.FeatureSet, w.FeatureSet},
+		{a.InstallType, b.InstallType, w.InstallType},
+		{strconv.FormatBool(a.FIPS), strconv.FormatBool(b.FIPS), w.FIPS},
+		{strconv.FormatBool(a.Proxy), strconv.FormatBool(b.Proxy), w.Proxy},
+	} {
+		if field.a != field.b {
+			distance += field.weight
+		}
+	}
+	return distance
+}
+
+// releaseDistance returns the number of minor releases between two major.minor releases.  Releases of different
+// major versions, or that can not be parsed, are ten minor releases apart.
+func releaseDistance(a, b string) float64 {
+	if a == b {
+		return 0
+	}
+	aMajor, aMinor, aOK := parseRelease(a)
+	bMajor, bMinor, bOK := parseRelease(b)
+	if !aOK || !bOK || aMajor != bMajor {
+		return 10
+	}
+	if aMinor > bMinor {
+		return float64(aMinor - bMinor)
+	}
+	return float64(bMinor - aMinor)
+}
+
+func parseRelease(release string) (int, int, bool) {
+	parts := strings.Split(release, ".")
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
@@ -0,0 +1,136 @@
+package historicaldata
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openshift/origin/pkg/synthetictests/platformidentification"
+)
+
+func TestBestMatchClosest(t *testing.T) {
+	historicalJSON := []byte(`[
+  {"Name": "kube-api-new-connections", "Release": "4.10", "Platform": "aws", "Architecture": "s390x", "Network": "sdn", "Topology": "ha", "P95": "6", "P99": "6"},
+  {"Name": "kube-api-new-connections", "Release": "4.10", "Platform": "aws", "Architecture": "arm64", "Network": "ovn", "Topology": "ha", "P95": "1", "P99": "1"},
+  {"Name": "kube-api-new-connections", "Release": "4.10", "Platform": "aws", "Architecture": "amd64", "Network": "sdn", "Topology": "ha", "P95": "2", "P99": "2"},
+  {"Name": "kube-api-new-connections", "Release": "4.9", "Platform": "aws", "Architecture": "amd64", "Network": "ovn", "Topology": "ha", "P95": "3", "P99": "3"},
+  {"Name": "kube-api-new-connections", "Release": "4.10", "Platform": "gcp", "Architecture": "amd64", "Network": "ovn", "Topology": "ha", "P95": "4", "P99": "4"},
+  {"Name": "kube-api-new-connections", "Release": "4.10", "FromRelease": "4.9", "Platform": "aws", "Architecture": "amd64", "Network": "ovn", "Topology": "ha", "P95": "5", "P99": "5"}
+]`)
+	aws := platformidentification.JobType{Release: "4.10", Platform: "aws", Architecture: "amd64", Network: "ovn", Topology: "ha"}
+	single := platformidentification.CloneJobType(aws)
+	single.Topology = "single"
+	ppc := platformidentification.CloneJobType(aws)
+	ppc.Architecture = "ppc64le"
+	ppc.Network = "sdn"
+
+	tests := []struct {
+		name         string
+		maxDistance  float64
+		jobType      platformidentification.JobType
+		expectedP99  float64
+		expectedText string
+	}{
+		{name: "different-network-is-closest", jobType: aws, expectedP99: 2, expectedText: "at distance 5"},
+		{name: "equally-close-use-the-first-in-order", jobType: ppc, expectedP99: 2, expectedText: "at distance 8"},
+		{name: "different-topology-is-never-close", jobType: single, expectedP99: 9, expectedText: "no exact or fuzzy match within distance 50"},
+		{name: "configured-max-distance", maxDistance: 4, jobType: aws, expectedP99: 9, expectedText: "within distance 4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher, err := NewMatcherWithOptions(historicalJSON, MatcherOptions{DefaultReturn: 9, MaxDistance: tt.maxDistance})
+			if err != nil {
+				t.Fatal(err)
+			}
+			match, details, err := matcher.BestMatch("kube-api-new-connections", tt.jobType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if match.P99 != tt.expectedP99 || !strings.Contains(details, tt.expectedText) {
+				t.Errorf("expected %v with %q, got %v with %q", tt.expectedP99, tt.expectedText, match.P99, details)
+			}
+		})
+	}
+}
+
+func TestDistance(t *testing.T) {
+	base := platformidentification.JobType{Release: "4.11", FromRelease: "4.11", Platform: "aws", Architecture: "amd64", Network: "ovn", Topology: "ha"}
+	tests := []struct {
+		name     string
+		modify   func(*platformidentification.JobType)
+		expected float64
+	}{
+		{name: "same", modify: func(*platformidentification.JobType) {}, expected: 0},
+		{name: "minor-upgrade", modify: func(j *platformidentification.JobType) { j.FromRelease = "4.10" }, expected: 4},
+		{name: "previous-release", modify: func(j *platformidentification.JobType) { j.Release, j.FromRelease = "4.9", "4.9" }, expected: 28},
+		{name: "not-an-upgrade", modify: func(j *platformidentification.JobType) { j.FromRelease = "" }, expected: 100},
+		{name: "fips-proxy-dual-stack", modify: func(j *platformidentification.JobType) { j.FIPS, j.Proxy, j.IPStack = true, true, "dual" }, expected: 21},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := platformidentification.CloneJobType(base)
+			tt.modify(&other)
+			if actual := DefaultDistanceWeights.Distance(base, other); actual != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestReadMatchingConfigFile(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedError string
+		validate      func(*testing.T, *MatchingConfig)
+	}{
+		{
+			name:    "partial-weights-keep-defaults",
+			content: "DisruptionDefault: 10\nMaxDistance: 30\nWeights:\n  Platform: 40\n",
+			validate: func(t *testing.T, config *MatchingConfig) {
+				expected := DefaultDistanceWeights
+				expected.Platform = 40
+				if *config.Weights != expected {
+					t.Errorf("expected weights %+v, got %+v", expected, *config.Weights)
+				}
+				if config.AlertDefault != nil || config.DisruptionDefault == nil || *config.DisruptionDefault != 10 || config.MaxDistance != 30 {
+					t.Errorf("unexpected config %+v", config)
+				}
+			},
+		},
+		{
+			name:    "no-weights",
+			content: "AlertDefault: 0\n",
+			validate: func(t *testing.T, config *MatchingConfig) {
+				if *config.Weights != DefaultDistanceWeights {
+					t.Errorf("expected the default weights, got %+v", *config.Weights)
+				}
+				if config.AlertDefault == nil || *config.AlertDefault != 0 {
+					t.Errorf("expected a zero alert default, got %v", config.AlertDefault)
+				}
+			},
+		},
+		{name: "negative-default", content: "DisruptionDefault: -1\n", expectedError: "DisruptionDefault is negative"},
+		{name: "unknown-field", content: "Default: 1\n", expectedError: "unknown field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "matching.yaml")
+			if err := ioutil.WriteFile(filename, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			config, err := ReadMatchingConfigFile(filename)
+			switch {
+			case len(tt.expectedError) == 0 && err != nil:
+				t.Fatal(err)
+			case len(tt.expectedError) > 0:
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected an error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			tt.validate(t, config)
+		})
+	}
+}
@@ -4,21 +4,32 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/openshift/origin/pkg/synthetictests/platformidentification"
+	"sigs.k8s.io/yaml"
 )
 
 type BestMatcher interface {
 	// BestMatch returns the best possible match for this historical data.  It attempts a full match first, then
-	// it attempts to match on the most important keys in order, before giving up and returning a default.
+	// it picks the data for the closest job type, before giving up and returning a default.
 	BestMatch(name string, jopType platformidentification.JobType) (StatisticalData, string, error)
 	// BestMatchDuration returns the best possible match for this historical data.  It attempts a full match first, then
-	// it attempts to match on the most important keys in order, before giving up and returning a default.
+	// it picks the data for the closest job type, before giving up and returning a default.
 	BestMatchDuration(name string, jopType platformidentification.JobType) (StatisticalDuration, string, error)
 
 	BestMatchP99(name string, jobType platformidentification.JobType) (*time.Duration, string, error)
+
+	// HasOverride returns true if an override was provided for name, whatever the job type.
+	HasOverride(name string) bool
+	// HasMatchingOverride returns true if an override was provided for name that applies to jobType.
+	HasMatchingOverride(name string, jobType platformidentification.JobType) bool
+	// HasMatch returns true if BestMatch finds an override or historical data for name and jobType instead of returning
+	// the default.
+	HasMatch(name string, jobType platformidentification.JobType) bool
 }
 
 type StatisticalDuration struct {
@@ -42,11 +53,43 @@ type DataKey struct {
 
 type bestMatcher struct {
 	historicalData map[DataKey]StatisticalData
-	defaultReturn  float64
+	// byName is the historicalData for each name, ordered to break ties between equally close job types.
+	byName map[string][]StatisticalData
+	// overrides take precedence over historicalData.  Empty fields in the JobType of an override match any value.
+	overrides     []StatisticalData
+	defaultReturn float64
+	weights       DistanceWeights
+	maxDistance   float64
+}
+
+// MatcherOptions configure a BestMatcher.
+type MatcherOptions struct {
+	// DefaultReturn is the P95 and P99 returned when there is no historical data within MaxDistance of a job type.
+	DefaultReturn float64
+	// MaxDistance is how far the closest historical data may be from a job type.  DefaultMaxDistance is used when it
+	// is zero.
+	MaxDistance float64
+	// Weights are used to compute the distance between job types.  DefaultDistanceWeights are used when it is nil.
+	Weights *DistanceWeights
+	// Overrides take precedence over the historical data, see NewMatcherWithOverrides.
+	Overrides []StatisticalData
 }
 
 func NewMatcher(historicalJSON []byte, defaultReturn float64) (BestMatcher, error) {
+	return NewMatcherWithOverrides(historicalJSON, defaultReturn, nil)
+}
+
+// NewMatcherWithOverrides returns a BestMatcher for historicalJSON where the overrides take precedence over the
+// historical data.  Fields left empty or false in the JobType of an override match any value, so a single override
+// can cover every job that runs a backend the historical data knows nothing about.
+func NewMatcherWithOverrides(historicalJSON []byte, defaultReturn float64, overrides []StatisticalData) (BestMatcher, error) {
+	return NewMatcherWithOptions(historicalJSON, MatcherOptions{DefaultReturn: defaultReturn, Overrides: overrides})
+}
+
+// NewMatcherWithOptions returns a BestMatcher for historicalJSON configured by options.
+func NewMatcherWithOptions(historicalJSON []byte, options MatcherOptions) (BestMatcher, error) {
 	historicalData := map[DataKey]StatisticalData{}
+	byName := map[string][]StatisticalData{}
 
 	inFile := bytes.NewBuffer(historicalJSON)
 	jsonDecoder := json.NewDecoder(inFile)
@@ -76,39 +119,192 @@ func NewMatcher(historicalJSON []byte, defaultReturn float64) (BestMatcher, erro
 			P95:     p95,
 			P99:     p99,
 		}
+		if _, ok := historicalData[curr.DataKey]; !ok {
+			byName[curr.Name] = append(byName[curr.Name], curr)
+		}
 		historicalData[curr.DataKey] = curr
 	}
+	for _, data := range byName {
+		sort.Slice(data, func(i, j int) bool {
+			return data[i].DataKey.less(data[j].DataKey)
+		})
+	}
 
-	return &bestMatcher{
+	matcher := &bestMatcher{
 		historicalData: historicalData,
-		defaultReturn:  defaultReturn,
-	}, nil
+		byName:         byName,
+		overrides:      options.Overrides,
+		defaultReturn:  options.DefaultReturn,
+		weights:        DefaultDistanceWeights,
+		maxDistance:    options.MaxDistance,
+	}
+	if options.Weights != nil {
+		matcher.weights = *options.Weights
+	}
+	if matcher.maxDistance == 0 {
+		matcher.maxDistance = DefaultMaxDistance
+	}
+	return matcher, nil
+}
+
+// MatchingConfig configures how the historical data of alerts and disruption is matched to a job type.
+type MatchingConfig struct {
+	// AlertDefault is the P95 and P99 in seconds of alerts without historical data within MaxDistance of a job type.
+	// The built in default is used when it is not set.
+	AlertDefault *float64
+	// DisruptionDefault is the P95 and P99 in seconds of backends without historical data within MaxDistance of a job
+	// type.  The built in default is used when it is not set.
+	DisruptionDefault *float64
+	// MaxDistance is how far the closest historical data may be from a job type.  DefaultMaxDistance is used when it
+	// is zero.
+	MaxDistance float64
+	// Weights are used to compute the distance between job types.  Weights that are not set keep their value in
+	// DefaultDistanceWeights.
+	Weights *DistanceWeights
+}
+
+// ReadMatchingConfigFile reads a YAML or JSON MatchingConfig, for instance
+// {"DisruptionDefault": 10, "MaxDistance": 30, "Weights": {"Platform": 40}}.
+func ReadMatchingConfigFile(filename string) (*MatchingConfig, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	weights := DefaultDistanceWeights
+	config := &MatchingConfig{Weights: &weights}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %v", filename, err)
+	}
+	if config.Weights == nil {
+		config.Weights = &weights
+	}
+	switch {
+	case config.AlertDefault != nil && *config.AlertDefault < 0:
+		return nil, fmt.Errorf("%s: AlertDefault is negative", filename)
+	case config.DisruptionDefault != nil && *config.DisruptionDefault < 0:
+		return nil, fmt.Errorf("%s: DisruptionDefault is negative", filename)
+	case config.MaxDistance < 0:
+		return nil, fmt.Errorf("%s: MaxDistance is negative", filename)
+	}
+	return config, nil
+}
+
+// ReadOverridesFile reads a YAML or JSON list of StatisticalData to pass to NewMatcherWithOverrides.  P95 and P99 are
+// in seconds, for instance [{"Name": "my-app-new-connections", "Platform": "aws", "P95": 1.5, "P99": 4}].
+func ReadOverridesFile(filename string) ([]StatisticalData, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	overrides := []StatisticalData{}
+	if err := yaml.UnmarshalStrict(data, &overrides); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %v", filename, err)
+	}
+	for i, override := range overrides {
+		if len(override.Name) == 0 {
+			return nil, fmt.Errorf("%s: entry %d has no Name", filename, i)
+		}
+		if override.P95 < 0 || override.P99 < 0 {
+			return nil, fmt.Errorf("%s: entry %d for %s has a negative percentile", filename, i, override.Name)
+		}
+	}
+	return overrides, nil
+}
+
+// HasOverride returns true if an override was provided for name, whatever the job type.
+func (b *bestMatcher) HasOverride(name string) bool {
+	for _, override := range b.overrides {
+		if override.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *bestMatcher) HasMatchingOverride(name string, jobType platformidentification.JobType) bool {
+	_, ok := b.bestOverride(DataKey{Name: name, JobType: jobType})
+	return ok
+}
+
+// bestOverride returns the override for key with the most fields set, preferring the earlier override on ties.
+func (b *bestMatcher) bestOverride(key DataKey) (StatisticalData, bool) {
+	best, bestFields := StatisticalData{}, -1
+	for _, override := range b.overrides {
+		if override.Name != key.Name {
+			continue
+		}
+		fields := 0
+		matches := true
+		for _, field := range []struct{ override, actual string }{
+			{override.Release, key.Release},
+			{override.FromRelease, key.FromRelease},
+			{override.Platform, key.Platform},
+			{override.Architecture, key.Architecture},
+			{override.Network, key.Network},
+			{override.Topology, key.Topology},
+			{override.IPStack, key.IPStack},
+			{override.FeatureSet, key.FeatureSet},
+			{override.InstallType, key.InstallType},
+			{strconv.FormatBool(override.FIPS), strconv.FormatBool(key.FIPS)},
+			{strconv.FormatBool(override.Proxy), strconv.FormatBool(key.Proxy)},
+		} {
+			if len(field.override) == 0 || field.override == "false" {
+				continue
+			}
+			if field.override != field.actual {
+				matches = false
+				break
+			}
+			fields++
+		}
+		if matches && fields > bestFields {
+			best, bestFields = override, fields
+		}
+	}
+	return best, bestFields >= 0
 }
 
 func (b *bestMatcher) BestMatch(name string, jobType platformidentification.JobType) (StatisticalData, string, error) {
+	percentiles, details, _ := b.bestMatch(name, jobType)
+	return percentiles, details, nil
+}
+
+func (b *bestMatcher) HasMatch(name string, jobType platformidentification.JobType) bool {
+	_, _, ok := b.bestMatch(name, jobType)
+	return ok
+}
+
+// bestMatch returns false with the default when there is no override or historical data for the job type.
+func (b *bestMatcher) bestMatch(name string, jobType platformidentification.JobType) (StatisticalData, string, bool) {
 	exactMatchKey := DataKey{
 		Name:    name,
 		JobType: jobType,
 	}
 
+	if percentiles, ok := b.bestOverride(exactMatchKey); ok {
+		return percentiles, fmt.Sprintf("(using override for %#v)", percentiles.DataKey), true
+	}
+
 	if percentiles, ok := b.historicalData[exactMatchKey]; ok {
-		return percentiles, "", nil
+		return percentiles, "", true
 	}
 
-	// tested in TestGetClosestP95Value in allowedbackendisruption.  Should get a local test at some point.
-	for _, nextBestGuesser := range nextBestGuessers {
-		nextBestJobType, ok := nextBestGuesser(jobType)
-		if !ok {
+	// tested in TestGetClosestP95Value in allowedbackendisruption and allowedalerts.
+	var closest *StatisticalData
+	var closestDistance float64
+	for i, candidate := range b.byName[name] {
+		distance := b.weights.Distance(jobType, candidate.JobType)
+		if distance > b.maxDistance {
 			continue
 		}
-		nextBestMatchKey := DataKey{
-			Name:    name,
-			JobType: nextBestJobType,
-		}
-		if percentiles, ok := b.historicalData[nextBestMatchKey]; ok {
-			return percentiles, fmt.Sprintf("(no exact match for %#v, fell back to %#v)", exactMatchKey, nextBestMatchKey), nil
+		// candidates are ordered, so the first of equally close candidates is used
+		if closest == nil || distance < closestDistance {
+			closest, closestDistance = &b.byName[name][i], distance
 		}
 	}
+	if closest != nil {
+		return *closest, fmt.Sprintf("(no exact match for %#v, fell back to %#v at distance %v)", exactMatchKey, closest.DataKey, closestDistance), true
+	}
 
 	defaultReturn := StatisticalData{
 		DataKey: exactMatchKey,
@@ -116,8 +312,8 @@ func (b *bestMatcher) BestMatch(name string, jobType platformidentification.JobT
 		P99:     b.defaultReturn,
 	}
 	return defaultReturn,
-		fmt.Sprintf("(no exact or fuzzy match for jobType=%#v)", jobType),
-		nil
+		fmt.Sprintf("(no exact or fuzzy match within distance %v for jobType=%#v)", b.maxDistance, jobType),
+		false
 }
 
 func (b *bestMatcher) BestMatchDuration(name string, jobType platformidentification.JobType) (StatisticalDuration, string, error) {
@@ -493,6 +493,13 @@ func buildTestsFailIfRegexMatch(testName string, matchRE, dontMatchRE *regexp.Re
 // Minor Upgrades to 4.11 include a one time update to NTO that might update machine configs during upgrades
 // Use this function to determine if we need to run extra checks to account for the update
 func is411MinorUpgrade(kubeClientConfig *rest.Config) (bool, error) {
+	if kubeClientConfig == nil {
+		jobType, err := platformidentification.GetJobType(context.TODO(), kubeClientConfig)
+		if err != nil {
+			return false, err
+		}
+		return jobType.Release == "4.11" && len(jobType.FromRelease) > 0 && jobType.FromRelease != "4.11", nil
+	}
 	ocClient, err := openshiftcorev1.NewForConfig(kubeClientConfig)
 	if err != nil {
 		return false, err
@@ -517,6 +524,9 @@ var realTimeKernelRE = regexp.MustCompile(".*.rt[0-9]+.[0-9]+..*")
 // Use this function to get a list of worker nodes that run a realtime kernel
 func getRealTimeWorkerNodes(kubeClientConfig *rest.Config) (nodes map[string]int, err error) {
 	nodes = make(map[string]int)
+	if kubeClientConfig == nil {
+		return nodes, fmt.Errorf("no cluster is available to list worker nodes")
+	}
 	kubeNodeClient, err := corev1.NewForConfig(kubeClientConfig)
 	if err != nil {
 		return nodes, err
@@ -2,13 +2,21 @@ package platformidentification
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
 	"strings"
+	"sync"
 
 	configv1 "github.com/openshift/api/config/v1"
 	configclient "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
 	exutil "github.com/openshift/origin/test/extended/util"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
@@ -20,6 +28,21 @@ type JobType struct {
 	Architecture string
 	Network      string
 	Topology     string
+
+	// The remaining fields are empty or false for the most common clusters, so historical data recorded before they
+	// were added still matches those clusters.
+
+	// IPStack is ipv6 or dual for clusters with IPv6 or dual stack cluster networks, empty for IPv4.
+	IPStack string `json:",omitempty"`
+	// FeatureSet is the feature set of the cluster, like TechPreviewNoUpgrade, empty for the default feature set.
+	FeatureSet string `json:",omitempty"`
+	// InstallType is upi for clusters whose control plane machines are not managed by the machine API, empty for
+	// installer provisioned clusters.
+	InstallType string `json:",omitempty"`
+	// FIPS is true when the cluster was installed in FIPS mode.
+	FIPS bool `json:",omitempty"`
+	// Proxy is true when the cluster reaches the internet through a proxy.
+	Proxy bool `json:",omitempty"`
 }
 
 func CloneJobType(in JobType) JobType {
@@ -30,11 +53,67 @@ func CloneJobType(in JobType) JobType {
 		Architecture: in.Architecture,
 		Network:      in.Network,
 		Topology:     in.Topology,
+		IPStack:      in.IPStack,
+		FeatureSet:   in.FeatureSet,
+		InstallType:  in.InstallType,
+		FIPS:         in.FIPS,
+		Proxy:        in.Proxy,
+	}
+}
+
+var (
+	offlineJobTypeLock sync.Mutex
+	offlineJobType     *JobType
+)
+
+// SetOfflineJobType sets the JobType GetJobType returns when it has no clientConfig, for instance when events from
+// an earlier run are analyzed without a cluster.
+func SetOfflineJobType(jobType *JobType) {
+	offlineJobTypeLock.Lock()
+	defer offlineJobTypeLock.Unlock()
+	if jobType == nil {
+		offlineJobType = nil
+		return
+	}
+	clone := CloneJobType(*jobType)
+	offlineJobType = &clone
+}
+
+// JobTypeFromFile reads a JobType serialized as JSON.
+func JobTypeFromFile(filename string) (*JobType, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	jobType := &JobType{}
+	if err := json.Unmarshal(data, jobType); err != nil {
+		return nil, fmt.Errorf("unable to read job type from %s: %w", filename, err)
 	}
+	return jobType, nil
 }
 
-// GetJobType returns information that can be used to identify a job
+// WriteJobType writes jobType as JSON, to be read by JobTypeFromFile.
+func WriteJobType(filename string, jobType *JobType) error {
+	data, err := json.MarshalIndent(jobType, "", "    ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0644)
+}
+
+// GetJobType returns information that can be used to identify a job.  Without a clientConfig it returns the JobType
+// set by SetOfflineJobType.
 func GetJobType(ctx context.Context, clientConfig *rest.Config) (*JobType, error) {
+	if clientConfig == nil {
+		offlineJobTypeLock.Lock()
+		defer offlineJobTypeLock.Unlock()
+		if offlineJobType == nil {
+			return nil, errors.New("no cluster is available to determine the job type and no job type was provided")
+		}
+		clone := CloneJobType(*offlineJobType)
+		return &clone, nil
+	}
+
 	configClient, err := configclient.NewForConfig(clientConfig)
 	if err != nil {
 		return nil, err
@@ -106,9 +185,90 @@ func GetJobType(ctx context.Context, clientConfig *rest.Config) (*JobType, error
 		Architecture: architecture,
 		Network:      networkType,
 		Topology:     topology,
+		IPStack:      getIPStack(network),
+		FeatureSet:   getFeatureSet(ctx, configClient),
+		InstallType:  getInstallType(ctx, clientConfig),
+		FIPS:         getFIPS(clientConfig),
+		Proxy:        getProxy(ctx, configClient),
 	}, nil
 }
 
+// getIPStack returns ipv6 or dual based on the cluster networks, or empty for IPv4.
+func getIPStack(network *configv1.Network) string {
+	var ipv4, ipv6 bool
+	for _, clusterNetwork := range network.Status.ClusterNetwork {
+		ip, _, err := net.ParseCIDR(clusterNetwork.CIDR)
+		if err != nil {
+			continue
+		}
+		if ip.To4() != nil {
+			ipv4 = true
+		} else {
+			ipv6 = true
+		}
+	}
+	switch {
+	case ipv4 && ipv6:
+		return "dual"
+	case ipv6:
+		return "ipv6"
+	}
+	return ""
+}
+
+// The dimensions below refine the job type, so they are left empty when they can not be determined rather than
+// failing to identify the cluster.
+
+func getFeatureSet(ctx context.Context, configClient configclient.ConfigV1Interface) string {
+	featureGate, err := configClient.FeatureGates().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		return ""
+	}
+	return string(featureGate.Spec.FeatureSet)
+}
+
+func getProxy(ctx context.Context, configClient configclient.ConfigV1Interface) bool {
+	proxy, err := configClient.Proxies().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		return false
+	}
+	return len(proxy.Status.HTTPProxy) > 0 || len(proxy.Status.HTTPSProxy) > 0
+}
+
+func getFIPS(clientConfig *rest.Config) bool {
+	kubeClient, err := kubernetes.NewForConfig(clientConfig)
+	if err != nil {
+		return false
+	}
+	fips, err := exutil.IsFIPS(kubeClient.CoreV1())
+	if err != nil {
+		return false
+	}
+	return fips
+}
+
+// getInstallType returns upi when there are no control plane machines, which the installer creates for the clusters
+// it provisions.
+func getInstallType(ctx context.Context, clientConfig *rest.Config) string {
+	dynamicClient, err := dynamic.NewForConfig(clientConfig)
+	if err != nil {
+		return ""
+	}
+	machines, err := dynamicClient.Resource(schema.GroupVersionResource{Group: "machine.openshift.io", Version: "v1beta1", Resource: "machines"}).
+		Namespace("openshift-machine-api").
+		List(ctx, metav1.ListOptions{LabelSelector: "machine.openshift.io/cluster-api-machine-role=master"})
+	switch {
+	case apierrors.IsNotFound(err):
+		// without the machine API, no machines are managed
+		return "upi"
+	case err != nil:
+		return ""
+	case len(machines.Items) == 0:
+		return "upi"
+	}
+	return ""
+}
+
 func VersionFromHistory(history configv1.UpdateHistory) string {
 	versionParts := strings.Split(history.Version, ".")
 	if len(versionParts) < 2 {
@@ -49,6 +49,18 @@ func testStaticPodLifecycleFailure(events monitorapi.Intervals, kubeClientConfig
 	const testName = `[sig-node] static pods should start after being created`
 	failures := []string{}
 
+	// static pod failures are only visible in the events stored in the cluster
+	if kubeClientConfig == nil {
+		return []*junitapi.JUnitTestCase{
+			{
+				Name: testName,
+				SkipMessage: &junitapi.SkipMessage{
+					Message: "no cluster is available to read events from",
+				},
+			},
+		}
+	}
+
 	kubeClient, err := kubernetes.NewForConfig(kubeClientConfig)
 	if err != nil {
 		return []*junitapi.JUnitTestCase{
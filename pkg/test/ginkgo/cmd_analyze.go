@@ -0,0 +1,169 @@
+package ginkgo
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/openshift/origin/pkg/synthetictests"
+	"github.com/openshift/origin/pkg/synthetictests/allowedalerts"
+	"github.com/openshift/origin/pkg/synthetictests/allowedbackenddisruption"
+	"github.com/openshift/origin/pkg/synthetictests/allowedrepeatedevents"
+	"github.com/openshift/origin/pkg/synthetictests/platformidentification"
+)
+
+// AnalyzeOptions runs the invariants of a suite against the events saved by an earlier run, without a cluster.
+type AnalyzeOptions struct {
+	// EventFiles are e2e-events_*.json files or event journals written by a run.
+	EventFiles []string
+	// ResourceFiles are resource-*.zip files written by a run.
+	ResourceFiles []string
+	// JobTypeFile is a JSON serialized JobType describing the cluster the events came from.  Invariants that need
+	// to identify the cluster fail or skip without it.
+	JobTypeFile string
+	// DisruptionBackendsFile are the extra backends the run monitored, which get availability tests, the same as for
+	// run.
+	DisruptionBackendsFile string
+	// DisruptionBudgetFile is the allowed disruption for backends and job types, the same as for run.
+	DisruptionBudgetFile string
+	// HistoricalDataFile is used by the invariants instead of the embedded historical data, the same as for run.
+	HistoricalDataFile string
+	// HistoricalDataMatchingFile is how the invariants match historical data to the job type, the same as for run.
+	HistoricalDataMatchingFile string
+	// AlertAllowlistFile is added to the alerts the generic alert invariant allows, the same as for run.
+	AlertAllowlistFile string
+	// RepeatedEventsAllowlistFiles are merged into the embedded allowlist of repeated events, the same as for run.
+	RepeatedEventsAllowlistFiles []string
+	JUnitDir                     string
+
+	// SyntheticEventTests are the invariants to evaluate.
+	SyntheticEventTests JUnitsForEvents
+
+	RunDataWriters []RunDataWriter
+
+	Out, ErrOut io.Writer
+}
+
+func NewAnalyzeOptions() *AnalyzeOptions {
+	return &AnalyzeOptions{
+		RunDataWriters: NewOptions().RunDataWriters,
+		Out:            os.Stdout,
+		ErrOut:         os.Stderr,
+	}
+}
+
+func (opt *AnalyzeOptions) Run(suiteName, junitSuiteName string) error {
+	if len(opt.EventFiles) == 0 {
+		return fmt.Errorf("at least one events file must be specified")
+	}
+	if len(opt.JUnitDir) == 0 {
+		return fmt.Errorf("--junit-dir must be specified")
+	}
+	if err := os.MkdirAll(opt.JUnitDir, 0755); err != nil {
+		return fmt.Errorf("could not create --junit-dir: %v", err)
+	}
+
+	if len(opt.DisruptionBackendsFile) > 0 {
+		if err := synthetictests.SetConfiguredDisruptionBackendsFromFile(opt.DisruptionBackendsFile); err != nil {
+			return err
+		}
+	}
+	if len(opt.DisruptionBudgetFile) > 0 {
+		if err := allowedbackenddisruption.SetOverridesFromFile(opt.DisruptionBudgetFile); err != nil {
+			return err
+		}
+	}
+	if len(opt.HistoricalDataFile) > 0 {
+		if err := setHistoricalDataFromFile(opt.HistoricalDataFile); err != nil {
+			return fmt.Errorf("could not read --historical-data: %v", err)
+		}
+	}
+	if len(opt.HistoricalDataMatchingFile) > 0 {
+		if err := setHistoricalDataMatchingFromFile(opt.HistoricalDataMatchingFile); err != nil {
+			return fmt.Errorf("could not read --historical-data-matching: %v", err)
+		}
+	}
+	if len(opt.AlertAllowlistFile) > 0 {
+		if err := allowedalerts.SetAllowlistFromFile(opt.AlertAllowlistFile); err != nil {
+			return err
+		}
+	}
+	if len(opt.RepeatedEventsAllowlistFiles) > 0 {
+		if err := allowedrepeatedevents.SetAllowlistsFromFiles(opt.RepeatedEventsAllowlistFiles); err != nil {
+			return err
+		}
+	}
+	if len(opt.JobTypeFile) > 0 {
+		jobType, err := platformidentification.JobTypeFromFile(opt.JobTypeFile)
+		if err != nil {
+			return err
+		}
+		platformidentification.SetOfflineJobType(jobType)
+		defer platformidentification.SetOfflineJobType(nil)
+	}
+
+	m := monitor.NewMonitor()
+	var events monitorapi.Intervals
+	for _, filename := range opt.EventFiles {
+		if strings.HasSuffix(filename, ".jsonl") {
+			journaled, err := monitor.LoadJournal(filename)
+			if err != nil {
+				return err
+			}
+			events = append(events, journaled.Intervals(time.Time{}, time.Time{})...)
+			continue
+		}
+		saved, err := monitorserialization.EventsFromFile(filename)
+		if err != nil {
+			return fmt.Errorf("unable to read events from %s: %v", filename, err)
+		}
+		events = append(events, saved...)
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("no events were found in %s", strings.Join(opt.EventFiles, ", "))
+	}
+	sort.Sort(events)
+
+	for _, filename := range opt.ResourceFiles {
+		resourceType, instances, err := monitorserialization.InstanceMapFromFile(filename)
+		if err != nil {
+			return fmt.Errorf("unable to read resources from %s: %v", filename, err)
+		}
+		m.RestoreResources(resourceType, instances)
+	}
+
+	// the run is assumed to span the saved events
+	start := events[0].From
+	end := start
+	for _, event := range events {
+		if event.To.After(end) {
+			end = event.To
+		}
+	}
+	events.Clamp(start, end)
+	duration := end.Sub(start).Round(time.Second)
+	timeSuffix := fmt.Sprintf("_%s", start.UTC().Format("20060102-150405"))
+
+	if err := writeRunDataToArtifactsDir(opt.RunDataWriters, opt.JUnitDir, m, events, timeSuffix); err != nil {
+		fmt.Fprintf(opt.ErrOut, "error: Failed to write run-data: %v\n", err)
+	}
+
+	syntheticTestResults, buf, syntheticFailure := evaluateSyntheticTests(events, duration, nil, opt.SyntheticEventTests, suiteName)
+	opt.Out.Write(buf.Bytes())
+
+	if err := writeJUnitReport("junit_e2e", junitSuiteName, nil, opt.JUnitDir, duration, opt.ErrOut, syntheticTestResults...); err != nil {
+		fmt.Fprintf(opt.Out, "error: Unable to write e2e JUnit results: %v", err)
+	}
+
+	if syntheticFailure {
+		return fmt.Errorf("failed because an invariant was violated (%s)", duration)
+	}
+	fmt.Fprintf(opt.Out, "%d events analyzed (%s)\n", len(events), duration)
+	return nil
+}
@@ -0,0 +1,65 @@
+package ginkgo
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	"k8s.io/client-go/rest"
+)
+
+func TestAnalyzeOptions_Run(t *testing.T) {
+	dir := t.TempDir()
+	eventsFile := filepath.Join(dir, "e2e-events_20220401-100000.json")
+	from := time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)
+	err := monitorserialization.EventsToFile(eventsFile, monitorapi.Intervals{
+		{Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: "node/node1", Message: "reason/NotReady"}, From: from, To: from.Add(time.Minute)},
+		{Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: "node/node1", Message: "reason/Ready"}, From: from.Add(time.Hour), To: from.Add(time.Hour)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gotDuration time.Duration
+	var gotConfig *rest.Config
+	out := &bytes.Buffer{}
+	opt := &AnalyzeOptions{
+		EventFiles: []string{eventsFile},
+		JUnitDir:   filepath.Join(dir, "junit"),
+		SyntheticEventTests: JUnitForEventsFunc(func(events monitorapi.Intervals, duration time.Duration, kubeClientConfig *rest.Config, testSuite string) []*junitapi.JUnitTestCase {
+			gotDuration, gotConfig = duration, kubeClientConfig
+			return []*junitapi.JUnitTestCase{
+				{Name: "nodes should not go not ready", FailureOutput: &junitapi.FailureOutput{Output: "node1 went not ready"}},
+			}
+		}),
+		Out:    out,
+		ErrOut: out,
+	}
+	if err := opt.Run("openshift/conformance", "openshift-tests"); err == nil || !strings.Contains(err.Error(), "invariant was violated") {
+		t.Fatalf("expected the failing invariant to fail the analysis, got %v", err)
+	}
+	if gotDuration != time.Hour || gotConfig != nil {
+		t.Errorf("unexpected invariant arguments: duration=%s config=%v", gotDuration, gotConfig)
+	}
+	if !strings.Contains(out.String(), "Failing invariants:\n\nnodes should not go not ready") {
+		t.Errorf("failing invariant was not reported:\n%s", out.String())
+	}
+
+	junits, err := filepath.Glob(filepath.Join(opt.JUnitDir, "junit_e2e_*.xml"))
+	if err != nil || len(junits) != 1 {
+		t.Fatalf("expected one junit file, got %v: %v", junits, err)
+	}
+	data, err := ioutil.ReadFile(junits[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "node1 went not ready") {
+		t.Errorf("junit does not contain the invariant failure:\n%s", data)
+	}
+}
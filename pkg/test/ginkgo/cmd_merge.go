@@ -0,0 +1,186 @@
+package ginkgo
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+// MergeOptions combines the results that the shards of a suite wrote to their --junit-dir into one report.
+type MergeOptions struct {
+	// Dirs are the --junit-dir of each shard.
+	Dirs     []string
+	JUnitDir string
+
+	// RunDataWriters are given the events of all the shards.
+	RunDataWriters []RunDataWriter
+
+	Out, ErrOut io.Writer
+}
+
+func NewMergeOptions() *MergeOptions {
+	return &MergeOptions{
+		RunDataWriters: eventIntervalRunDataWriters(),
+		Out:            os.Stdout,
+		ErrOut:         os.Stderr,
+	}
+}
+
+func (opt *MergeOptions) Run() error {
+	if len(opt.Dirs) == 0 {
+		return fmt.Errorf("at least one shard directory must be specified")
+	}
+	if len(opt.JUnitDir) == 0 {
+		return fmt.Errorf("--junit-dir must be specified")
+	}
+	if err := os.MkdirAll(opt.JUnitDir, 0755); err != nil {
+		return fmt.Errorf("could not create --junit-dir: %v", err)
+	}
+
+	var name string
+	var duration float64
+	var shardTestCases [][]*junitapi.JUnitTestCase
+	var events monitorapi.Intervals
+	for _, dir := range opt.Dirs {
+		var testCases []*junitapi.JUnitTestCase
+		junitFiles, err := filepath.Glob(filepath.Join(dir, "junit_e2e_*.xml"))
+		if err != nil {
+			return err
+		}
+		if len(junitFiles) == 0 {
+			return fmt.Errorf("no junit_e2e_*.xml results were found in %s", dir)
+		}
+		for _, filename := range junitFiles {
+			suite, err := readJUnitSuite(filename)
+			if err != nil {
+				return err
+			}
+			if len(name) == 0 {
+				name = suite.Name
+			}
+			// the shards run at the same time, so the suite took as long as the slowest one
+			if suite.Duration > duration {
+				duration = suite.Duration
+			}
+			testCases = append(testCases, suite.TestCases...)
+		}
+		shardTestCases = append(shardTestCases, testCases)
+
+		eventFiles, err := filepath.Glob(filepath.Join(dir, "e2e-events_*.json"))
+		if err != nil {
+			return err
+		}
+		for _, filename := range eventFiles {
+			saved, err := monitorserialization.EventsFromFile(filename)
+			if err != nil {
+				return fmt.Errorf("unable to read events from %s: %v", filename, err)
+			}
+			events = append(events, saved...)
+		}
+	}
+
+	testCases := mergeShardTestCases(shardTestCases)
+
+	if len(events) > 0 {
+		sort.Sort(events)
+		timeSuffix := fmt.Sprintf("_%s", events[0].From.UTC().Format("20060102-150405"))
+		if err := writeRunDataToArtifactsDir(opt.RunDataWriters, opt.JUnitDir, monitor.NewMonitor(), events, timeSuffix); err != nil {
+			fmt.Fprintf(opt.ErrOut, "error: Failed to write run-data: %v\n", err)
+		}
+	}
+
+	if err := writeJUnitReport("junit_e2e", name, nil, opt.JUnitDir, time.Duration(duration*float64(time.Second)), opt.ErrOut, testCases...); err != nil {
+		return fmt.Errorf("unable to write merged JUnit results: %v", err)
+	}
+	fmt.Fprintf(opt.Out, "Merged %d test results and %d events from %d shards\n", len(testCases), len(events), len(opt.Dirs))
+	return nil
+}
+
+// mergeShardTestCases combines the test cases of the shards.  Every shard evaluates the invariants, so a test with
+// results in several shards is an invariant: it fails if it failed in any shard, flakes if it flaked in any, and
+// otherwise passes once.  Tests that ran in one shard keep all their results.
+func mergeShardTestCases(shardTestCases [][]*junitapi.JUnitTestCase) []*junitapi.JUnitTestCase {
+	var names []string
+	byName := map[string][][]*junitapi.JUnitTestCase{}
+	for _, testCases := range shardTestCases {
+		shardByName := map[string][]*junitapi.JUnitTestCase{}
+		for _, testCase := range testCases {
+			if _, ok := byName[testCase.Name]; !ok {
+				names = append(names, testCase.Name)
+				byName[testCase.Name] = nil
+			}
+			shardByName[testCase.Name] = append(shardByName[testCase.Name], testCase)
+		}
+		for name, cases := range shardByName {
+			byName[name] = append(byName[name], cases)
+		}
+	}
+
+	var merged []*junitapi.JUnitTestCase
+	for _, name := range names {
+		shards := byName[name]
+		if len(shards) == 1 {
+			merged = append(merged, shards[0]...)
+			continue
+		}
+
+		var failures, flakes []*junitapi.JUnitTestCase
+		var passed *junitapi.JUnitTestCase
+		for _, cases := range shards {
+			var shardFailures []*junitapi.JUnitTestCase
+			var shardPassed *junitapi.JUnitTestCase
+			for _, testCase := range cases {
+				switch {
+				case testCase.FailureOutput != nil:
+					shardFailures = append(shardFailures, testCase)
+				case testCase.SkipMessage == nil && shardPassed == nil:
+					shardPassed = testCase
+				}
+			}
+			switch {
+			case len(shardFailures) > 0 && shardPassed == nil:
+				failures = append(failures, shardFailures...)
+			case len(shardFailures) > 0:
+				flakes = append(flakes, shardFailures...)
+			}
+			if passed == nil {
+				passed = shardPassed
+			}
+		}
+		switch {
+		case len(failures) > 0:
+			merged = append(merged, failures...)
+		case len(flakes) > 0:
+			merged = append(merged, flakes...)
+			merged = append(merged, passed)
+		case passed != nil:
+			merged = append(merged, passed)
+		default:
+			// skipped in every shard
+			merged = append(merged, shards[0][0])
+		}
+	}
+	return merged
+}
+
+func readJUnitSuite(filename string) (*junitapi.JUnitTestSuite, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	suite := &junitapi.JUnitTestSuite{}
+	if err := xml.Unmarshal(data, suite); err != nil {
+		return nil, fmt.Errorf("unable to read JUnit results from %s: %v", filename, err)
+	}
+	return suite, nil
+}
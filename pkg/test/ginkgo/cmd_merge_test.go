@@ -0,0 +1,99 @@
+package ginkgo
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+func TestMergeOptions_Run(t *testing.T) {
+	dir := t.TempDir()
+	from := time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)
+	out := &bytes.Buffer{}
+
+	failure := &junitapi.FailureOutput{Output: "failed"}
+	var shards []string
+	for i, results := range [][]*junitapi.JUnitTestCase{
+		{
+			{Name: "passing", Duration: 10},
+			{Name: "[invariant] fails in one shard", FailureOutput: failure},
+			{Name: "[invariant] flakes in one shard"},
+			{Name: "[invariant] passes"},
+		},
+		{
+			{Name: "failing", FailureOutput: failure},
+			{Name: "[invariant] fails in one shard"},
+			{Name: "[invariant] flakes in one shard", FailureOutput: failure},
+			{Name: "[invariant] flakes in one shard"},
+			{Name: "[invariant] passes"},
+		},
+	} {
+		shard := filepath.Join(dir, "shard", string(rune('0'+i)))
+		if err := os.MkdirAll(shard, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := writeJUnitReport("junit_e2e", "openshift-tests", nil, shard, time.Duration(i+1)*time.Hour, out, results...); err != nil {
+			t.Fatal(err)
+		}
+		err := monitorserialization.EventsToFile(filepath.Join(shard, "e2e-events_20220401-100000.json"), monitorapi.Intervals{
+			{Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: "node/node1", Message: "reason/NotReady"}, From: from.Add(time.Duration(i) * time.Minute), To: from.Add(time.Hour)},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		shards = append(shards, shard)
+	}
+
+	opt := NewMergeOptions()
+	opt.Dirs = shards
+	opt.JUnitDir = filepath.Join(dir, "merged")
+	opt.Out, opt.ErrOut = out, out
+	if err := opt.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	junits, err := filepath.Glob(filepath.Join(opt.JUnitDir, "junit_e2e_*.xml"))
+	if err != nil || len(junits) != 1 {
+		t.Fatalf("expected one junit file, got %v: %v", junits, err)
+	}
+	suite, err := readJUnitSuite(junits[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suite.Name != "openshift-tests" || suite.NumTests != 6 || suite.NumFailed != 3 || suite.Duration != (2*time.Hour).Seconds() {
+		t.Errorf("unexpected merged suite %s: %d tests, %d failed, %fs", suite.Name, suite.NumTests, suite.NumFailed, suite.Duration)
+	}
+	results := map[string][]bool{}
+	for _, testCase := range suite.TestCases {
+		results[testCase.Name] = append(results[testCase.Name], testCase.FailureOutput == nil)
+	}
+	expected := map[string][]bool{
+		"passing":                         {true},
+		"failing":                         {false},
+		"[invariant] fails in one shard":  {false},
+		"[invariant] flakes in one shard": {false, true},
+		"[invariant] passes":              {true},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected the invariants of the shards to be merged into %v, got %v", expected, results)
+	}
+
+	events, err := monitorserialization.EventsFromFile(filepath.Join(opt.JUnitDir, "e2e-events_20220401-100000.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Errorf("expected the events of both shards, got %v", events)
+	}
+
+	if err := (&MergeOptions{Dirs: []string{dir}, JUnitDir: opt.JUnitDir}).Run(); err == nil {
+		t.Errorf("expected a directory without results to be rejected")
+	}
+}
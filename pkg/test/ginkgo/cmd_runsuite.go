@@ -19,13 +19,18 @@ import (
 
 	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
 
+	"github.com/openshift/origin/pkg/synthetictests"
 	"github.com/openshift/origin/pkg/synthetictests/allowedalerts"
+	"github.com/openshift/origin/pkg/synthetictests/allowedbackenddisruption"
+	"github.com/openshift/origin/pkg/synthetictests/allowedrepeatedevents"
 
 	"github.com/onsi/ginkgo/config"
 	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitor/backenddisruption"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
 	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
 	"github.com/openshift/origin/test/extended/util/disruption/controlplane"
+	"github.com/openshift/origin/test/extended/util/disruption/custom"
 	"github.com/openshift/origin/test/extended/util/disruption/frontends"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
@@ -63,6 +68,59 @@ type Options struct {
 
 	IncludeSuccessOutput bool
 
+	// JournalEvents writes monitor intervals to a journal in JUnitDir as they are recorded, so they survive the
+	// process being killed before the end of the run.
+	JournalEvents bool
+
+	// DisruptionBackendsFile is a YAML or JSON file of extra backends to monitor for disruption, in addition to the
+	// control plane and ingress backends.
+	DisruptionBackendsFile string
+	// DisruptionLatency is when the disruption backends record HighLatency intervals.
+	DisruptionLatency backenddisruption.LatencyConfig
+	// DisruptionBudgetFile is a YAML or JSON file of allowed disruption for backends and job types, see
+	// historicaldata.ReadOverridesFile.  Disruption above the allowance fails the availability tests.
+	DisruptionBudgetFile string
+	// HistoricalDataFile is a JSON file of historical alert and disruption percentiles written by
+	// compute-historical-data.  It is used by the invariants instead of the embedded data.
+	HistoricalDataFile string
+	// HistoricalDataMatchingFile is a YAML or JSON file of how the invariants match historical data to the job type,
+	// see historicaldata.ReadMatchingConfigFile.
+	HistoricalDataMatchingFile string
+	// AlertAllowlistFile is a YAML or JSON list of alerts the generic alert invariant allows, see
+	// allowedalerts.ReadAllowlistFile.  It is added to the embedded allowlist.
+	AlertAllowlistFile string
+	// RepeatedEventsAllowlistFiles are YAML or JSON allowlists of repeated events, see
+	// allowedrepeatedevents.ReadAllowlistFile.  They are merged into the embedded allowlist.
+	RepeatedEventsAllowlistFiles []string
+
+	// StatusAddress, if set, is the host:port to serve the live monitor intervals, disruption state, metrics and chart on
+	// while the suite runs.
+	StatusAddress string
+
+	// ShardIndex and ShardCount split the suite across ShardCount processes, this one running the tests of shard
+	// ShardIndex.  See shardTests.  Use merge-results to combine the results of the shards.
+	ShardIndex int
+	ShardCount int
+
+	// TestDurationsPath is a JSON file of test durations, or the JUnit results of an earlier run, see
+	// ReadTestDurations.  The longest tests are started first.  Without it, tests run in the order of the suite.
+	TestDurationsPath string
+
+	// ResumeFrom is the --junit-dir of an interrupted run of the suite.  Tests that passed or were skipped in it are
+	// not run again, and its results and events are reported with those of this run.
+	ResumeFrom string
+
+	// OutputJSONL is a file to write a JSON record to as each test finishes, followed by the results of the invariants
+	// and a summary of the suite.
+	OutputJSONL string
+
+	// QuarantineFile is a YAML list of known broken tests, see ReadQuarantine.  It is added to the embedded
+	// quarantine.  Failures of quarantined tests are reported as skips and do not fail the suite until they expire.
+	QuarantineFile string
+
+	// WorkerPool runs the tests in long lived worker processes instead of a process per test.
+	WorkerPool bool
+
 	CommandEnv []string
 
 	DryRun        bool
@@ -74,22 +132,29 @@ type Options struct {
 
 func NewOptions() *Options {
 	return &Options{
-		RunDataWriters: []RunDataWriter{
-			// these produce the various intervals.  Different intervals focused on inspecting different problem spaces.
-			AdaptEventDataWriter(intervalcreation.NewSpyglassEventIntervalRenderer("everything", intervalcreation.BelongsInEverything)),
-			AdaptEventDataWriter(intervalcreation.NewSpyglassEventIntervalRenderer("spyglass", intervalcreation.BelongsInSpyglass)),
-			// TODO add visualization of individual apiserver containers and their readiness on this page
-			AdaptEventDataWriter(intervalcreation.NewSpyglassEventIntervalRenderer("kube-apiserver", intervalcreation.BelongsInKubeAPIServer)),
-			AdaptEventDataWriter(intervalcreation.NewSpyglassEventIntervalRenderer("operators", intervalcreation.BelongsInOperatorRollout)),
-			AdaptEventDataWriter(intervalcreation.NewPodEventIntervalRenderer()),
-
-			RunDataWriterFunc(monitor.WriteEventsForJobRun),
+		RunDataWriters: append(eventIntervalRunDataWriters(),
 			RunDataWriterFunc(monitor.WriteTrackedResourcesForJobRun),
 			RunDataWriterFunc(monitor.WriteBackendDisruptionForJobRun),
 			RunDataWriterFunc(allowedalerts.WriteAlertDataForJobRun),
-		},
-		Out:    os.Stdout,
-		ErrOut: os.Stderr,
+		),
+		DisruptionLatency: backenddisruption.DefaultLatencyConfig(),
+		Out:               os.Stdout,
+		ErrOut:            os.Stderr,
+	}
+}
+
+// eventIntervalRunDataWriters write the events and the interval pages, which only need the events.
+func eventIntervalRunDataWriters() []RunDataWriter {
+	return []RunDataWriter{
+		// these produce the various intervals.  Different intervals focused on inspecting different problem spaces.
+		AdaptEventDataWriter(intervalcreation.NewSpyglassEventIntervalRenderer("everything", intervalcreation.BelongsInEverything)),
+		AdaptEventDataWriter(intervalcreation.NewSpyglassEventIntervalRenderer("spyglass", intervalcreation.BelongsInSpyglass)),
+		// TODO add visualization of individual apiserver containers and their readiness on this page
+		AdaptEventDataWriter(intervalcreation.NewSpyglassEventIntervalRenderer("kube-apiserver", intervalcreation.BelongsInKubeAPIServer)),
+		AdaptEventDataWriter(intervalcreation.NewSpyglassEventIntervalRenderer("operators", intervalcreation.BelongsInOperatorRollout)),
+		AdaptEventDataWriter(intervalcreation.NewPodEventIntervalRenderer()),
+
+		RunDataWriterFunc(monitor.WriteEventsForJobRun),
 	}
 }
 
@@ -150,6 +215,55 @@ func max(a, b int) int {
 }
 
 func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
+	if err := validateShard(opt.ShardIndex, opt.ShardCount); err != nil {
+		return err
+	}
+	var durations TestDurations
+	if len(opt.TestDurationsPath) > 0 {
+		read, err := ReadTestDurations(opt.TestDurationsPath)
+		if err != nil {
+			return fmt.Errorf("could not read --test-durations: %v", err)
+		}
+		durations = read
+	}
+	quarantine := DefaultQuarantine()
+	if len(opt.QuarantineFile) > 0 {
+		read, err := ReadQuarantine(opt.QuarantineFile)
+		if err != nil {
+			return fmt.Errorf("could not read --quarantine-file: %v", err)
+		}
+		quarantine = quarantine.Merge(read)
+	}
+	if len(opt.DisruptionBackendsFile) > 0 {
+		if err := synthetictests.SetConfiguredDisruptionBackendsFromFile(opt.DisruptionBackendsFile); err != nil {
+			return err
+		}
+	}
+	if len(opt.DisruptionBudgetFile) > 0 {
+		if err := allowedbackenddisruption.SetOverridesFromFile(opt.DisruptionBudgetFile); err != nil {
+			return err
+		}
+	}
+	if len(opt.HistoricalDataFile) > 0 {
+		if err := setHistoricalDataFromFile(opt.HistoricalDataFile); err != nil {
+			return fmt.Errorf("could not read --historical-data: %v", err)
+		}
+	}
+	if len(opt.HistoricalDataMatchingFile) > 0 {
+		if err := setHistoricalDataMatchingFromFile(opt.HistoricalDataMatchingFile); err != nil {
+			return fmt.Errorf("could not read --historical-data-matching: %v", err)
+		}
+	}
+	if len(opt.AlertAllowlistFile) > 0 {
+		if err := allowedalerts.SetAllowlistFromFile(opt.AlertAllowlistFile); err != nil {
+			return err
+		}
+	}
+	if len(opt.RepeatedEventsAllowlistFiles) > 0 {
+		if err := allowedrepeatedevents.SetAllowlistsFromFiles(opt.RepeatedEventsAllowlistFiles); err != nil {
+			return err
+		}
+	}
 	if len(opt.Regex) > 0 {
 		if err := filterWithRegex(suite, opt.Regex); err != nil {
 			return err
@@ -171,6 +285,11 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 	if err != nil {
 		return err
 	}
+	if suite.patterns != nil {
+		for _, pattern := range suite.patterns.unresolved(tests) {
+			fmt.Fprintf(opt.ErrOut, "warning: %s of suite %q does not match any test\n", pattern, suite.Name)
+		}
+	}
 
 	// This ensures that tests in the identified paths do not run in parallel, because
 	// the test suite reuses shared resources without considering whether another test
@@ -192,6 +311,30 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 	if len(tests) == 0 {
 		return fmt.Errorf("suite %q does not contain any tests", suite.Name)
 	}
+	if opt.ShardCount > 1 {
+		total := len(tests)
+		tests = shardTests(tests, opt.ShardIndex, opt.ShardCount)
+		fmt.Fprintf(opt.ErrOut, "Running shard %d of %d: %d of %d tests\n", opt.ShardIndex, opt.ShardCount, len(tests), total)
+		if len(tests) == 0 {
+			return fmt.Errorf("shard %d of suite %q does not contain any tests", opt.ShardIndex, suite.Name)
+		}
+	}
+	var resumed *resumedRun
+	if len(opt.ResumeFrom) > 0 {
+		if filepath.Clean(opt.ResumeFrom) == filepath.Clean(opt.JUnitDir) {
+			return fmt.Errorf("--resume-from must be a different directory than --junit-dir")
+		}
+		resumed, err = readResumedRun(opt.ResumeFrom)
+		if err != nil {
+			return fmt.Errorf("could not resume from %s: %v", opt.ResumeFrom, err)
+		}
+		total := len(tests)
+		tests = resumed.filter(tests)
+		fmt.Fprintf(opt.ErrOut, "Resuming from %s: %d of %d tests already passed or were skipped\n", opt.ResumeFrom, total-len(tests), total)
+	}
+	for _, test := range quarantine.apply(tests, time.Now()) {
+		fmt.Fprintf(opt.ErrOut, "warning: The quarantine of %q expired on %s, its failures fail the suite again: %s\n", test.name, test.quarantine.Expires, test.quarantine.Reason)
+	}
 
 	count := opt.Count
 	if count == 0 {
@@ -205,7 +348,7 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 
 	if opt.PrintCommands {
 		status := newTestStatus(opt.Out, true, len(tests), time.Minute, &monitor.Monitor{}, monitor.NewNoOpMonitor(), opt.AsEnv())
-		newParallelTestQueue().Execute(context.Background(), tests, 1, status.OutputCommand)
+		newParallelTestQueue(durations).Execute(context.Background(), tests, 1, status.OutputCommand)
 		return nil
 	}
 	if opt.DryRun {
@@ -215,6 +358,9 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 		return nil
 	}
 
+	if opt.JournalEvents && len(opt.JUnitDir) == 0 {
+		return fmt.Errorf("--journal-events requires --junit-dir")
+	}
 	if len(opt.JUnitDir) > 0 {
 		if _, err := os.Stat(opt.JUnitDir); err != nil {
 			if !os.IsNotExist(err) {
@@ -225,6 +371,15 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 			}
 		}
 	}
+	var results *resultStream
+	if len(opt.OutputJSONL) > 0 {
+		f, err := os.Create(opt.OutputJSONL)
+		if err != nil {
+			return fmt.Errorf("could not create --output-jsonl: %v", err)
+		}
+		defer f.Close()
+		results = newResultStream(f, opt.ErrOut)
+	}
 
 	parallelism := opt.Parallelism
 	if parallelism == 0 {
@@ -263,15 +418,32 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 	if err != nil {
 		return err
 	}
-	m, err := monitor.Start(ctx, restConfig,
-		[]monitor.StartEventIntervalRecorderFunc{
-			controlplane.StartAllAPIMonitoring,
-			frontends.StartAllIngressMonitoring,
-		},
-	)
+	var journalFilename string
+	if opt.JournalEvents {
+		journalFilename = filepath.Join(opt.JUnitDir, monitor.JournalFilename)
+	}
+	eventIntervalRecorders := []monitor.StartEventIntervalRecorderFunc{
+		controlplane.StartAllAPIMonitoringWithLatency(opt.DisruptionLatency),
+		frontends.StartAllIngressMonitoringWithLatency(opt.DisruptionLatency),
+	}
+	if len(opt.DisruptionBackendsFile) > 0 {
+		startConfiguredMonitoring, err := custom.StartMonitoringFromFile(opt.DisruptionBackendsFile, opt.DisruptionLatency)
+		if err != nil {
+			return err
+		}
+		eventIntervalRecorders = append(eventIntervalRecorders, startConfiguredMonitoring)
+	}
+	m, err := monitor.StartWithJournal(ctx, restConfig, eventIntervalRecorders, journalFilename)
 	if err != nil {
 		return err
 	}
+	if len(opt.StatusAddress) > 0 {
+		address, err := monitor.StartStatusServer(ctx, opt.StatusAddress, m)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(opt.ErrOut, "Serving monitor status on http://%s\n", address)
+	}
 
 	pc, err := SetupNewPodCollector(ctx)
 	if err != nil {
@@ -318,7 +490,28 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 	}
 	expectedTestCount += len(openshiftTests) + len(kubeTests)
 
+	// the queues below run one after the other
+	predicted := durations.predict(early, parallelism) +
+		durations.predict(kubeTests, parallelism) +
+		durations.predict(storageTests, max(1, parallelism/2)) +
+		durations.predict(openshiftTests, parallelism) +
+		durations.predict(late, parallelism)
+
+	var workers *workerPool
+	if opt.WorkerPool {
+		workers = newWorkerPool(opt.AsEnv())
+		defer workers.Close()
+	}
+
 	status := newTestStatus(opt.Out, includeSuccess, expectedTestCount, timeout, m, m, opt.AsEnv())
+	status.results = results
+	status.workers = workers
+	retryPolicy := suite.retryPolicy()
+	retrier := newRetrier(retryPolicy)
+	run := status.Run
+	if retryPolicy.Immediate {
+		run = retrier.immediately(status.Run)
+	}
 	testCtx := ctx
 	if opt.FailFast {
 		var cancelFn context.CancelFunc
@@ -333,8 +526,8 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 	tests = nil
 
 	// run our Early tests
-	q := newParallelTestQueue()
-	q.Execute(testCtx, early, parallelism, status.Run)
+	q := newParallelTestQueue(durations)
+	q.Execute(testCtx, early, parallelism, run)
 	tests = append(tests, early...)
 
 	// TODO: will move to the monitor
@@ -344,16 +537,16 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 	// we loop indefinitely.
 	for i := 0; (i < 1 || count == -1) && testCtx.Err() == nil; i++ {
 		kubeTestsCopy := copyTests(kubeTests)
-		q.Execute(testCtx, kubeTestsCopy, parallelism, status.Run)
+		q.Execute(testCtx, kubeTestsCopy, parallelism, run)
 		tests = append(tests, kubeTestsCopy...)
 
 		// I thought about randomizing the order of the kube, storage, and openshift tests, but storage dominates our e2e runs, so it doesn't help much.
 		storageTestsCopy := copyTests(storageTests)
-		q.Execute(testCtx, storageTestsCopy, max(1, parallelism/2), status.Run) // storage tests only run at half the parallelism, so we can avoid cloud provider quota problems.
+		q.Execute(testCtx, storageTestsCopy, max(1, parallelism/2), run) // storage tests only run at half the parallelism, so we can avoid cloud provider quota problems.
 		tests = append(tests, storageTestsCopy...)
 
 		openshiftTestsCopy := copyTests(openshiftTests)
-		q.Execute(testCtx, openshiftTestsCopy, parallelism, status.Run)
+		q.Execute(testCtx, openshiftTestsCopy, parallelism, run)
 		tests = append(tests, openshiftTestsCopy...)
 	}
 
@@ -361,8 +554,9 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 	pc.SetEvents([]string{postUpgradeEvent})
 
 	// run Late test suits after everything else
-	q.Execute(testCtx, late, parallelism, status.Run)
+	q.Execute(testCtx, late, parallelism, run)
 	tests = append(tests, late...)
+	tests = append(tests, retrier.retries...)
 
 	// TODO: will move to the monitor
 	if len(opt.JUnitDir) > 0 {
@@ -389,8 +583,22 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 	if duration > time.Minute {
 		duration = duration.Round(time.Second)
 	}
+	if predicted > 0 && count != -1 {
+		fmt.Fprintf(opt.Out, "Predicted %s from the durations of earlier runs, took %s\n\n", predicted.Round(time.Second), duration)
+	}
 
-	pass, fail, skip, failing := summarizeTests(tests)
+	// the outcome of the retries is reported below
+	pass, fail, skip, failing := summarizeTests(firstAttempts(tests))
+	quarantined, failing := splitTests(failing, func(t *testCase) bool { return t.quarantined() })
+	if len(quarantined) > 0 {
+		fail -= len(quarantined)
+		skip += len(quarantined)
+		var lines []string
+		for _, test := range sortedTests(quarantined) {
+			lines = append(lines, fmt.Sprintf("%s (%s)", test.name, test.quarantine))
+		}
+		fmt.Fprintf(opt.Out, "Quarantined tests failed:\n\n%s\n\n", strings.Join(lines, "\n"))
+	}
 
 	// monitor the cluster while the tests are running and report any detected anomalies
 	var syntheticTestResults []*junitapi.JUnitTestCase
@@ -429,79 +637,63 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 	sort.Sort(events)
 
 	events.Clamp(start, end)
+	if resumed != nil {
+		// the earlier run is already clamped to its own start and end
+		events = append(resumed.intervals(start), events...)
+		sort.Sort(events)
+	}
 
 	if len(opt.JUnitDir) > 0 {
 		if err := opt.WriteRunDataToArtifactsDir(opt.JUnitDir, m, events, timeSuffix); err != nil {
 			fmt.Fprintf(opt.ErrOut, "error: Failed to write run-data: %v\n", err)
 		}
+		if err := writeJobTypeForJobRun(ctx, opt.JUnitDir, restConfig, timeSuffix); err != nil {
+			fmt.Fprintf(opt.ErrOut, "warning: Unable to record the job type: %v\n", err)
+		}
+	}
+
+	// the invariants and the report cover both runs
+	reportDuration := duration
+	var resumedResults []*junitapi.JUnitTestCase
+	if resumed != nil {
+		reportDuration += resumed.duration()
+		resumedResults = resumed.testCases
 	}
 
 	if len(events) > 0 {
 		var buf *bytes.Buffer
-		syntheticTestResults, buf, _ = createSyntheticTestsFromMonitor(events, duration)
-		testCases := syntheticEventTests.JUnitsForEvents(events, duration, restConfig, suite.Name)
-		syntheticTestResults = append(syntheticTestResults, testCases...)
-
-		if len(syntheticTestResults) > 0 {
-			// mark any failures by name
-			failing, flaky := sets.NewString(), sets.NewString()
-			for _, test := range syntheticTestResults {
-				if test.FailureOutput != nil {
-					failing.Insert(test.Name)
-				}
-			}
-			// if a test has both a pass and a failure, flag it
-			// as a flake
-			for _, test := range syntheticTestResults {
-				if test.FailureOutput == nil {
-					if failing.Has(test.Name) {
-						flaky.Insert(test.Name)
-					}
-				}
-			}
-			failing = failing.Difference(flaky)
-			if failing.Len() > 0 {
-				fmt.Fprintf(buf, "Failing invariants:\n\n%s\n\n", strings.Join(failing.List(), "\n"))
-				syntheticFailure = true
-			}
-			if flaky.Len() > 0 {
-				fmt.Fprintf(buf, "Flaky invariants:\n\n%s\n\n", strings.Join(flaky.List(), "\n"))
-			}
-		}
-
+		syntheticTestResults, buf, syntheticFailure = evaluateSyntheticTests(events, reportDuration, restConfig, syntheticEventTests, suite.Name)
 		opt.Out.Write(buf.Bytes())
+		results.writeInvariants(syntheticTestResults)
 	}
 
 	// attempt to retry failures to do flake detection
-	if fail > 0 && fail <= suite.MaximumAllowedFlakes {
-		var retries []*testCase
-		for _, test := range failing {
-			retry := test.Retry()
-			retries = append(retries, retry)
-			tests = append(tests, retry)
-			if len(retries) > suite.MaximumAllowedFlakes {
-				break
-			}
-		}
-
-		q := newParallelTestQueue()
-		status := newTestStatus(ioutil.Discard, opt.IncludeSuccessOutput, len(retries), timeout, m, m, opt.AsEnv())
-		q.Execute(testCtx, retries, parallelism, status.Run)
-		var flaky []string
-		var repeatFailures []*testCase
-		for _, test := range retries {
-			if test.success {
-				flaky = append(flaky, test.name)
-			} else {
-				repeatFailures = append(repeatFailures, test)
-			}
+	if !retryPolicy.Immediate && fail > 0 && fail <= retryPolicy.Budget {
+		q := newParallelTestQueue(durations)
+		status := newTestStatus(ioutil.Discard, opt.IncludeSuccessOutput, fail, timeout, m, m, opt.AsEnv())
+		status.results = results
+		status.workers = workers
+		for retries := retrier.retry(failing); len(retries) > 0 && testCtx.Err() == nil; {
+			q.Execute(testCtx, retries, parallelism, status.Run)
+			tests = append(tests, retries...)
+			_, _, _, repeatFailures := summarizeTests(retries)
+			retries = retrier.retry(repeatFailures)
 		}
-		if len(flaky) > 0 {
-			failing = repeatFailures
-			sort.Strings(flaky)
-			fmt.Fprintf(opt.Out, "Flaky tests:\n\n%s\n\n", strings.Join(flaky, "\n"))
+	}
+	var flaky []string
+	failing = nil
+	for _, test := range lastAttempts(tests) {
+		switch {
+		case isFlake(test):
+			flaky = append(flaky, test.name)
+		case test.failed && !test.quarantined():
+			failing = append(failing, test)
 		}
 	}
+	if len(flaky) > 0 {
+		sort.Strings(flaky)
+		fmt.Fprintf(opt.Out, "Flaky tests:\n\n%s\n\n", strings.Join(flaky, "\n"))
+	}
 
 	// report the outcome of the test
 	if len(failing) > 0 {
@@ -510,11 +702,19 @@ func (opt *Options) Run(suite *TestSuite, junitSuiteName string) error {
 	}
 
 	if len(opt.JUnitDir) > 0 {
-		if err := writeJUnitReport("junit_e2e", junitSuiteName, tests, opt.JUnitDir, duration, opt.ErrOut, syntheticTestResults...); err != nil {
+		correlateIntervals(tests, events, opt.JUnitDir, timeSuffix, opt.ErrOut)
+		if err := writeJUnitReport("junit_e2e", junitSuiteName, tests, opt.JUnitDir, reportDuration, opt.ErrOut, append(resumedResults, syntheticTestResults...)...); err != nil {
 			fmt.Fprintf(opt.Out, "error: Unable to write e2e JUnit results: %v", err)
 		}
 	}
 
+	err = opt.outcome(ctx, suite, pass, fail, skip, failing, syntheticFailure, duration)
+	results.writeSummary(junitSuiteName, err, pass, fail, skip, reportDuration)
+	return err
+}
+
+// outcome reports whether the suite passed.
+func (opt *Options) outcome(ctx context.Context, suite *TestSuite, pass, fail, skip int, failing []*testCase, syntheticFailure bool, duration time.Duration) error {
 	if fail > 0 {
 		if len(failing) > 0 || suite.MaximumAllowedFlakes == 0 {
 			return fmt.Errorf("%d fail, %d pass, %d skip (%s)", fail, pass, skip, duration)
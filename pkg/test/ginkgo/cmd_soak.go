@@ -0,0 +1,59 @@
+package ginkgo
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"k8s.io/client-go/rest"
+)
+
+// SoakWindowWriter writes the run data of each window of a soaking monitor and evaluates the invariants against it,
+// the same as at the end of a suite.
+type SoakWindowWriter struct {
+	// SuiteName is passed to the invariants and JUnitSuiteName names the JUnit suite of each window.
+	SuiteName      string
+	JUnitSuiteName string
+
+	SyntheticEventTests JUnitsForEvents
+	RunDataWriters      []RunDataWriter
+
+	// RestConfig is the cluster the monitor watches.  The invariants use it the same as at the end of a suite.
+	RestConfig *rest.Config
+
+	Out, ErrOut io.Writer
+}
+
+func NewSoakWindowWriter(suiteName string, syntheticEventTests JUnitsForEvents, restConfig *rest.Config) *SoakWindowWriter {
+	return &SoakWindowWriter{
+		SuiteName:           suiteName,
+		RestConfig:          restConfig,
+		JUnitSuiteName:      "openshift-tests-monitor",
+		SyntheticEventTests: syntheticEventTests,
+		RunDataWriters:      NewOptions().RunDataWriters,
+		Out:                 os.Stdout,
+		ErrOut:              os.Stderr,
+	}
+}
+
+// WriteWindow is a monitor.WindowWriterFunc.  Invariant failures are reported in the JUnit of the window rather than
+// returned, so the soak continues.
+func (w *SoakWindowWriter) WriteWindow(artifactDir string, m *monitor.Monitor, events monitorapi.Intervals, from, to time.Time) error {
+	duration := to.Sub(from).Round(time.Second)
+	timeSuffix := fmt.Sprintf("_%s", from.UTC().Format("20060102-150405"))
+
+	if err := writeRunDataToArtifactsDir(w.RunDataWriters, artifactDir, m, events, timeSuffix); err != nil {
+		fmt.Fprintf(w.ErrOut, "error: Failed to write run-data: %v\n", err)
+	}
+
+	syntheticTestResults, buf, syntheticFailure := evaluateSyntheticTests(events, duration, w.RestConfig, w.SyntheticEventTests, w.SuiteName)
+	w.Out.Write(buf.Bytes())
+	if syntheticFailure {
+		fmt.Fprintf(w.Out, "Window from %s to %s violated an invariant\n", from.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339))
+	}
+
+	return writeJUnitReport("junit_e2e", w.JUnitSuiteName, nil, artifactDir, duration, w.ErrOut, syntheticTestResults...)
+}
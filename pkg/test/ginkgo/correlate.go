@@ -0,0 +1,92 @@
+package ginkgo
+
+import (
+	"fmt"
+	"hash/fnv"
+	"html"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/origin/pkg/monitor/intervalcreation"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+// testNamespaceRegexp matches the namespaces the e2e frameworks create for a test in its output.
+var testNamespaceRegexp = regexp.MustCompile(`\be2e-[a-z0-9]+(?:-[a-z0-9]+)*`)
+
+// correlateIntervals sets the systemErr of each failed or flaked test to the Error and Warning intervals that overlapped
+// it, so the JUnit report shows what else was wrong with the cluster at the time.  When artifactDir is set, a chart of
+// those intervals is written there for each test and linked.
+func correlateIntervals(tests []*testCase, events monitorapi.Intervals, artifactDir, timeSuffix string, errOut io.Writer) {
+	for _, test := range tests {
+		if !test.failed && !test.flake {
+			continue
+		}
+		if test.start.IsZero() || test.end.IsZero() {
+			continue
+		}
+		intervals := testIntervals(test, events)
+		if len(intervals) == 0 {
+			continue
+		}
+
+		var lines []string
+		lines = append(lines, fmt.Sprintf("%d Error and Warning intervals overlapped this test:", len(intervals)))
+		for _, interval := range intervals {
+			lines = append(lines, interval.String())
+		}
+
+		if len(artifactDir) > 0 {
+			filename := testIntervalsFilename(test.name, timeSuffix)
+			if err := writeTestIntervals(filepath.Join(artifactDir, filename), test, events, intervals); err != nil {
+				fmt.Fprintf(errOut, "error: Failed to write the intervals of %q: %v\n", test.name, err)
+			} else {
+				lines = append(lines, "", fmt.Sprintf("Chart of these intervals: %s", filename))
+			}
+		}
+		test.systemErr = strings.Join(lines, "\n")
+	}
+}
+
+// testIntervals returns the Error and Warning intervals that overlapped the run of test.  Other tests are left out, and
+// so are the intervals of e2e namespaces that do not appear in the output of test, which belong to other tests.
+func testIntervals(test *testCase, events monitorapi.Intervals) monitorapi.Intervals {
+	namespaces := sets.NewString(testNamespaceRegexp.FindAllString(string(test.out), -1)...)
+	return events.Cut(test.start, test.end).Filter(func(interval monitorapi.EventInterval) bool {
+		if interval.Level != monitorapi.Error && interval.Level != monitorapi.Warning {
+			return false
+		}
+		if _, ok := monitorapi.E2ETestFromLocator(interval.Locator); ok {
+			return false
+		}
+		if namespace := monitorapi.NamespaceFromLocator(interval.Locator); strings.HasPrefix(namespace, "e2e-") {
+			return namespaces.Has(namespace)
+		}
+		return true
+	})
+}
+
+// testIntervalsFilename is named by a hash of the test name, which is too long and has too many special characters
+// to be a filename.
+func testIntervalsFilename(name, timeSuffix string) string {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return fmt.Sprintf("e2e-test-intervals_%08x%s.html", h.Sum32(), timeSuffix)
+}
+
+func writeTestIntervals(path string, test *testCase, events, intervals monitorapi.Intervals) error {
+	locator := monitorapi.E2ETestLocator(test.name)
+	charted := append(events.Filter(func(interval monitorapi.EventInterval) bool {
+		return interval.Locator == locator
+	}).Cut(test.start, test.end), intervals...)
+	chart, err := intervalcreation.RenderE2EChart(html.EscapeString(test.name), charted)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, chart, 0644)
+}
@@ -0,0 +1,65 @@
+package ginkgo
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func Test_correlateIntervals(t *testing.T) {
+	start := time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)
+	interval := func(level monitorapi.EventLevel, locator, message string, from, to time.Duration) monitorapi.EventInterval {
+		return monitorapi.EventInterval{
+			Condition: monitorapi.Condition{Level: level, Locator: locator, Message: message},
+			From:      start.Add(from),
+			To:        start.Add(to),
+		}
+	}
+	events := monitorapi.Intervals{
+		interval(monitorapi.Error, "disruption/kube-api connection/new", "reason/DisruptionBegan", -time.Minute, time.Minute),
+		interval(monitorapi.Warning, "node/node1", "reason/NotReady", 2*time.Minute, 3*time.Minute),
+		interval(monitorapi.Info, "clusteroperator/dns", "condition/Progressing status/True", 0, time.Minute),
+		interval(monitorapi.Warning, "ns/e2e-test-deployment-abcde pod/web-1", "reason/Evicted", time.Minute, time.Minute),
+		interval(monitorapi.Warning, "ns/e2e-test-other-fghij pod/web-1", "reason/Evicted", time.Minute, time.Minute),
+		interval(monitorapi.Error, monitorapi.E2ETestLocator("other test"), "e2e test finished As \"Failed\"", 0, time.Minute),
+		interval(monitorapi.Error, "clusteroperator/dns", "condition/Degraded status/True", 10*time.Minute, 11*time.Minute),
+	}
+	failed := &testCase{
+		name:   "[sig-apps] deployment should roll out",
+		start:  start,
+		end:    start.Add(5 * time.Minute),
+		out:    []byte("STEP: Building a namespace api object, basename e2e-test-deployment\nCreated namespace e2e-test-deployment-abcde\n"),
+		failed: true,
+	}
+	passed := &testCase{name: "[sig-apps] deployment should scale", start: start, end: start.Add(5 * time.Minute), success: true}
+
+	dir := t.TempDir()
+	correlateIntervals([]*testCase{failed, passed}, events, dir, "_20220401-100000", &bytes.Buffer{})
+
+	if len(passed.systemErr) > 0 {
+		t.Errorf("expected no intervals for a passing test, got %s", passed.systemErr)
+	}
+	for _, expected := range []string{"reason/DisruptionBegan", "reason/NotReady", "ns/e2e-test-deployment-abcde pod/web-1"} {
+		if !strings.Contains(failed.systemErr, expected) {
+			t.Errorf("expected %q in the intervals of the failed test, got:\n%s", expected, failed.systemErr)
+		}
+	}
+	for _, unexpected := range []string{"status/True", "e2e-test-other-fghij", "other test"} {
+		if strings.Contains(failed.systemErr, unexpected) {
+			t.Errorf("expected no %q in the intervals of the failed test, got:\n%s", unexpected, failed.systemErr)
+		}
+	}
+
+	filename := testIntervalsFilename(failed.name, "_20220401-100000")
+	if !strings.Contains(failed.systemErr, filename) {
+		t.Errorf("expected a link to %s, got:\n%s", filename, failed.systemErr)
+	}
+	if _, err := os.Stat(filepath.Join(dir, filename)); err != nil {
+		t.Errorf("expected the chart of the failed test to be written: %v", err)
+	}
+}
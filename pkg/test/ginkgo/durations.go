@@ -0,0 +1,170 @@
+package ginkgo
+
+import (
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TestDurations are the expected durations of tests by name.  The queue uses them to start the longest tests first.
+type TestDurations map[string]time.Duration
+
+// testDuration is an entry of a test durations JSON file.
+type testDuration struct {
+	TestName        string
+	DurationSeconds float64
+}
+
+// ReadTestDurations reads the durations of tests from a JSON file of
+// [{"TestName": "...", "DurationSeconds": 12.5}], from a JUnit file, or from the JUnit files of an earlier --junit-dir.
+// Tests that ran more than once get their average duration, skipped tests are ignored.
+func ReadTestDurations(path string) (TestDurations, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() && !strings.HasSuffix(path, ".xml") {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		durations, err := testDurationsFromJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %s: %v", path, err)
+		}
+		return durations, nil
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files, err = filepath.Glob(filepath.Join(path, "junit*.xml"))
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no JUnit results were found in %s", path)
+		}
+	}
+	total := map[string]time.Duration{}
+	runs := map[string]int{}
+	for _, filename := range files {
+		suite, err := readJUnitSuite(filename)
+		if err != nil {
+			return nil, err
+		}
+		for _, testCase := range suite.TestCases {
+			if testCase.SkipMessage != nil {
+				continue
+			}
+			total[testCase.Name] += time.Duration(testCase.Duration * float64(time.Second))
+			runs[testCase.Name]++
+		}
+	}
+	durations := TestDurations{}
+	for name, duration := range total {
+		durations[name] = duration / time.Duration(runs[name])
+	}
+	return durations, nil
+}
+
+func testDurationsFromJSON(data []byte) (TestDurations, error) {
+	var entries []testDuration
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	durations := TestDurations{}
+	for _, entry := range entries {
+		durations[entry.TestName] = time.Duration(entry.DurationSeconds * float64(time.Second))
+	}
+	return durations, nil
+}
+
+// expected returns the expected duration of each test.  Tests without data are expected to take the median of the
+// tests that have it, so they are started neither first nor last.  Without any data every test is expected to take 0.
+func (d TestDurations) expected(tests []*testCase) []time.Duration {
+	var known []time.Duration
+	for _, test := range tests {
+		if duration, ok := d[test.name]; ok {
+			known = append(known, duration)
+		}
+	}
+	var fallback time.Duration
+	if len(known) > 0 {
+		sort.Slice(known, func(i, j int) bool { return known[i] < known[j] })
+		fallback = known[len(known)/2]
+	}
+
+	expected := make([]time.Duration, 0, len(tests))
+	for _, test := range tests {
+		duration, ok := d[test.name]
+		if !ok {
+			duration = fallback
+		}
+		expected = append(expected, duration)
+	}
+	return expected
+}
+
+// longestFirst returns a copy of tests ordered by expected duration, longest first.  Tests that are expected to take
+// the same time, including all tests when there is no data, keep their order.
+func (d TestDurations) longestFirst(tests []*testCase) []*testCase {
+	if len(d) == 0 {
+		return tests
+	}
+	expected := d.expected(tests)
+	indexes := make([]int, len(tests))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool { return expected[indexes[i]] > expected[indexes[j]] })
+	sorted := make([]*testCase, 0, len(tests))
+	for _, i := range indexes {
+		sorted = append(sorted, tests[i])
+	}
+	return sorted
+}
+
+// predict returns how long the queue is expected to take to run tests with parallelism, starting the longest tests
+// first and the [Serial] tests after all others.  Exclusions between tests are not taken into account.
+func (d TestDurations) predict(tests []*testCase, parallelism int) time.Duration {
+	if len(tests) == 0 || parallelism < 1 {
+		return 0
+	}
+	serial, parallel := splitTests(tests, func(t *testCase) bool { return strings.Contains(t.name, "[Serial]") })
+
+	workers := make(workerFinishTimes, parallelism)
+	for _, duration := range d.expected(d.longestFirst(parallel)) {
+		workers[0] += duration
+		heap.Fix(&workers, 0)
+	}
+	var predicted time.Duration
+	for _, finish := range workers {
+		if finish > predicted {
+			predicted = finish
+		}
+	}
+	for _, duration := range d.expected(serial) {
+		predicted += duration
+	}
+	return predicted
+}
+
+// workerFinishTimes is a min-heap of when each worker of the queue becomes free.
+type workerFinishTimes []time.Duration
+
+func (h workerFinishTimes) Len() int            { return len(h) }
+func (h workerFinishTimes) Less(i, j int) bool  { return h[i] < h[j] }
+func (h workerFinishTimes) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *workerFinishTimes) Push(x interface{}) { *h = append(*h, x.(time.Duration)) }
+func (h *workerFinishTimes) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,94 @@
+package ginkgo
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+func TestTestDurations_longestFirst(t *testing.T) {
+	durations := TestDurations{
+		"short":  time.Minute,
+		"medium": 5 * time.Minute,
+		"long":   40 * time.Minute,
+	}
+	var tests []*testCase
+	for _, name := range []string{"short", "unknown-1", "long", "medium", "unknown-2"} {
+		tests = append(tests, &testCase{name: name})
+	}
+
+	if got, want := testNames(durations.longestFirst(tests)), []string{"long", "unknown-1", "medium", "unknown-2", "short"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the longest first and unknown tests with the median, got %v", got)
+	}
+	if got, want := testNames(TestDurations{}.longestFirst(tests)), testNames(tests); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the order to be kept without data, got %v", got)
+	}
+
+	// two workers: long on one, everything else on the other, then the serial test
+	tests = append(tests, &testCase{name: "[Serial] serial"})
+	durations["[Serial] serial"] = 2 * time.Minute
+	if got, want := durations.predict(tests, 2), 42*time.Minute; got != want {
+		t.Errorf("expected a prediction of %s, got %s", want, got)
+	}
+}
+
+func TestReadTestDurations(t *testing.T) {
+	dir := t.TempDir()
+	for i, duration := range []float64{10, 30} {
+		data, err := xml.Marshal(&junitapi.JUnitTestSuite{
+			Name: "openshift-tests",
+			TestCases: []*junitapi.JUnitTestCase{
+				{Name: "test", Duration: duration},
+				{Name: "skipped", SkipMessage: &junitapi.SkipMessage{}},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("junit_e2e_%d.xml", i)), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	durations, err := ReadTestDurations(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(durations, TestDurations{"test": 20 * time.Second}) {
+		t.Errorf("expected the average of the runs without skips, got %v", durations)
+	}
+
+	jsonFile := filepath.Join(dir, "durations.json")
+	if err := ioutil.WriteFile(jsonFile, []byte(`[{"TestName": "test", "DurationSeconds": 1.5}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	durations, err = ReadTestDurations(jsonFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(durations, TestDurations{"test": 1500 * time.Millisecond}) {
+		t.Errorf("unexpected durations %v", durations)
+	}
+
+	// the durations read from a file decide the order and the prediction
+	if err := ioutil.WriteFile(jsonFile, []byte(`[{"TestName": "short", "DurationSeconds": 60}, {"TestName": "long", "DurationSeconds": 600}, {"TestName": "[Serial] serial", "DurationSeconds": 30}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	durations, err = ReadTestDurations(jsonFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []*testCase{{name: "short"}, {name: "unknown"}, {name: "[Serial] serial"}, {name: "long"}}
+	if got, want := testNames(durations.longestFirst(tests)), []string{"long", "short", "unknown", "[Serial] serial"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the longest read test first, got %v", got)
+	}
+	// among the parallel tests unknown gets the 600s of long, so each takes a worker and short follows, then serial
+	if got, want := durations.predict(tests, 2), 690*time.Second; got != want {
+		t.Errorf("expected a prediction of %s, got %s", want, got)
+	}
+}
@@ -38,15 +38,32 @@ func writeJUnitReport(filePrefix, name string, tests []*testCase, dir string, du
 					Message: lastLinesUntil(string(test.out), 100, "skip ["),
 				},
 			})
+		case test.failed && test.quarantined():
+			s.NumTests++
+			s.NumSkipped++
+			s.TestCases = append(s.TestCases, &junitapi.JUnitTestCase{
+				Name:      test.name,
+				SystemOut: string(test.out),
+				SystemErr: test.systemErr,
+				Duration:  test.duration.Seconds(),
+				SkipMessage: &junitapi.SkipMessage{
+					Message: fmt.Sprintf("failed but %s", test.quarantine),
+				},
+			})
 		case test.failed:
+			output := lastLinesUntil(string(test.out), 100, "fail [")
+			if test.quarantine != nil {
+				output = fmt.Sprintf("The quarantine of this test expired on %s: %s\n\n%s", test.quarantine.Expires, test.quarantine.Reason, output)
+			}
 			s.NumTests++
 			s.NumFailed++
 			s.TestCases = append(s.TestCases, &junitapi.JUnitTestCase{
 				Name:      test.name,
 				SystemOut: string(test.out),
+				SystemErr: test.systemErr,
 				Duration:  test.duration.Seconds(),
 				FailureOutput: &junitapi.FailureOutput{
-					Output: lastLinesUntil(string(test.out), 100, "fail ["),
+					Output: output,
 				},
 			})
 		case test.success:
@@ -56,6 +73,7 @@ func writeJUnitReport(filePrefix, name string, tests []*testCase, dir string, du
 				s.TestCases = append(s.TestCases, &junitapi.JUnitTestCase{
 					Name:      test.name,
 					SystemOut: string(test.out),
+					SystemErr: test.systemErr,
 					Duration:  test.duration.Seconds(),
 					FailureOutput: &junitapi.FailureOutput{
 						Output: lastLinesUntil(string(test.out), 100, "flake:"),
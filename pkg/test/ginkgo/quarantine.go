@@ -0,0 +1,119 @@
+package ginkgo
+
+import (
+	_ "embed"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+//go:embed quarantine.yaml
+var quarantineYAML []byte
+
+// quarantineDateFormat is the format of the expiry date of a quarantine.
+const quarantineDateFormat = "2006-01-02"
+
+// QuarantinedTest is a known broken test.  Until the quarantine expires the test still runs, but its failures are
+// reported as skips and do not fail the suite.
+type QuarantinedTest struct {
+	// Name is the full name of the test.
+	Name string `json:"name"`
+	// Reason links to the bug tracking the fix.
+	Reason string `json:"reason"`
+	// Expires is the date, as 2006-01-02, from which failures of the test fail the suite again.
+	Expires string `json:"expires"`
+
+	expires time.Time
+}
+
+// Expired is true when the quarantine no longer applies at now.
+func (q *QuarantinedTest) Expired(now time.Time) bool {
+	return !now.Before(q.expires)
+}
+
+func (q *QuarantinedTest) String() string {
+	return fmt.Sprintf("quarantined until %s: %s", q.Expires, q.Reason)
+}
+
+// Quarantine are the quarantined tests by name.
+type Quarantine map[string]*QuarantinedTest
+
+// DefaultQuarantine returns the embedded quarantine.
+func DefaultQuarantine() Quarantine {
+	quarantine, err := quarantineFromYAML(quarantineYAML)
+	if err != nil {
+		panic(err)
+	}
+	return quarantine
+}
+
+// ReadQuarantine reads a YAML list of quarantined tests from path.
+func ReadQuarantine(path string) (Quarantine, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	quarantine, err := quarantineFromYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %v", path, err)
+	}
+	return quarantine, nil
+}
+
+func quarantineFromYAML(data []byte) (Quarantine, error) {
+	var entries []*QuarantinedTest
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	quarantine := Quarantine{}
+	for _, entry := range entries {
+		if len(entry.Name) == 0 {
+			return nil, fmt.Errorf("a quarantined test must have a name")
+		}
+		if len(entry.Reason) == 0 {
+			return nil, fmt.Errorf("the quarantine of %q must have a reason", entry.Name)
+		}
+		expires, err := time.Parse(quarantineDateFormat, entry.Expires)
+		if err != nil {
+			return nil, fmt.Errorf("the quarantine of %q must expire on a date like %s: %v", entry.Name, quarantineDateFormat, err)
+		}
+		entry.expires = expires
+		quarantine[entry.Name] = entry
+	}
+	return quarantine, nil
+}
+
+// Merge returns the quarantine of q overridden by that of other.
+func (q Quarantine) Merge(other Quarantine) Quarantine {
+	merged := Quarantine{}
+	for name, entry := range q {
+		merged[name] = entry
+	}
+	for name, entry := range other {
+		merged[name] = entry
+	}
+	return merged
+}
+
+// apply marks the quarantined tests and returns those whose quarantine expired at now.
+func (q Quarantine) apply(tests []*testCase, now time.Time) []*testCase {
+	var expired []*testCase
+	for _, test := range tests {
+		entry, ok := q[test.name]
+		if !ok {
+			continue
+		}
+		test.quarantine = entry
+		if entry.Expired(now) {
+			expired = append(expired, test)
+		}
+	}
+	return expired
+}
+
+// quarantined is true when the failures of the test are not reported as failures.
+func (t *testCase) quarantined() bool {
+	return t.quarantine != nil && !t.quarantine.Expired(t.start)
+}
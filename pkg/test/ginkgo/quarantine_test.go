@@ -0,0 +1,68 @@
+package ginkgo
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadQuarantine(t *testing.T) {
+	DefaultQuarantine()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "quarantine.yaml")
+	err := ioutil.WriteFile(path, []byte(`
+- name: "[sig-network] active"
+  reason: https://bugzilla.redhat.com/show_bug.cgi?id=1
+  expires: 2022-05-01
+- name: "[sig-network] expired"
+  reason: https://bugzilla.redhat.com/show_bug.cgi?id=2
+  expires: 2022-03-01
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quarantine, err := ReadQuarantine(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)
+	active := &testCase{name: "[sig-network] active", start: now, failed: true, out: []byte("fail [broken]")}
+	expired := &testCase{name: "[sig-network] expired", start: now, failed: true, out: []byte("fail [broken]")}
+	other := &testCase{name: "[sig-network] other", start: now, failed: true}
+	if got := quarantine.apply([]*testCase{active, expired, other}, now); len(got) != 1 || got[0] != expired {
+		t.Errorf("expected only the expired quarantine to be returned, got %v", got)
+	}
+	if !active.quarantined() || expired.quarantined() || other.quarantined() {
+		t.Errorf("expected only the active quarantine to apply")
+	}
+
+	if err := writeJUnitReport("junit_e2e", "openshift-tests", []*testCase{active, expired, other}, dir, time.Hour, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	junits, err := filepath.Glob(filepath.Join(dir, "junit_e2e_*.xml"))
+	if err != nil || len(junits) != 1 {
+		t.Fatalf("expected one junit file, got %v: %v", junits, err)
+	}
+	suite, err := readJUnitSuite(junits[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suite.NumFailed != 2 || suite.NumSkipped != 1 {
+		t.Errorf("expected the active quarantine to be reported as a skip, got %d failed and %d skipped", suite.NumFailed, suite.NumSkipped)
+	}
+	if skip := suite.TestCases[0].SkipMessage; skip == nil || !strings.Contains(skip.Message, "show_bug.cgi?id=1") {
+		t.Errorf("expected the skip to link the reason, got %#v", skip)
+	}
+	if failure := suite.TestCases[1].FailureOutput; failure == nil || !strings.Contains(failure.Output, "expired on 2022-03-01") {
+		t.Errorf("expected the failure to say the quarantine expired, got %#v", failure)
+	}
+
+	if _, err := quarantineFromYAML([]byte(`[{"name": "no expiry", "reason": "bug"}]`)); err == nil {
+		t.Errorf("expected a quarantine without an expiry to be rejected")
+	}
+}
@@ -11,11 +11,17 @@ import (
 // the `[Serial]` tag on their name or if another test with the
 // testExclusion field is currently running. Serial tests are
 // defered until all other tests are completed.
+// A test that declares a lock with `[Lock:name]` is not started
+// while another test holds that lock, or with `[Lock:name:count]`
+// while count tests hold it.
+// Tests with the longest expected durations are started first.
 type parallelByFileTestQueue struct {
-	cond   *sync.Cond
-	lock   sync.Mutex
-	queue  *ring.Ring
-	active map[string]struct{}
+	cond  *sync.Cond
+	lock  sync.Mutex
+	queue *ring.Ring
+	// held are the counts of the locks held by each running test, by lock name
+	held      map[string][]int
+	durations TestDurations
 }
 
 type nopLock struct{}
@@ -25,10 +31,11 @@ func (nopLock) Unlock() {}
 
 type TestFunc func(ctx context.Context, test *testCase)
 
-func newParallelTestQueue() *parallelByFileTestQueue {
+func newParallelTestQueue(durations TestDurations) *parallelByFileTestQueue {
 	return &parallelByFileTestQueue{
-		cond:   sync.NewCond(nopLock{}),
-		active: make(map[string]struct{}),
+		cond:      sync.NewCond(nopLock{}),
+		held:      make(map[string][]int),
+		durations: durations,
 	}
 }
 
@@ -43,12 +50,13 @@ func (q *parallelByFileTestQueue) pop() (*testCase, bool) {
 	}
 	for i := 0; i < l; i++ {
 		t := r.Value.(*testCase)
-		if _, ok := q.active[t.testExclusion]; ok {
+		locks := requiredLocks(t)
+		if !q.canAcquire(locks) {
 			r = r.Next()
 			continue
 		}
-		if len(t.testExclusion) > 0 {
-			q.active[t.testExclusion] = struct{}{}
+		for name, count := range locks {
+			q.held[name] = append(q.held[name], count)
 		}
 		if l == 1 {
 			q.queue = nil
@@ -64,15 +72,57 @@ func (q *parallelByFileTestQueue) pop() (*testCase, bool) {
 func (q *parallelByFileTestQueue) done(t *testCase) {
 	q.lock.Lock()
 	defer q.lock.Unlock()
-	delete(q.active, t.testExclusion)
+	for name, count := range requiredLocks(t) {
+		held := q.held[name]
+		for i := range held {
+			if held[i] == count {
+				held = append(held[:i], held[i+1:]...)
+				break
+			}
+		}
+		if len(held) == 0 {
+			delete(q.held, name)
+		} else {
+			q.held[name] = held
+		}
+	}
 	q.cond.Broadcast()
 }
 
+// canAcquire is true when every lock is held by fewer tests than both its count and the counts of the tests already
+// holding it, so a test that needs a lock to itself waits for the lock to be free and keeps others out while it runs.
+func (q *parallelByFileTestQueue) canAcquire(locks map[string]int) bool {
+	for name, count := range locks {
+		held := q.held[name]
+		if len(held) >= count {
+			return false
+		}
+		for _, heldCount := range held {
+			if len(held) >= heldCount {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// requiredLocks returns the locks a test declares, and its testExclusion as a lock held by one test at a time.
+func requiredLocks(t *testCase) map[string]int {
+	if len(t.testExclusion) == 0 {
+		return t.locks
+	}
+	locks := map[string]int{t.testExclusion: 1}
+	for name, count := range t.locks {
+		locks[name] = count
+	}
+	return locks
+}
+
 func (q *parallelByFileTestQueue) Close() {
 	q.lock.Lock()
 	defer q.lock.Unlock()
 	q.queue = nil
-	q.active = make(map[string]struct{})
+	q.held = make(map[string][]int)
 	q.cond.Broadcast()
 }
 
@@ -100,6 +150,7 @@ func (q *parallelByFileTestQueue) Execute(ctx context.Context, tests []*testCase
 	}
 
 	serial, parallel := splitTests(tests, func(t *testCase) bool { return strings.Contains(t.name, "[Serial]") })
+	parallel = q.durations.longestFirst(parallel)
 
 	r := ring.New(len(parallel))
 	for _, test := range parallel {
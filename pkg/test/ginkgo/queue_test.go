@@ -0,0 +1,101 @@
+package ginkgo
+
+import (
+	"container/ring"
+	"reflect"
+	"testing"
+)
+
+func Test_parseLocks(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    map[string]int
+		wantErr bool
+	}{
+		{name: "[sig-network] no locks"},
+		{name: "[sig-network][Lock:ingress-controller] exclusive", want: map[string]int{"ingress-controller": 1}},
+		{name: "[sig-mco][Lock:machineconfigpool:3][Lock:ingress-controller] shared", want: map[string]int{"machineconfigpool": 3, "ingress-controller": 1}},
+		{name: "[sig-mco][Lock:machineconfigpool:0] no holders", wantErr: true},
+		{name: "[sig-mco][Lock:machineconfigpool:many] not a count", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLocks(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseLocks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseLocks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parallelByFileTestQueue_locks(t *testing.T) {
+	exclusive := func(name string) *testCase { return &testCase{name: name, locks: map[string]int{"pool": 1}} }
+	shared := func(name string) *testCase { return &testCase{name: name, locks: map[string]int{"pool": 2}} }
+	tests := []*testCase{
+		shared("shared-1"),
+		shared("shared-2"),
+		shared("shared-3"),
+		exclusive("exclusive"),
+		{name: "unlocked"},
+		{name: "excluded-1", testExclusion: "disruption.go"},
+		{name: "excluded-2", testExclusion: "disruption.go"},
+	}
+	q := newParallelTestQueue(nil)
+	r := ring.New(len(tests))
+	for _, test := range tests {
+		r.Value = test
+		r = r.Next()
+	}
+	q.queue = r
+
+	var running []*testCase
+	popAll := func() []string {
+		var names []string
+		for {
+			test, ok := q.pop()
+			if !ok || test == nil {
+				return names
+			}
+			running = append(running, test)
+			names = append(names, test.name)
+		}
+	}
+	doneAll := func() {
+		for _, test := range running {
+			q.done(test)
+		}
+		running = nil
+	}
+
+	started := 0
+	for round := 0; started < len(tests); round++ {
+		if round == len(tests) {
+			t.Fatalf("only %d of %d tests were started", started, len(tests))
+		}
+		names := popAll()
+		if len(names) == 0 {
+			t.Fatalf("no test could be started with %d tests left", len(tests)-started)
+		}
+		started += len(names)
+		counts := map[string]int{}
+		for _, test := range running {
+			for name := range requiredLocks(test) {
+				counts[name]++
+			}
+		}
+		for _, test := range running {
+			for name, count := range requiredLocks(test) {
+				if counts[name] > count {
+					t.Errorf("%s ran while %d tests held lock %s: %v", test.name, counts[name], name, names)
+				}
+			}
+		}
+		doneAll()
+	}
+	if len(q.held) != 0 {
+		t.Errorf("expected all locks to be released, got %v", q.held)
+	}
+}
@@ -0,0 +1,122 @@
+package ginkgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+// resultRecord is a line of the --output-jsonl stream.  Type is "test" for each test as it finishes, "invariant" for
+// each result of the monitor invariants, and "summary" for the outcome of the suite, which is the last record.
+type resultRecord struct {
+	Type  string `json:"type"`
+	Name  string `json:"name,omitempty"`
+	State string `json:"state"`
+
+	DurationSeconds float64    `json:"durationSeconds"`
+	Start           *time.Time `json:"start,omitempty"`
+	End             *time.Time `json:"end,omitempty"`
+	ExitCode        *int       `json:"exitCode,omitempty"`
+	TimedOut        bool       `json:"timedOut,omitempty"`
+	Failure         string     `json:"failure,omitempty"`
+
+	Passed  *int `json:"passed,omitempty"`
+	Failed  *int `json:"failed,omitempty"`
+	Skipped *int `json:"skipped,omitempty"`
+}
+
+// resultStream writes a JSON record per line as results become available, so progress can be followed while the
+// suite runs.  A nil stream writes nothing.
+type resultStream struct {
+	lock    sync.Mutex
+	encoder *json.Encoder
+	errOut  io.Writer
+}
+
+func newResultStream(out io.Writer, errOut io.Writer) *resultStream {
+	return &resultStream{encoder: json.NewEncoder(out), errOut: errOut}
+}
+
+func (s *resultStream) write(record *resultRecord) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.encoder.Encode(record); err != nil {
+		fmt.Fprintf(s.errOut, "error: Unable to write result of %q: %v\n", record.Name, err)
+	}
+}
+
+// writeTest records a finished test.
+func (s *resultStream) writeTest(test *testCase) {
+	start, end, exitCode := test.start, test.end, test.exitCode
+	record := &resultRecord{
+		Type:            "test",
+		Name:            test.name,
+		DurationSeconds: test.duration.Seconds(),
+		Start:           &start,
+		End:             &end,
+		ExitCode:        &exitCode,
+		TimedOut:        test.timedOut,
+	}
+	switch {
+	case test.flake:
+		record.State = "flaked"
+		record.Failure = lastLinesUntil(string(test.out), 100, "flake:")
+	case test.success:
+		record.State = "passed"
+	case test.skipped:
+		record.State = "skipped"
+		record.Failure = lastLinesUntil(string(test.out), 100, "skip [")
+	case test.failed:
+		record.State = "failed"
+		record.Failure = lastLinesUntil(string(test.out), 100, "fail [")
+	default:
+		record.State = "unknown"
+	}
+	s.write(record)
+}
+
+// writeInvariants records the results of the monitor invariants.
+func (s *resultStream) writeInvariants(results []*junitapi.JUnitTestCase) {
+	for _, result := range results {
+		record := &resultRecord{
+			Type:            "invariant",
+			Name:            result.Name,
+			State:           "passed",
+			DurationSeconds: result.Duration,
+		}
+		switch {
+		case result.FailureOutput != nil:
+			record.State = "failed"
+			record.Failure = result.FailureOutput.Output
+		case result.SkipMessage != nil:
+			record.State = "skipped"
+			record.Failure = result.SkipMessage.Message
+		}
+		s.write(record)
+	}
+}
+
+// writeSummary records the outcome of the suite.
+func (s *resultStream) writeSummary(name string, err error, pass, fail, skip int, duration time.Duration) {
+	record := &resultRecord{
+		Type:            "summary",
+		Name:            name,
+		State:           "passed",
+		DurationSeconds: duration.Seconds(),
+		Passed:          &pass,
+		Failed:          &fail,
+		Skipped:         &skip,
+	}
+	if err != nil {
+		record.State = "failed"
+		record.Failure = err.Error()
+	}
+	s.write(record)
+}
@@ -0,0 +1,54 @@
+package ginkgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+func Test_resultStream(t *testing.T) {
+	start := time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)
+	out := &bytes.Buffer{}
+	results := newResultStream(out, out)
+
+	results.writeTest(&testCase{name: "passing", start: start, end: start.Add(time.Minute), duration: time.Minute, success: true})
+	results.writeTest(&testCase{name: "timing out", start: start, end: start.Add(time.Hour), duration: time.Hour, failed: true, timedOut: true, exitCode: 2, out: []byte("fail [timeout]\n")})
+	results.writeInvariants([]*junitapi.JUnitTestCase{
+		{Name: "[sig-arch] invariant", FailureOutput: &junitapi.FailureOutput{Output: "violated"}},
+	})
+	results.writeSummary("openshift-tests", fmt.Errorf("1 fail, 1 pass, 0 skip (1h0m0s)"), 1, 1, 0, time.Hour)
+
+	var records []resultRecord
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var record resultRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 4 {
+		t.Fatalf("expected 4 records, got %d:\n%s", len(records), out.String())
+	}
+
+	if r := records[0]; r.Type != "test" || r.State != "passed" || r.DurationSeconds != 60 || r.ExitCode == nil || *r.ExitCode != 0 || !r.Start.Equal(start) {
+		t.Errorf("unexpected record of a passing test: %#v", r)
+	}
+	if r := records[1]; r.State != "failed" || !r.TimedOut || *r.ExitCode != 2 || r.Failure != "fail [timeout]" {
+		t.Errorf("unexpected record of a failing test: %#v", r)
+	}
+	if r := records[2]; r.Type != "invariant" || r.State != "failed" || r.Failure != "violated" {
+		t.Errorf("unexpected record of an invariant: %#v", r)
+	}
+	if r := records[3]; r.Type != "summary" || r.State != "failed" || *r.Passed != 1 || *r.Failed != 1 || *r.Skipped != 0 {
+		t.Errorf("unexpected summary: %#v", r)
+	}
+
+	// without --output-jsonl nothing is written
+	var disabled *resultStream
+	disabled.writeTest(&testCase{name: "passing", success: true})
+}
@@ -0,0 +1,196 @@
+package ginkgo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+// resumedRun is what an interrupted run of a suite left in its --junit-dir.
+type resumedRun struct {
+	dir string
+	// done are the names of the tests that passed or were skipped.  They are not run again.
+	done sets.String
+	// testCases are the JUnit results of the earlier run.  filter keeps those of the tests that are not run again.
+	testCases []*junitapi.JUnitTestCase
+	// events are the events of the earlier run, which ran from from to to.
+	events   monitorapi.Intervals
+	from, to time.Time
+}
+
+// readResumedRun reads the junit_e2e_*.xml files and the events of an earlier run from dir.  The events are read
+// from the e2e-events_*.json files, or from the event journal when the run did not get to write them.  A run that was
+// killed before writing its results is resumed from the finished tests recorded in its events.
+func readResumedRun(dir string) (*resumedRun, error) {
+	junitFiles, err := filepath.Glob(filepath.Join(dir, "junit_e2e_*.xml"))
+	if err != nil {
+		return nil, err
+	}
+	r := &resumedRun{dir: dir, done: sets.NewString()}
+
+	eventFiles, err := filepath.Glob(filepath.Join(dir, "e2e-events_*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, filename := range eventFiles {
+		saved, err := monitorserialization.EventsFromFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read events from %s: %v", filename, err)
+		}
+		r.events = append(r.events, saved...)
+	}
+	if journalFilename := filepath.Join(dir, monitor.JournalFilename); len(r.events) == 0 {
+		if _, err := os.Stat(journalFilename); err == nil {
+			journaled, err := monitor.LoadJournal(journalFilename)
+			if err != nil {
+				return nil, err
+			}
+			r.events = journaled.Intervals(time.Time{}, time.Time{})
+		}
+	}
+	if len(junitFiles) == 0 && len(r.events) == 0 {
+		return nil, fmt.Errorf("no junit_e2e_*.xml results, events or event journal were found in %s", dir)
+	}
+	if len(r.events) > 0 {
+		sort.Sort(r.events)
+		// intervals derived from a journal without a known beginning, like those of tests that finished without a
+		// start, start at the zero time
+		for _, event := range r.events {
+			for _, t := range []time.Time{event.From, event.To} {
+				if t.IsZero() {
+					continue
+				}
+				if r.from.IsZero() || t.Before(r.from) {
+					r.from = t
+				}
+				if t.After(r.to) {
+					r.to = t
+				}
+			}
+		}
+		// intervals still open when the run was interrupted end with it
+		r.events.Clamp(r.from, r.to)
+	}
+
+	for _, filename := range junitFiles {
+		suite, err := readJUnitSuite(filename)
+		if err != nil {
+			return nil, err
+		}
+		r.testCases = append(r.testCases, suite.TestCases...)
+	}
+	if len(junitFiles) == 0 {
+		r.testCases = testCasesFromEvents(r.events)
+	}
+	for _, testCase := range r.testCases {
+		// a test that failed and then passed on retry is a flake and does not need to run again
+		if testCase.FailureOutput == nil {
+			r.done.Insert(testCase.Name)
+		}
+	}
+	return r, nil
+}
+
+// testCasesFromEvents returns the results of the tests that finished, from the events recorded as each test finishes.
+// When a test finished more than once, the last result counts.
+func testCasesFromEvents(events monitorapi.Intervals) []*junitapi.JUnitTestCase {
+	var names []string
+	started := map[string]time.Time{}
+	finished := map[string][]*junitapi.JUnitTestCase{}
+	for _, event := range events {
+		name, ok := monitorapi.E2ETestFromLocator(event.Locator)
+		if !ok || !event.From.Equal(event.To) {
+			continue
+		}
+		if event.Message == "started" {
+			started[name] = event.From
+			continue
+		}
+		if !strings.HasPrefix(event.Message, "finishedStatus/") {
+			continue
+		}
+
+		var duration float64
+		if start, ok := started[name]; ok {
+			duration = event.From.Sub(start).Seconds()
+		}
+		if _, ok := finished[name]; !ok {
+			names = append(names, name)
+		}
+		failure := &junitapi.FailureOutput{Output: fmt.Sprintf("%s in the earlier run, its output was not saved", event.Message)}
+		switch {
+		case strings.HasPrefix(event.Message, "finishedStatus/Passed"):
+			finished[name] = []*junitapi.JUnitTestCase{{Name: name, Duration: duration}}
+		case strings.HasPrefix(event.Message, "finishedStatus/Skipped"):
+			finished[name] = []*junitapi.JUnitTestCase{{Name: name, Duration: duration, SkipMessage: &junitapi.SkipMessage{Message: "skipped in the earlier run"}}}
+		case strings.HasPrefix(event.Message, "finishedStatus/Flaked"):
+			finished[name] = []*junitapi.JUnitTestCase{{Name: name, Duration: duration, FailureOutput: failure}, {Name: name, Duration: duration}}
+		default:
+			finished[name] = []*junitapi.JUnitTestCase{{Name: name, Duration: duration, FailureOutput: failure}}
+		}
+	}
+
+	var testCases []*junitapi.JUnitTestCase
+	for _, name := range names {
+		testCases = append(testCases, finished[name]...)
+	}
+	return testCases
+}
+
+// filter returns the tests that did not pass or get skipped in the earlier run, and keeps the earlier results of the
+// others to report with this run.  Earlier invariant results are dropped, the invariants are evaluated again against
+// the events of both runs.
+func (r *resumedRun) filter(tests []*testCase) []*testCase {
+	names := sets.NewString()
+	var remaining []*testCase
+	for _, test := range tests {
+		names.Insert(test.name)
+		if !r.done.Has(test.name) {
+			remaining = append(remaining, test)
+		}
+	}
+	var testCases []*junitapi.JUnitTestCase
+	for _, testCase := range r.testCases {
+		if names.Has(testCase.Name) && r.done.Has(testCase.Name) {
+			testCases = append(testCases, testCase)
+		}
+	}
+	r.testCases = testCases
+	return remaining
+}
+
+// duration is how long the earlier run was monitored.
+func (r *resumedRun) duration() time.Duration {
+	return r.to.Sub(r.from)
+}
+
+// intervals returns the events of the earlier run and an interval covering the time nothing was monitored, until
+// this run started at resumedAt.
+func (r *resumedRun) intervals(resumedAt time.Time) monitorapi.Intervals {
+	if len(r.events) == 0 {
+		return nil
+	}
+	intervals := append(monitorapi.Intervals{}, r.events...)
+	if r.to.Before(resumedAt) {
+		intervals = append(intervals, monitorapi.EventInterval{
+			Condition: monitorapi.Condition{
+				Level:   monitorapi.Warning,
+				Locator: "e2e-run/resumed",
+				Message: fmt.Sprintf("reason/Resumed nothing was monitored between the run in %s and this run", r.dir),
+			},
+			From: r.to,
+			To:   resumedAt,
+		})
+	}
+	return intervals
+}
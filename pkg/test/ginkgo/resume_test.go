@@ -0,0 +1,141 @@
+package ginkgo
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+func TestResumedRun(t *testing.T) {
+	dir := t.TempDir()
+	from := time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	results := []*junitapi.JUnitTestCase{
+		{Name: "passed", Duration: 10},
+		{Name: "skipped", SkipMessage: &junitapi.SkipMessage{Message: "skipped"}},
+		{Name: "failed", FailureOutput: &junitapi.FailureOutput{Output: "failed"}},
+		{Name: "flaked", FailureOutput: &junitapi.FailureOutput{Output: "failed"}},
+		{Name: "flaked", Duration: 5},
+		{Name: "removed from the suite", Duration: 5},
+		{Name: "[sig-arch] invariant", Duration: 1},
+	}
+	if err := writeJUnitReport("junit_e2e", "openshift-tests", nil, dir, time.Hour, &bytes.Buffer{}, results...); err != nil {
+		t.Fatal(err)
+	}
+	err := monitorserialization.EventsToFile(filepath.Join(dir, "e2e-events_20220401-100000.json"), monitorapi.Intervals{
+		{Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: "node/node1", Message: "reason/NotReady"}, From: from, To: from.Add(time.Hour)},
+		{Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: "node/node1", Message: "reason/Ready"}, From: from.Add(time.Hour), To: from.Add(time.Hour)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resumed, err := readResumedRun(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests []*testCase
+	for _, name := range []string{"passed", "skipped", "failed", "flaked", "not run"} {
+		tests = append(tests, &testCase{name: name})
+	}
+	var remaining []string
+	for _, test := range resumed.filter(tests) {
+		remaining = append(remaining, test.name)
+	}
+	if len(remaining) != 2 || remaining[0] != "failed" || remaining[1] != "not run" {
+		t.Errorf("expected failed and not run to be run again, got %v", remaining)
+	}
+	var kept []string
+	for _, testCase := range resumed.testCases {
+		kept = append(kept, testCase.Name)
+	}
+	if len(kept) != 4 || kept[0] != "passed" || kept[1] != "skipped" || kept[2] != "flaked" || kept[3] != "flaked" {
+		t.Errorf("expected the results of the tests that are not run again, got %v", kept)
+	}
+
+	if resumed.duration() != time.Hour {
+		t.Errorf("expected the earlier run to have been monitored for an hour, got %s", resumed.duration())
+	}
+	intervals := resumed.intervals(from.Add(2 * time.Hour))
+	if len(intervals) != 3 {
+		t.Fatalf("expected the earlier events and a gap, got %v", intervals)
+	}
+	gap := intervals[2]
+	if gap.Locator != "e2e-run/resumed" || !gap.From.Equal(from.Add(time.Hour)) || !gap.To.Equal(from.Add(2*time.Hour)) {
+		t.Errorf("unexpected gap %v", gap)
+	}
+
+	if _, err := readResumedRun(t.TempDir()); err == nil {
+		t.Errorf("expected a directory without results to be rejected")
+	}
+}
+
+func TestResumedRun_killedBeforeResults(t *testing.T) {
+	dir := t.TempDir()
+	from := time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	// a run killed before it wrote its results only leaves the journal behind
+	instant := func(name, message string, at time.Duration) monitorapi.EventInterval {
+		return monitorapi.EventInterval{
+			Condition: monitorapi.Condition{Level: monitorapi.Info, Locator: monitorapi.E2ETestLocator(name), Message: message},
+			From:      from.Add(at),
+			To:        from.Add(at),
+		}
+	}
+	journal := &bytes.Buffer{}
+	for _, event := range []monitorapi.EventInterval{
+		instant("passed", "started", 0),
+		instant("passed", "finishedStatus/Passed", 10*time.Second),
+		instant("skipped", "finishedStatus/Skipped", 11*time.Second),
+		instant("failed", "finishedStatus/Failed  reason/Timeout", 12*time.Second),
+		instant("flaked", "finishedStatus/Flaked", 13*time.Second),
+		instant("retried", "finishedStatus/Failed", 14*time.Second),
+		instant("retried", "finishedStatus/Passed", 15*time.Second),
+		instant("running", "started", 16*time.Second),
+	} {
+		if err := monitorserialization.WriteJournalRecord(journal, monitorserialization.IntervalJournalRecord(event, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, monitor.JournalFilename), journal.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resumed, err := readResumedRun(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests []*testCase
+	for _, name := range []string{"passed", "skipped", "failed", "flaked", "retried", "running", "not run"} {
+		tests = append(tests, &testCase{name: name})
+	}
+	var remaining []string
+	for _, test := range resumed.filter(tests) {
+		remaining = append(remaining, test.name)
+	}
+	if want := []string{"failed", "running", "not run"}; !reflect.DeepEqual(remaining, want) {
+		t.Errorf("expected %v to be run again, got %v", want, remaining)
+	}
+	var kept []string
+	for _, testCase := range resumed.testCases {
+		kept = append(kept, testCase.Name)
+	}
+	if want := []string{"passed", "skipped", "flaked", "flaked", "retried"}; !reflect.DeepEqual(kept, want) {
+		t.Errorf("expected the results of %v, got %v", want, kept)
+	}
+	if resumed.testCases[0].Duration != 10 {
+		t.Errorf("expected the duration from the start of the test, got %v", resumed.testCases[0].Duration)
+	}
+	if resumed.duration() != 16*time.Second {
+		t.Errorf("expected the earlier run to have been monitored for 16s, got %s", resumed.duration())
+	}
+}
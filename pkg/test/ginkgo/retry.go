@@ -0,0 +1,128 @@
+package ginkgo
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// NoRetryTag in the name of a test keeps it from being retried, so a failure of it is never reported as a flake.
+const NoRetryTag = "[NoRetry]"
+
+// RetryPolicy is how the failed tests of a suite are run again to tell flakes from failures.
+type RetryPolicy struct {
+	// MaxAttempts is how many times a failing test is run, including the first time.  1 disables retries.
+	MaxAttempts int
+	// Immediate retries a test as soon as it fails instead of after all the tests have run.
+	Immediate bool
+	// Budget is how many retries the suite may run in total.  When retrying at the end and more tests failed than the
+	// budget, nothing is retried because the suite fails anyway.
+	Budget int
+}
+
+// retryPolicy returns the RetryPolicy of the suite.  By default each failed test is retried once after all the tests
+// have run, when no more than MaximumAllowedFlakes tests failed.
+func (s *TestSuite) retryPolicy() RetryPolicy {
+	if s.RetryPolicy != nil {
+		return *s.RetryPolicy
+	}
+	return RetryPolicy{MaxAttempts: 2, Budget: s.MaximumAllowedFlakes}
+}
+
+// retrier hands out the retries of a RetryPolicy to the failed tests.
+type retrier struct {
+	policy RetryPolicy
+
+	lock sync.Mutex
+	used int
+	// retries are the attempts run by immediately
+	retries []*testCase
+}
+
+func newRetrier(policy RetryPolicy) *retrier {
+	return &retrier{policy: policy}
+}
+
+// take reports whether the failed test may be run again, and if so takes a retry from the budget.
+func (r *retrier) take(test *testCase) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if strings.Contains(test.name, NoRetryTag) {
+		return false
+	}
+	if attempts(test) >= r.policy.MaxAttempts || r.used >= r.policy.Budget {
+		return false
+	}
+	r.used++
+	return true
+}
+
+// immediately returns a TestFunc that runs a test with fn and runs it again as long as it fails and may be retried.
+// The test keeps its place in the queue, and its exclusions and locks, until the last attempt finishes.
+func (r *retrier) immediately(fn TestFunc) TestFunc {
+	return func(ctx context.Context, test *testCase) {
+		fn(ctx, test)
+		for attempt := test; attempt.failed && ctx.Err() == nil && r.take(attempt); {
+			retry := attempt.Retry()
+			fn(ctx, retry)
+			r.lock.Lock()
+			r.retries = append(r.retries, retry)
+			r.lock.Unlock()
+			attempt = retry
+		}
+	}
+}
+
+// retry returns the next attempts of the failed tests that may be retried.
+func (r *retrier) retry(failed []*testCase) []*testCase {
+	var retries []*testCase
+	for _, test := range failed {
+		if r.take(test) {
+			retries = append(retries, test.Retry())
+		}
+	}
+	return retries
+}
+
+// attempts returns how many times a test has been run, including the attempt test.
+func attempts(test *testCase) int {
+	count := 1
+	for previous := test.previous; previous != nil; previous = previous.previous {
+		count++
+	}
+	return count
+}
+
+// firstAttempts returns the tests that are not retries.
+func firstAttempts(tests []*testCase) []*testCase {
+	first, _ := splitTests(tests, func(t *testCase) bool { return t.previous == nil })
+	return first
+}
+
+// lastAttempts returns the tests that were not retried, whose outcome is the outcome of the test.
+func lastAttempts(tests []*testCase) []*testCase {
+	retried := make(map[*testCase]struct{})
+	for _, test := range tests {
+		if test.previous != nil {
+			retried[test.previous] = struct{}{}
+		}
+	}
+	_, last := splitTests(tests, func(t *testCase) bool {
+		_, ok := retried[t]
+		return ok
+	})
+	return last
+}
+
+// isFlake is true when the last attempt of a test passed after an earlier attempt failed.
+func isFlake(last *testCase) bool {
+	if !last.success {
+		return false
+	}
+	for previous := last.previous; previous != nil; previous = previous.previous {
+		if previous.failed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package ginkgo
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func Test_retrier_immediately(t *testing.T) {
+	// each test fails the given number of times before it passes
+	failures := map[string]int{
+		"passes":             0,
+		"flakes":             1,
+		"flakes twice":       2,
+		"fails":              5,
+		"fails [NoRetry]":    1,
+		"fails after budget": 5,
+		"not reached":        1,
+	}
+	runs := map[string]int{}
+	fn := func(ctx context.Context, test *testCase) {
+		runs[test.name]++
+		if runs[test.name] > failures[test.name] {
+			test.success = true
+		} else {
+			test.failed = true
+		}
+	}
+
+	r := newRetrier(RetryPolicy{MaxAttempts: 3, Immediate: true, Budget: 6})
+	run := r.immediately(fn)
+	var tests []*testCase
+	for _, name := range []string{"passes", "flakes", "flakes twice", "fails", "fails [NoRetry]", "fails after budget", "not reached"} {
+		test := &testCase{name: name}
+		run(context.Background(), test)
+		tests = append(tests, test)
+	}
+	tests = append(tests, r.retries...)
+
+	expectedRuns := map[string]int{
+		"passes":             1,
+		"flakes":             2,
+		"flakes twice":       3,
+		"fails":              3,
+		"fails [NoRetry]":    1,
+		"fails after budget": 2,
+		"not reached":        1,
+	}
+	if !reflect.DeepEqual(runs, expectedRuns) {
+		t.Errorf("unexpected attempts %v, expected %v", runs, expectedRuns)
+	}
+
+	if first := firstAttempts(tests); len(first) != 7 {
+		t.Errorf("expected one first attempt per test, got %d", len(first))
+	}
+	var flaky, failing []string
+	for _, test := range lastAttempts(tests) {
+		switch {
+		case isFlake(test):
+			flaky = append(flaky, test.name)
+		case test.failed:
+			failing = append(failing, test.name)
+		}
+	}
+	sort.Strings(flaky)
+	sort.Strings(failing)
+	if expected := []string{"flakes", "flakes twice"}; !reflect.DeepEqual(flaky, expected) {
+		t.Errorf("expected flakes %v, got %v", expected, flaky)
+	}
+	if expected := []string{"fails", "fails [NoRetry]", "fails after budget", "not reached"}; !reflect.DeepEqual(failing, expected) {
+		t.Errorf("expected failures %v, got %v", expected, failing)
+	}
+}
+
+func Test_retrier_retry(t *testing.T) {
+	r := newRetrier((&TestSuite{MaximumAllowedFlakes: 2}).retryPolicy())
+	failed := []*testCase{{name: "a", failed: true}, {name: "b", failed: true}, {name: "c", failed: true}}
+	retries := r.retry(failed)
+	if len(retries) != 2 || retries[0].previous != failed[0] || retries[1].previous != failed[1] {
+		t.Fatalf("expected the first two failures to be retried within the budget, got %v", retries)
+	}
+	for _, retry := range retries {
+		retry.failed = true
+	}
+	if again := r.retry(retries); len(again) != 0 {
+		t.Errorf("expected tests to be attempted at most twice by default, got %v", again)
+	}
+}
@@ -0,0 +1,64 @@
+package ginkgo
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// validateShard checks the --shard-index and --shard-count flags.  A count of 0 disables sharding.
+func validateShard(index, count int) error {
+	if count < 0 {
+		return fmt.Errorf("--shard-count must not be negative")
+	}
+	if count == 0 {
+		if index != 0 {
+			return fmt.Errorf("--shard-index requires --shard-count")
+		}
+		return nil
+	}
+	if index < 0 || index >= count {
+		return fmt.Errorf("--shard-index must be between 0 and %d", count-1)
+	}
+	return nil
+}
+
+// isPinnedToFirstShard is true for tests that must all run in the same process: [Serial] tests expect nothing else
+// to run against the cluster, and [Early] and [Late] tests expect to run before and after everything else.
+func isPinnedToFirstShard(t *testCase) bool {
+	return strings.Contains(t.name, "[Serial]") || strings.Contains(t.name, "[Early]") || strings.Contains(t.name, "[Late]")
+}
+
+// shardTests returns the tests that shard index of count runs.  Tests are assigned by a hash of their name so every
+// host computes the same partition independently, and a test stays on the same shard when other tests are added or
+// removed.  Tests that share a testExclusion or a lock are assigned together because they must not run at the same
+// time, and pinned tests always run on shard 0.  A test with several locks is assigned by the first lock name.
+func shardTests(tests []*testCase, index, count int) []*testCase {
+	if count <= 1 {
+		return tests
+	}
+	var ret []*testCase
+	for _, test := range tests {
+		if shardFor(test, count) == index {
+			ret = append(ret, test)
+		}
+	}
+	return ret
+}
+
+func shardFor(t *testCase, count int) int {
+	if isPinnedToFirstShard(t) {
+		return 0
+	}
+	key := t.name
+	if len(t.testExclusion) > 0 {
+		key = t.testExclusion
+	} else if len(t.locks) > 0 {
+		key = sets.StringKeySet(t.locks).List()[0]
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(count))
+}
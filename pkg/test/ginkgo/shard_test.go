@@ -0,0 +1,80 @@
+package ginkgo
+
+import (
+	"fmt"
+	"testing"
+)
+
+func Test_shardTests(t *testing.T) {
+	var tests []*testCase
+	for i := 0; i < 100; i++ {
+		tests = append(tests, &testCase{name: fmt.Sprintf("[sig-test] test %d [Suite:openshift/conformance/parallel]", i)})
+	}
+	for i := 0; i < 5; i++ {
+		tests = append(tests, &testCase{name: fmt.Sprintf("[sig-apps] disruption %d", i), testExclusion: "/k8s.io/kubernetes/test/e2e/apps/disruption.go"})
+	}
+	tests = append(tests,
+		&testCase{name: "[sig-test] serial [Serial]"},
+		&testCase{name: "[sig-test] early [Early]"},
+		&testCase{name: "[sig-test] late [Late]"},
+	)
+
+	const count = 4
+	shardOf := map[string]int{}
+	for index := 0; index < count; index++ {
+		shard := shardTests(tests, index, count)
+		if len(shard) == 0 {
+			t.Errorf("shard %d is empty", index)
+		}
+		for _, test := range shard {
+			if previous, ok := shardOf[test.name]; ok {
+				t.Errorf("%q is in shards %d and %d", test.name, previous, index)
+			}
+			shardOf[test.name] = index
+		}
+		// the partition must not depend on the order of the tests
+		reversed := make([]*testCase, 0, len(tests))
+		for i := len(tests) - 1; i >= 0; i-- {
+			reversed = append(reversed, tests[i])
+		}
+		if len(shardTests(reversed, index, count)) != len(shard) {
+			t.Errorf("shard %d changed when the tests were reordered", index)
+		}
+	}
+	if len(shardOf) != len(tests) {
+		t.Errorf("expected every test in a shard, got %d of %d", len(shardOf), len(tests))
+	}
+	for _, name := range []string{"[sig-test] serial [Serial]", "[sig-test] early [Early]", "[sig-test] late [Late]"} {
+		if shardOf[name] != 0 {
+			t.Errorf("expected %q in shard 0, got %d", name, shardOf[name])
+		}
+	}
+	for i := 1; i < 5; i++ {
+		if shardOf[fmt.Sprintf("[sig-apps] disruption %d", i)] != shardOf["[sig-apps] disruption 0"] {
+			t.Errorf("expected tests with the same exclusion in the same shard")
+		}
+	}
+
+	if got := shardTests(tests, 0, 1); len(got) != len(tests) {
+		t.Errorf("expected a single shard to run everything, got %d", len(got))
+	}
+}
+
+func Test_validateShard(t *testing.T) {
+	tests := []struct {
+		index, count int
+		wantErr      bool
+	}{
+		{index: 0, count: 0},
+		{index: 1, count: 0, wantErr: true},
+		{index: 2, count: 3},
+		{index: 3, count: 3, wantErr: true},
+		{index: -1, count: 3, wantErr: true},
+		{index: 0, count: -1, wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := validateShard(tt.index, tt.count); (err != nil) != tt.wantErr {
+			t.Errorf("validateShard(%d, %d) = %v, wantErr %t", tt.index, tt.count, err, tt.wantErr)
+		}
+	}
+}
@@ -10,7 +10,6 @@ import (
 	"sort"
 	"strings"
 	"sync"
-	"syscall"
 	"time"
 
 	"github.com/openshift/origin/pkg/monitor"
@@ -26,6 +25,10 @@ type testStatus struct {
 	env             []string
 
 	afterTestFn func(t *testCase)
+	// results receives the result of each test when it is set
+	results *resultStream
+	// workers run the tests when set, instead of a process per test
+	workers *workerPool
 
 	includeSuccessfulOutput bool
 
@@ -126,6 +129,7 @@ func (s *testStatus) finalizeTest(test *testCase) {
 		Locator: monitorapi.E2ETestLocator(test.name),
 		Message: eventMessage,
 	})
+	s.results.writeTest(test)
 }
 
 // OutputCommand prints to stdout what would have been executed.
@@ -148,8 +152,6 @@ func (s *testStatus) Run(ctx context.Context, test *testCase) {
 	defer s.finalizeTest(test)
 
 	test.start = time.Now()
-	c := exec.Command(os.Args[0], "run-test", test.name)
-	c.Env = append(os.Environ(), s.env...)
 	s.fprintf(fmt.Sprintf("started: (%s) %q\n\n", "%d/%d/%d", test.name))
 
 	timeout := s.timeout
@@ -157,7 +159,15 @@ func (s *testStatus) Run(ctx context.Context, test *testCase) {
 		timeout = test.testTimeout
 	}
 
-	out, err := runWithTimeout(ctx, c, timeout)
+	var out []byte
+	var err error
+	if s.workers != nil {
+		out, err = s.workers.Run(ctx, test.name, timeout)
+	} else {
+		c := exec.Command(os.Args[0], "run-test", test.name)
+		c.Env = append(os.Environ(), s.env...)
+		out, err = runWithTimeout(ctx, c, timeout)
+	}
 	test.end = time.Now()
 
 	duration := test.end.Sub(test.start).Round(time.Second / 10)
@@ -179,8 +189,9 @@ func (s *testStatus) Run(ctx context.Context, test *testCase) {
 		return
 	}
 
-	if exitErr, ok := err.(*exec.ExitError); ok {
-		switch exitErr.ProcessState.Sys().(syscall.WaitStatus).ExitStatus() {
+	if code, ok := exitCode(err); ok {
+		test.exitCode = code
+		switch test.exitCode {
 		case 1:
 			// failed
 			test.failed = true
@@ -0,0 +1,333 @@
+package ginkgo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// SuiteDefinition is a test suite defined in a --suite-file rather than in code.
+type SuiteDefinition struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// Include are regular expressions of the tests in the suite.  A test is included if it matches any of them, or
+	// all tests are included if there are none.
+	Include []string `json:"include"`
+	// Exclude are regular expressions of tests left out of the suite, even if they are included.
+	Exclude []string `json:"exclude"`
+	// Tags is an expression of the tags a test must have, like "[sig-network] && !([Serial] || [Slow])".
+	Tags string `json:"tags"`
+
+	Parallelism          int    `json:"parallelism"`
+	Count                int    `json:"count"`
+	TestTimeout          string `json:"testTimeout"`
+	MaximumAllowedFlakes int    `json:"maximumAllowedFlakes"`
+	// Invariants names the set of invariants evaluated against the monitor events of the suite.  There are none when
+	// it is empty.
+	Invariants string `json:"invariants"`
+}
+
+// suitePatterns are the compiled patterns of a SuiteDefinition.
+type suitePatterns struct {
+	include, exclude []*regexp.Regexp
+	tags             *tagExpression
+}
+
+// matches is true when name is included, has the tags and is not excluded.  Disabled tests are never included.
+func (p *suitePatterns) matches(name string) bool {
+	if strings.Contains(name, "[Disabled") {
+		return false
+	}
+	if len(p.include) > 0 && !matchesAny(p.include, name) {
+		return false
+	}
+	if p.tags != nil && !p.tags.matches(name) {
+		return false
+	}
+	return !matchesAny(p.exclude, name)
+}
+
+// unresolved returns the patterns that match none of the tests, which usually means they are mistyped.
+func (p *suitePatterns) unresolved(tests []*testCase) []string {
+	var unresolved []string
+	for _, patterns := range [][]*regexp.Regexp{p.include, p.exclude} {
+		for _, re := range patterns {
+			found := false
+			for _, test := range tests {
+				if re.MatchString(test.name) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				unresolved = append(unresolved, re.String())
+			}
+		}
+	}
+	if p.tags != nil {
+		for _, tag := range p.tags.tags() {
+			found := false
+			for _, test := range tests {
+				if strings.Contains(test.name, tag) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				unresolved = append(unresolved, tag)
+			}
+		}
+	}
+	return unresolved
+}
+
+func matchesAny(patterns []*regexp.Regexp, name string) bool {
+	for _, re := range patterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadSuiteFile reads a YAML list of SuiteDefinitions from path.  invariants are the sets of invariants a suite may
+// name.
+func ReadSuiteFile(path string, invariants map[string]JUnitsForEvents) ([]*TestSuite, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var definitions []SuiteDefinition
+	if err := yaml.Unmarshal(data, &definitions); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %v", path, err)
+	}
+	var suites []*TestSuite
+	names := make(map[string]struct{})
+	for _, definition := range definitions {
+		suite, err := newSuiteFromDefinition(definition, invariants)
+		if err != nil {
+			return nil, fmt.Errorf("invalid suite %q in %s: %v", definition.Name, path, err)
+		}
+		if _, ok := names[suite.Name]; ok {
+			return nil, fmt.Errorf("suite %q is defined more than once in %s", suite.Name, path)
+		}
+		names[suite.Name] = struct{}{}
+		suites = append(suites, suite)
+	}
+	return suites, nil
+}
+
+func newSuiteFromDefinition(definition SuiteDefinition, invariants map[string]JUnitsForEvents) (*TestSuite, error) {
+	if len(definition.Name) == 0 {
+		return nil, fmt.Errorf("a name is required")
+	}
+	patterns := &suitePatterns{}
+	for _, include := range definition.Include {
+		re, err := regexp.Compile(include)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include: %v", err)
+		}
+		patterns.include = append(patterns.include, re)
+	}
+	for _, exclude := range definition.Exclude {
+		re, err := regexp.Compile(exclude)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude: %v", err)
+		}
+		patterns.exclude = append(patterns.exclude, re)
+	}
+	if len(strings.TrimSpace(definition.Tags)) > 0 {
+		tags, err := parseTagExpression(definition.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tags: %v", err)
+		}
+		patterns.tags = tags
+	}
+	if definition.Parallelism < 0 || definition.MaximumAllowedFlakes < 0 {
+		return nil, fmt.Errorf("parallelism and maximumAllowedFlakes must not be negative")
+	}
+
+	suite := &TestSuite{
+		Name:                 definition.Name,
+		Description:          definition.Description,
+		Matches:              patterns.matches,
+		Count:                definition.Count,
+		Parallelism:          definition.Parallelism,
+		MaximumAllowedFlakes: definition.MaximumAllowedFlakes,
+		patterns:             patterns,
+	}
+	if len(definition.TestTimeout) > 0 {
+		timeout, err := time.ParseDuration(definition.TestTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid testTimeout: %v", err)
+		}
+		suite.TestTimeout = timeout
+	}
+	if len(definition.Invariants) > 0 {
+		tests, ok := invariants[definition.Invariants]
+		if !ok {
+			var known []string
+			for name := range invariants {
+				known = append(known, name)
+			}
+			sort.Strings(known)
+			return nil, fmt.Errorf("unknown invariants %q, must be one of %s", definition.Invariants, strings.Join(known, ", "))
+		}
+		suite.SyntheticEventTests = tests
+	}
+	return suite, nil
+}
+
+// tagExpression is a boolean expression of tags like "[sig-network] && !([Serial] || [Slow])".  A tag is true when
+// the name of a test contains it.
+type tagExpression struct {
+	tag     string
+	not     *tagExpression
+	and, or []*tagExpression
+}
+
+func (e *tagExpression) matches(name string) bool {
+	switch {
+	case e.not != nil:
+		return !e.not.matches(name)
+	case len(e.and) > 0:
+		for _, operand := range e.and {
+			if !operand.matches(name) {
+				return false
+			}
+		}
+		return true
+	case len(e.or) > 0:
+		for _, operand := range e.or {
+			if operand.matches(name) {
+				return true
+			}
+		}
+		return false
+	default:
+		return strings.Contains(name, e.tag)
+	}
+}
+
+// tags returns the tags of the expression.
+func (e *tagExpression) tags() []string {
+	switch {
+	case e.not != nil:
+		return e.not.tags()
+	case len(e.and) > 0 || len(e.or) > 0:
+		var tags []string
+		for _, operand := range append(e.and, e.or...) {
+			tags = append(tags, operand.tags()...)
+		}
+		return tags
+	default:
+		return []string{e.tag}
+	}
+}
+
+// parseTagExpression parses tags combined with !, &&, || and parentheses.  && binds tighter than ||.
+func parseTagExpression(expression string) (*tagExpression, error) {
+	p := &tagParser{input: expression}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected %q at %d", p.input[p.pos:], p.pos)
+	}
+	return e, nil
+}
+
+type tagParser struct {
+	input string
+	pos   int
+}
+
+func (p *tagParser) skipSpace() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *tagParser) consume(token string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.input[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *tagParser) parseOr() (*tagExpression, error) {
+	e, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	operands := []*tagExpression{e}
+	for p.consume("||") {
+		e, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, e)
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return &tagExpression{or: operands}, nil
+}
+
+func (p *tagParser) parseAnd() (*tagExpression, error) {
+	e, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	operands := []*tagExpression{e}
+	for p.consume("&&") {
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, e)
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return &tagExpression{and: operands}, nil
+}
+
+func (p *tagParser) parseUnary() (*tagExpression, error) {
+	switch {
+	case p.consume("!"):
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &tagExpression{not: e}, nil
+	case p.consume("("):
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			return nil, fmt.Errorf("missing ) at %d", p.pos)
+		}
+		return e, nil
+	case p.consume("["):
+		end := strings.Index(p.input[p.pos:], "]")
+		if end == -1 {
+			return nil, fmt.Errorf("missing ] at %d", p.pos)
+		}
+		tag := "[" + p.input[p.pos:p.pos+end+1]
+		p.pos += end + 1
+		return &tagExpression{tag: tag}, nil
+	default:
+		return nil, fmt.Errorf("expected a [tag], ! or ( at %d", p.pos)
+	}
+}
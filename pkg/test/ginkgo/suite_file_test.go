@@ -0,0 +1,104 @@
+package ginkgo
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_parseTagExpression(t *testing.T) {
+	tests := []struct {
+		expression string
+		matches    []string
+		wantErr    bool
+	}{
+		{
+			expression: "[sig-network]",
+			matches:    []string{"[sig-network] a [Serial]", "[sig-network] b [Slow]", "[sig-network] c"},
+		},
+		{
+			expression: "[sig-network] && !([Serial] || [Slow])",
+			matches:    []string{"[sig-network] c"},
+		},
+		{
+			expression: "[Serial] || [Slow] && [sig-storage]",
+			matches:    []string{"[sig-network] a [Serial]", "[sig-storage] d [Slow]"},
+		},
+		{expression: "[sig-network] &&", wantErr: true},
+		{expression: "([sig-network]", wantErr: true},
+		{expression: "[sig-network", wantErr: true},
+		{expression: "sig-network", wantErr: true},
+	}
+	names := []string{"[sig-network] a [Serial]", "[sig-network] b [Slow]", "[sig-network] c", "[sig-storage] d [Slow]"}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			e, err := parseTagExpression(tt.expression)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTagExpression() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			var matches []string
+			for _, name := range names {
+				if e.matches(name) {
+					matches = append(matches, name)
+				}
+			}
+			if !reflect.DeepEqual(matches, tt.matches) {
+				t.Errorf("expected %v to match, got %v", tt.matches, matches)
+			}
+		})
+	}
+}
+
+func TestReadSuiteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "suites.yaml")
+	err := ioutil.WriteFile(path, []byte(`
+- name: my-team/network
+  description: The network tests.
+  include: ["\\[sig-network\\]"]
+  exclude: ["should be skipped"]
+  tags: "!([Serial] || [NoSuchTag])"
+  parallelism: 20
+  count: 2
+  testTimeout: 15m
+  maximumAllowedFlakes: 3
+  invariants: stable
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stable := JUnitForEventsFunc(nil)
+	suites, err := ReadSuiteFile(path, map[string]JUnitsForEvents{"stable": stable})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suites) != 1 {
+		t.Fatalf("expected one suite, got %d", len(suites))
+	}
+	suite := suites[0]
+	if suite.Name != "my-team/network" || suite.Parallelism != 20 || suite.Count != 2 || suite.TestTimeout != 15*time.Minute || suite.MaximumAllowedFlakes != 3 || suite.SyntheticEventTests == nil {
+		t.Errorf("unexpected suite %#v", suite)
+	}
+
+	var tests []*testCase
+	for _, name := range []string{"[sig-network] parallel", "[sig-network] serial [Serial]", "[sig-network] should be skipped", "[sig-network] disabled [Disabled:Broken]", "[sig-storage] other"} {
+		tests = append(tests, &testCase{name: name})
+	}
+	if got := testNames(suite.Filter(tests)); !reflect.DeepEqual(got, []string{"[sig-network] parallel"}) {
+		t.Errorf("unexpected tests in the suite: %v", got)
+	}
+	if got := suite.patterns.unresolved(tests[:1]); !reflect.DeepEqual(got, []string{"should be skipped", "[Serial]", "[NoSuchTag]"}) {
+		t.Errorf("unexpected unresolved patterns: %v", got)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(`[{"name": "unknown", "invariants": "none"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadSuiteFile(path, map[string]JUnitsForEvents{"stable": stable}); err == nil {
+		t.Errorf("expected unknown invariants to be rejected")
+	}
+}
@@ -3,10 +3,12 @@ package ginkgo
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
 
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/rest"
 
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
@@ -46,6 +48,43 @@ func (a JUnitsForAllEvents) JUnitsForEvents(events monitorapi.Intervals, duratio
 	return all
 }
 
+// evaluateSyntheticTests runs the monitor and event invariants against the events, summarizing failing and flaky
+// invariants in the returned buffer.  It returns true if any invariant failed without also passing.
+func evaluateSyntheticTests(events monitorapi.Intervals, duration time.Duration, kubeClientConfig *rest.Config, syntheticEventTests JUnitsForEvents, testSuite string) ([]*junitapi.JUnitTestCase, *bytes.Buffer, bool) {
+	syntheticTestResults, buf, _ := createSyntheticTestsFromMonitor(events, duration)
+	testCases := syntheticEventTests.JUnitsForEvents(events, duration, kubeClientConfig, testSuite)
+	syntheticTestResults = append(syntheticTestResults, testCases...)
+
+	var syntheticFailure bool
+	if len(syntheticTestResults) > 0 {
+		// mark any failures by name
+		failing, flaky := sets.NewString(), sets.NewString()
+		for _, test := range syntheticTestResults {
+			if test.FailureOutput != nil {
+				failing.Insert(test.Name)
+			}
+		}
+		// if a test has both a pass and a failure, flag it
+		// as a flake
+		for _, test := range syntheticTestResults {
+			if test.FailureOutput == nil {
+				if failing.Has(test.Name) {
+					flaky.Insert(test.Name)
+				}
+			}
+		}
+		failing = failing.Difference(flaky)
+		if failing.Len() > 0 {
+			fmt.Fprintf(buf, "Failing invariants:\n\n%s\n\n", strings.Join(failing.List(), "\n"))
+			syntheticFailure = true
+		}
+		if flaky.Len() > 0 {
+			fmt.Fprintf(buf, "Flaky invariants:\n\n%s\n\n", strings.Join(flaky.List(), "\n"))
+		}
+	}
+	return syntheticTestResults, buf, syntheticFailure
+}
+
 func createSyntheticTestsFromMonitor(events monitorapi.Intervals, monitorDuration time.Duration) ([]*junitapi.JUnitTestCase, *bytes.Buffer, *bytes.Buffer) {
 	var syntheticTestResults []*junitapi.JUnitTestCase
 
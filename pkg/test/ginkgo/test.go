@@ -21,6 +21,9 @@ type testCase struct {
 
 	// identifies which tests can be run in parallel (ginkgo runs suites linearly)
 	testExclusion string
+	// locks are the named locks the test declares with [Lock:name] or [Lock:name:count], and how many tests may hold
+	// each of them at the same time
+	locks map[string]int
 	// specific timeout for the current test. When set, it overrides the current
 	// suite timeout
 	testTimeout time.Duration
@@ -32,9 +35,15 @@ type testCase struct {
 	success  bool
 	failed   bool
 	timedOut bool
+	exitCode int
 	skipped  bool
 	flake    bool
 
+	// systemErr is reported with the failure of the test
+	systemErr string
+	// quarantine is set when the test is known to be broken
+	quarantine *QuarantinedTest
+
 	previous *testCase
 }
 
@@ -58,15 +67,46 @@ func newTestCase(spec ginkgoSpec) (*testCase, error) {
 		tc.testTimeout = testTimeOut
 	}
 
+	locks, err := parseLocks(name)
+	if err != nil {
+		return nil, err
+	}
+	tc.locks = locks
+
 	return tc, nil
 }
 
+var lockRegexp = regexp.MustCompile(`\[Lock:([^\]:]+)(?::([^\]]*))?\]`)
+
+// parseLocks returns the locks declared in a test name.  [Lock:name] is held by one test at a time, [Lock:name:count]
+// by up to count tests.
+func parseLocks(name string) (map[string]int, error) {
+	var locks map[string]int
+	for _, match := range lockRegexp.FindAllStringSubmatch(name, -1) {
+		count := 1
+		if len(match[2]) > 0 {
+			var err error
+			count, err = strconv.Atoi(match[2])
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("lock %q of test %q must be held by a positive number of tests, not %q", match[1], name, match[2])
+			}
+		}
+		if locks == nil {
+			locks = make(map[string]int)
+		}
+		locks[match[1]] = count
+	}
+	return locks, nil
+}
+
 func (t *testCase) Retry() *testCase {
 	copied := &testCase{
 		name:          t.name,
 		spec:          t.spec,
 		location:      t.location,
 		testExclusion: t.testExclusion,
+		locks:         t.locks,
+		quarantine:    t.quarantine,
 
 		previous: t,
 	}
@@ -85,11 +125,17 @@ type TestSuite struct {
 	Parallelism int
 	// The number of flakes that may occur before this test is marked as a failure.
 	MaximumAllowedFlakes int
+	// RetryPolicy is how failed tests are run again.  When it is nil each failed test is retried once after all the
+	// tests have run, if no more than MaximumAllowedFlakes failed.
+	RetryPolicy *RetryPolicy
 
 	// SyntheticEventTests is a set of suite level synthetics applied
 	SyntheticEventTests JUnitsForEvents
 
 	TestTimeout time.Duration
+
+	// patterns are set when the suite is read from a suite file
+	patterns *suitePatterns
 }
 
 func (s *TestSuite) Filter(tests []*testCase) []*testCase {
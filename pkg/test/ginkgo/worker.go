@@ -0,0 +1,271 @@
+package ginkgo
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// workerTokenEnv passes a worker the token that marks the end of the output of each test, so test output can not be
+// mistaken for it.
+const workerTokenEnv = "TEST_WORKER_TOKEN"
+
+// workerMaxTests is how many tests a worker runs before it is replaced.  Every test leaves goroutines and global state
+// behind in the process, which a fresh worker sheds.
+const workerMaxTests = 100
+
+// workerInputFd is the file descriptor a worker reads the names of its tests from.  Standard input is left to the
+// tests, which would otherwise consume the names of the tests that follow them.
+const workerInputFd = 3
+
+// WorkerInput returns the pipe a worker started by a workerPool reads the names of its tests from.
+func WorkerInput() io.ReadCloser {
+	return os.NewFile(workerInputFd, "worker-input")
+}
+
+func workerResultPrefix(token string) string {
+	return fmt.Sprintf("### worker %s exit ", token)
+}
+
+// RunWorker runs tests one after the other in this process for a workerPool, until in is closed.  Each line of in is
+// the quoted name of a test, which runFn runs.  The output of the test is followed by a line with its exit code.
+func RunWorker(in io.Reader, out io.Writer, runFn func(name string) error) error {
+	token := os.Getenv(workerTokenEnv)
+	if len(token) == 0 {
+		return fmt.Errorf("%s must be set, workers are started by the run command", workerTokenEnv)
+	}
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		name, err := strconv.Unquote(scanner.Text())
+		if err != nil {
+			return fmt.Errorf("invalid test name %q: %v", scanner.Text(), err)
+		}
+		code := 0
+		if err := runFn(name); err != nil {
+			var ok bool
+			if code, ok = exitCode(err); !ok {
+				fmt.Fprintf(out, "error: %v\n", err)
+				code = 1
+			}
+		}
+		fmt.Fprintf(out, "\n%s%d\n", workerResultPrefix(token), code)
+	}
+	return scanner.Err()
+}
+
+// exitCode returns the exit code of a test run in a child process or a worker.
+func exitCode(err error) (int, bool) {
+	switch e := err.(type) {
+	case ExitError:
+		return e.Code, true
+	case *exec.ExitError:
+		return e.ProcessState.Sys().(syscall.WaitStatus).ExitStatus(), true
+	}
+	return 0, false
+}
+
+// workerPool runs tests in long lived `run-test --worker` processes instead of starting a process per test, which
+// saves initializing the test framework for every test.  A worker is started whenever no idle worker is available, so
+// there are as many workers as tests run in parallel.  A worker that exits, because its test crashed it or was
+// interrupted on timeout, is replaced by a new one.
+type workerPool struct {
+	env []string
+
+	lock   sync.Mutex
+	idle   []*worker
+	closed bool
+}
+
+func newWorkerPool(env []string) *workerPool {
+	return &workerPool{env: env}
+}
+
+// Run runs the test in a worker like runWithTimeout, returning its output and an ExitError when it does not pass.
+func (p *workerPool) Run(ctx context.Context, name string, timeout time.Duration) ([]byte, error) {
+	w, err := p.get()
+	if err != nil {
+		return nil, err
+	}
+	out, ran, err := w.run(ctx, name, timeout)
+	if !ran {
+		// the worker exited before it was given the test, give it to a new one
+		w, err = p.get()
+		if err != nil {
+			return nil, err
+		}
+		out, _, err = w.run(ctx, name, timeout)
+	}
+	p.put(ctx, w)
+	return out, err
+}
+
+func (p *workerPool) get() (*worker, error) {
+	p.lock.Lock()
+	if n := len(p.idle); n > 0 {
+		w := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		p.lock.Unlock()
+		return w, nil
+	}
+	p.lock.Unlock()
+	return startWorker(p.env)
+}
+
+func (p *workerPool) put(ctx context.Context, w *worker) {
+	// an interrupted worker may be about to exit
+	if w.exited || ctx.Err() != nil || w.tests >= workerMaxTests {
+		w.close()
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.closed {
+		w.close()
+		return
+	}
+	p.idle = append(p.idle, w)
+}
+
+// Close stops the idle workers.
+func (p *workerPool) Close() {
+	p.lock.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.closed = true
+	p.lock.Unlock()
+	for _, w := range idle {
+		w.close()
+	}
+}
+
+type worker struct {
+	cmd    *exec.Cmd
+	in     io.WriteCloser
+	pipe   io.Closer
+	out    *bufio.Reader
+	prefix string
+
+	tests   int
+	exited  bool
+	waitErr error
+}
+
+func startWorker(env []string) (*worker, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+	prefix := workerResultPrefix(hex.EncodeToString(token))
+
+	c := exec.Command(os.Args[0], "run-test", "--worker")
+	c.Env = append(append(os.Environ(), env...), fmt.Sprintf("%s=%s", workerTokenEnv, hex.EncodeToString(token)))
+	// the tests get /dev/null as standard input, the names of the tests are sent on their own pipe
+	inR, in, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	c.ExtraFiles = []*os.File{inR}
+	// stdout and stderr share a pipe so the output keeps its order, as with CombinedOutput
+	r, w, err := os.Pipe()
+	if err != nil {
+		inR.Close()
+		in.Close()
+		return nil, err
+	}
+	c.Stdout, c.Stderr = w, w
+	err = c.Start()
+	inR.Close()
+	w.Close()
+	if err != nil {
+		in.Close()
+		r.Close()
+		return nil, fmt.Errorf("could not start a worker: %v", err)
+	}
+	return &worker{cmd: c, in: in, pipe: r, out: bufio.NewReader(r), prefix: prefix}, nil
+}
+
+// run runs the test.  ran is false if the worker had already exited and the test did not run.
+func (w *worker) run(ctx context.Context, name string, timeout time.Duration) (out []byte, ran bool, err error) {
+	w.tests++
+	if _, err := fmt.Fprintln(w.in, strconv.Quote(name)); err != nil {
+		w.wait()
+		return nil, false, nil
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go w.interrupt(ctx, timeout, done)
+
+	for {
+		line, readErr := w.out.ReadString('\n')
+		if strings.HasPrefix(line, w.prefix) {
+			code, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, w.prefix)))
+			if err != nil {
+				return out, true, fmt.Errorf("invalid exit code from worker: %q", line)
+			}
+			// drop the newline the worker writes before the exit code
+			out = []byte(strings.TrimSuffix(string(out), "\n"))
+			if code != 0 {
+				return out, true, ExitError{Code: code}
+			}
+			return out, true, nil
+		}
+		out = append(out, line...)
+		if readErr != nil {
+			// the worker exited during the test, like a test process would
+			if err := w.wait(); err != nil {
+				return out, true, err
+			}
+			return out, len(out) > 0, fmt.Errorf("worker exited before the test finished")
+		}
+	}
+}
+
+// interrupt interrupts the worker when the test times out or ctx is done, and aborts it if it does not exit within a
+// minute of a timeout so it dumps its stacks, like runWithTimeout.
+func (w *worker) interrupt(ctx context.Context, timeout time.Duration, done <-chan struct{}) {
+	var timedOut <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timedOut = timer.C
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+		w.cmd.Process.Signal(syscall.SIGINT)
+	case <-timedOut:
+		w.cmd.Process.Signal(syscall.SIGINT)
+		select {
+		case <-done:
+		case <-time.After(time.Minute):
+			w.cmd.Process.Signal(syscall.SIGABRT)
+		}
+	}
+}
+
+func (w *worker) wait() error {
+	if !w.exited {
+		w.exited = true
+		w.waitErr = w.cmd.Wait()
+		w.pipe.Close()
+	}
+	return w.waitErr
+}
+
+// close asks the worker to exit once it has no test left to run.
+func (w *worker) close() {
+	w.in.Close()
+	w.wait()
+}
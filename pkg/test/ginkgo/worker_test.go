@@ -0,0 +1,105 @@
+package ginkgo
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMain runs the test binary as a worker when a test starts it through a workerPool.
+func TestMain(m *testing.M) {
+	if os.Getenv("TEST_FAKE_WORKER") == "true" {
+		if err := RunWorker(WorkerInput(), os.Stdout, runFakeTest); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func runFakeTest(name string) error {
+	fmt.Fprintf(os.Stdout, "running %s in %d\n", name, os.Getpid())
+	switch name {
+	case "fails":
+		fmt.Fprintf(os.Stderr, "fail [fake.go:1]: failed\n")
+		return ExitError{Code: 1}
+	case "crashes":
+		os.Exit(255)
+	case "hangs":
+		time.Sleep(time.Hour)
+	case "reads-stdin":
+		in, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "read %q from stdin\n", in)
+	}
+	return nil
+}
+
+func Test_workerPool(t *testing.T) {
+	p := newWorkerPool([]string{"TEST_FAKE_WORKER=true"})
+	defer p.Close()
+	ctx := context.Background()
+
+	run := func(name string, timeout time.Duration) (string, int) {
+		out, err := p.Run(ctx, name, timeout)
+		if err == nil {
+			return string(out), 0
+		}
+		code, ok := exitCode(err)
+		if !ok {
+			t.Fatalf("unexpected error running %s: %v", name, err)
+		}
+		return string(out), code
+	}
+	pid := func(out string) string {
+		fields := strings.Fields(out)
+		return fields[len(fields)-1]
+	}
+
+	out, code := run("passes", time.Minute)
+	if code != 0 || !strings.HasPrefix(out, "running passes in ") {
+		t.Fatalf("unexpected result of a passing test: %d %q", code, out)
+	}
+	first := pid(out)
+
+	out, code = run("fails", time.Minute)
+	if code != 1 || !strings.Contains(out, "fail [fake.go:1]: failed") {
+		t.Errorf("unexpected result of a failing test: %d %q", code, out)
+	}
+	if !strings.Contains(out, "in "+first+"\n") {
+		t.Errorf("expected the worker to be reused, got %q", out)
+	}
+
+	if _, code = run("crashes", time.Minute); code != 255 {
+		t.Errorf("expected the exit code of the crashed worker, got %d", code)
+	}
+	out, code = run("passes", time.Minute)
+	if code != 0 || pid(out) == first {
+		t.Errorf("expected a new worker to replace the crashed one, got %d %q", code, out)
+	}
+	replacement := pid(out)
+
+	out, code = run("reads-stdin", time.Minute)
+	if code != 0 || !strings.Contains(out, `read "" from stdin`) {
+		t.Errorf("expected a test to read nothing from stdin, got %d %q", code, out)
+	}
+	out, code = run("passes", time.Minute)
+	if code != 0 || pid(out) != replacement {
+		t.Errorf("expected the worker to run the test after one reading stdin, got %d %q", code, out)
+	}
+
+	if _, err := p.Run(ctx, "hangs", 100*time.Millisecond); err == nil {
+		t.Errorf("expected a test that times out to fail")
+	}
+	out, code = run("passes", time.Minute)
+	if code != 0 || pid(out) == replacement {
+		t.Errorf("expected a new worker to replace the interrupted one, got %d %q", code, out)
+	}
+}
@@ -1,11 +1,20 @@
 package ginkgo
 
 import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"sort"
 
 	"github.com/openshift/origin/pkg/monitor"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/synthetictests/allowedalerts"
+	"github.com/openshift/origin/pkg/synthetictests/allowedbackenddisruption"
+	"github.com/openshift/origin/pkg/synthetictests/historicaldata"
+	"github.com/openshift/origin/pkg/synthetictests/platformidentification"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/rest"
 )
 
 type RunDataWriter interface {
@@ -30,6 +39,10 @@ func AdaptEventDataWriter(w EventDataWriter) RunDataWriterFunc {
 
 // WriteRunDataToArtifactsDir attempts to write useful run data to the specified directory.
 func (opt *Options) WriteRunDataToArtifactsDir(artifactDir string, monitor *monitor.Monitor, unorderedEvents monitorapi.Intervals, timeSuffix string) error {
+	return writeRunDataToArtifactsDir(opt.RunDataWriters, artifactDir, monitor, unorderedEvents, timeSuffix)
+}
+
+func writeRunDataToArtifactsDir(writers []RunDataWriter, artifactDir string, monitor *monitor.Monitor, unorderedEvents monitorapi.Intervals, timeSuffix string) error {
 	errs := []error{}
 
 	// use custom sorting here so that we can prioritize the sort order to make the intervals html page as readable
@@ -40,7 +53,7 @@ func (opt *Options) WriteRunDataToArtifactsDir(artifactDir string, monitor *moni
 	}
 	sort.Stable(monitorapi.ByTimeWithNamespacedPods(events))
 
-	for _, writer := range opt.RunDataWriters {
+	for _, writer := range writers {
 		currErr := writer.WriteRunData(artifactDir, monitor, events, timeSuffix)
 		if currErr != nil {
 			errs = append(errs, currErr)
@@ -48,3 +61,41 @@ func (opt *Options) WriteRunDataToArtifactsDir(artifactDir string, monitor *moni
 	}
 	return utilerrors.NewAggregate(errs)
 }
+
+// writeJobTypeForJobRun records the job type of the cluster next to the run data, so compute-historical-data knows
+// which job type the disruption and alert data of the run belongs to.
+func writeJobTypeForJobRun(ctx context.Context, artifactDir string, restConfig *rest.Config, timeSuffix string) error {
+	jobType, err := platformidentification.GetJobType(ctx, restConfig)
+	if err != nil {
+		return err
+	}
+	return platformidentification.WriteJobType(filepath.Join(artifactDir, fmt.Sprintf("job-type%s.json", timeSuffix)), jobType)
+}
+
+// setHistoricalDataFromFile replaces the embedded historical data of the alert and disruption invariants with a file
+// written by compute-historical-data.
+func setHistoricalDataFromFile(filename string) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	if err := allowedalerts.SetHistoricalData(data); err != nil {
+		return fmt.Errorf("could not parse %s: %v", filename, err)
+	}
+	if err := allowedbackenddisruption.SetHistoricalData(data); err != nil {
+		return fmt.Errorf("could not parse %s: %v", filename, err)
+	}
+	return nil
+}
+
+// setHistoricalDataMatchingFromFile configures how the alert and disruption invariants match historical data to the
+// job type with a file read by historicaldata.ReadMatchingConfigFile.
+func setHistoricalDataMatchingFromFile(filename string) error {
+	config, err := historicaldata.ReadMatchingConfigFile(filename)
+	if err != nil {
+		return err
+	}
+	allowedalerts.SetMatching(*config)
+	allowedbackenddisruption.SetMatching(*config)
+	return nil
+}
@@ -52736,7 +52736,10 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
     }
 
     function isEndpointConnectivity(eventInterval) {
-        if (!eventInterval.message.includes("stopped responding to GET requests")){
+        if (eventInterval.locator.includes("disruption/") && eventInterval.message.includes("reason/HighLatency")) {
+            return true
+        }
+        if (!eventInterval.message.includes("stopped responding to ")){
             return false
         }
         if (eventInterval.locator.includes("disruption/")) {
@@ -52766,6 +52769,13 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
         return false
     }
 
+    function isRunGap(eventInterval) {
+        if (eventInterval.locator.startsWith("e2e-run/")) {
+            return true
+        }
+        return false
+    }
+
     const reReason = new RegExp("(^| )reason/([^ ]+)")
     function podStateValue(item) {
         let m = item.message.match(reReason);
@@ -52819,6 +52829,13 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
         return [item.locator, ` + "`" + ` (${roles},updates)` + "`" + `, "Update"];
     }
 
+    function endpointAvailabilityValue(item) {
+        if (item.message.includes("reason/HighLatency")) {
+            return [item.locator, "", "HighLatency"]
+        }
+        return [item.locator, "", "Failed"]
+    }
+
     function alertSeverity(item) {
         // the other types can be pending, so check pending first
         let pendingIndex = item.message.indexOf("pending")
@@ -52937,7 +52954,10 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
     })
 
     timelineGroups.push({group: "endpoint-availability", data: []})
-    createTimelineData("Failed", timelineGroups[timelineGroups.length - 1].data, eventIntervals, isEndpointConnectivity)
+    createTimelineData(endpointAvailabilityValue, timelineGroups[timelineGroups.length - 1].data, eventIntervals, isEndpointConnectivity)
+
+    timelineGroups.push({group: "run-gaps", data: []})
+    createTimelineData("NotMonitored", timelineGroups[timelineGroups.length - 1].data, eventIntervals, isRunGap)
 
     timelineGroups.push({group: "e2e-test-failed", data: []})
     createTimelineData("Failed", timelineGroups[timelineGroups.length - 1].data, eventIntervals, isE2EFailed)
@@ -52978,6 +52998,8 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
             'OperatorUnavailable', 'OperatorDegraded', 'OperatorProgressing', // operators
             'Update', 'Drain', 'Reboot', 'OperatingSystemUpdate', 'NodeNotReady', // nodes
             'Passed', 'Skipped', 'Flaked', 'Failed',  // tests
+            'HighLatency', // endpoints
+            'NotMonitored', // run gaps
             'PodCreated', 'PodScheduled', 'ContainerWait', 'ContainerStart', 'ContainerNotReady', 'ContainerReady',  // pods
             'Degraded', 'Upgradeable', 'False', 'Unknown'])
         .range([
@@ -52985,6 +53007,8 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
             '#d0312d', '#ffa500', '#fada5e', // operators
             '#1e7bd9', '#4294e6', '#6aaef2', '#96cbff', '#fada5e', // nodes
             '#3cb043', '#ceba76', '#ffa500', '#d0312d', // tests
+            '#ffa500', // endpoints
+            '#888888', // run gaps
             '#96cbff', '#1e7bd9', '#ca8dfd', '#9300ff', '#fada5e','#3cb043', // pods
             '#b65049', '#32b8b6', '#ffffff', '#bbbbbb']);
     myChart.
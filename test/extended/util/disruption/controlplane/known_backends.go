@@ -11,73 +11,31 @@ import (
 )
 
 func StartAllAPIMonitoring(ctx context.Context, m monitor.Recorder, clusterConfig *rest.Config) error {
-	if err := startKubeAPIMonitoringWithNewConnections(ctx, m, clusterConfig); err != nil {
-		return err
-	}
-	if err := startOpenShiftAPIMonitoringWithNewConnections(ctx, m, clusterConfig); err != nil {
-		return err
-	}
-	if err := startOAuthAPIMonitoringWithNewConnections(ctx, m, clusterConfig); err != nil {
-		return err
-	}
-	if err := startKubeAPIMonitoringWithConnectionReuse(ctx, m, clusterConfig); err != nil {
-		return err
-	}
-	if err := startOpenShiftAPIMonitoringWithConnectionReuse(ctx, m, clusterConfig); err != nil {
-		return err
-	}
-	if err := startOAuthAPIMonitoringWithConnectionReuse(ctx, m, clusterConfig); err != nil {
-		return err
-	}
-	return nil
-}
-
-func startKubeAPIMonitoringWithNewConnections(ctx context.Context, m monitor.Recorder, clusterConfig *rest.Config) error {
-	backendSampler, err := createKubeAPIMonitoringWithNewConnections(clusterConfig)
-	if err != nil {
-		return err
-	}
-	return backendSampler.StartEndpointMonitoring(ctx, m, nil)
-}
-
-func startOpenShiftAPIMonitoringWithNewConnections(ctx context.Context, m monitor.Recorder, clusterConfig *rest.Config) error {
-	backendSampler, err := createOpenShiftAPIMonitoringWithNewConnections(clusterConfig)
-	if err != nil {
-		return err
-	}
-	return backendSampler.StartEndpointMonitoring(ctx, m, nil)
+	return StartAllAPIMonitoringWithLatency(backenddisruption.DefaultLatencyConfig())(ctx, m, clusterConfig)
 }
 
-func startOAuthAPIMonitoringWithNewConnections(ctx context.Context, m monitor.Recorder, clusterConfig *rest.Config) error {
-	backendSampler, err := createOAuthAPIMonitoringWithNewConnections(clusterConfig)
-	if err != nil {
-		return err
-	}
-	return backendSampler.StartEndpointMonitoring(ctx, m, nil)
-}
-
-func startKubeAPIMonitoringWithConnectionReuse(ctx context.Context, m monitor.Recorder, clusterConfig *rest.Config) error {
-	backendSampler, err := createKubeAPIMonitoringWithConnectionReuse(clusterConfig)
-	if err != nil {
-		return err
-	}
-	return backendSampler.StartEndpointMonitoring(ctx, m, nil)
-}
-
-func startOpenShiftAPIMonitoringWithConnectionReuse(ctx context.Context, m monitor.Recorder, clusterConfig *rest.Config) error {
-	backendSampler, err := createOpenShiftAPIMonitoringWithConnectionReuse(clusterConfig)
-	if err != nil {
-		return err
-	}
-	return backendSampler.StartEndpointMonitoring(ctx, m, nil)
-}
-
-func startOAuthAPIMonitoringWithConnectionReuse(ctx context.Context, m monitor.Recorder, clusterConfig *rest.Config) error {
-	backendSampler, err := createOAuthAPIMonitoringWithConnectionReuse(clusterConfig)
-	if err != nil {
-		return err
+// StartAllAPIMonitoringWithLatency returns a StartEventIntervalRecorderFunc that monitors the API servers, recording
+// HighLatency intervals as configured by latency.
+func StartAllAPIMonitoringWithLatency(latency backenddisruption.LatencyConfig) monitor.StartEventIntervalRecorderFunc {
+	return func(ctx context.Context, m monitor.Recorder, clusterConfig *rest.Config) error {
+		for _, createBackendSampler := range []func(*rest.Config) (*backenddisruption.BackendSampler, error){
+			createKubeAPIMonitoringWithNewConnections,
+			createOpenShiftAPIMonitoringWithNewConnections,
+			createOAuthAPIMonitoringWithNewConnections,
+			createKubeAPIMonitoringWithConnectionReuse,
+			createOpenShiftAPIMonitoringWithConnectionReuse,
+			createOAuthAPIMonitoringWithConnectionReuse,
+		} {
+			backendSampler, err := createBackendSampler(clusterConfig)
+			if err != nil {
+				return err
+			}
+			if err := backendSampler.WithLatencyConfig(latency).StartEndpointMonitoring(ctx, m, nil); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
-	return backendSampler.StartEndpointMonitoring(ctx, m, nil)
 }
 
 func createKubeAPIMonitoringWithNewConnections(clusterConfig *rest.Config) (*backenddisruption.BackendSampler, error) {
@@ -0,0 +1,38 @@
+package custom
+
+import (
+	"context"
+
+	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitor/backenddisruption"
+	"k8s.io/client-go/rest"
+)
+
+// StartMonitoringFromFile reads the backends in filename and returns a StartEventIntervalRecorderFunc that monitors
+// them for disruption alongside the built-in backends, recording HighLatency intervals as configured by latency.  The
+// file is read right away so that mistakes are reported before anything is started.
+func StartMonitoringFromFile(filename string, latency backenddisruption.LatencyConfig) (monitor.StartEventIntervalRecorderFunc, error) {
+	backends, err := backenddisruption.ReadBackendConfigFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, m monitor.Recorder, clusterConfig *rest.Config) error {
+		return StartAllConfiguredMonitoring(ctx, m, clusterConfig, backends, latency)
+	}, nil
+}
+
+// StartAllConfiguredMonitoring starts a BackendSampler for each backend and connection type in backends.
+func StartAllConfiguredMonitoring(ctx context.Context, m monitor.Recorder, clusterConfig *rest.Config, backends *backenddisruption.BackendConfigList, latency backenddisruption.LatencyConfig) error {
+	for _, backend := range backends.Backends {
+		backendSamplers, err := backend.NewBackendSamplers(clusterConfig)
+		if err != nil {
+			return err
+		}
+		for _, backendSampler := range backendSamplers {
+			if err := backendSampler.WithLatencyConfig(latency).StartEndpointMonitoring(ctx, m, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -11,19 +11,25 @@ import (
 )
 
 func StartAllIngressMonitoring(ctx context.Context, m monitor.Recorder, clusterConfig *rest.Config) error {
-	if err := createOAuthRouteAvailableWithNewConnections().StartEndpointMonitoring(ctx, m, nil); err != nil {
-		return err
-	}
-	if err := createOAuthRouteAvailableWithConnectionReuse().StartEndpointMonitoring(ctx, m, nil); err != nil {
-		return err
-	}
-	if err := createConsoleRouteAvailableWithNewConnections().StartEndpointMonitoring(ctx, m, nil); err != nil {
-		return err
-	}
-	if err := createConsoleRouteAvailableWithConnectionReuse().StartEndpointMonitoring(ctx, m, nil); err != nil {
-		return err
+	return StartAllIngressMonitoringWithLatency(backenddisruption.DefaultLatencyConfig())(ctx, m, clusterConfig)
+}
+
+// StartAllIngressMonitoringWithLatency returns a StartEventIntervalRecorderFunc that monitors the routes of the
+// ingress, recording HighLatency intervals as configured by latency.
+func StartAllIngressMonitoringWithLatency(latency backenddisruption.LatencyConfig) monitor.StartEventIntervalRecorderFunc {
+	return func(ctx context.Context, m monitor.Recorder, clusterConfig *rest.Config) error {
+		for _, createBackendSampler := range []func() *backenddisruption.BackendSampler{
+			createOAuthRouteAvailableWithNewConnections,
+			createOAuthRouteAvailableWithConnectionReuse,
+			createConsoleRouteAvailableWithNewConnections,
+			createConsoleRouteAvailableWithConnectionReuse,
+		} {
+			if err := createBackendSampler().WithLatencyConfig(latency).StartEndpointMonitoring(ctx, m, nil); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
-	return nil
 }
 
 func createOAuthRouteAvailableWithNewConnections() *backenddisruption.BackendSampler {
@@ -1450,14 +1450,15 @@ func FixturePaths(elem ...string) []string {
 var (
 	internalFixtureOnce sync.Once
 	// callers should use fixtureDirectory() instead
-	internalFixtureDir string
+	internalFixtureDir   string
+	internalFixtureOwned bool
 )
 
 // fixtureDirectory returns the fixture directory for use within this process.
-// It returns true if the current process was the one to initialize the directory.
+// It returns true if the current process allocated the directory and is responsible
+// for cleaning it up, rather than inheriting it from a parent process.
 func fixtureDirectory() (string, bool) {
 	// load or allocate fixture directory
-	var init bool
 	internalFixtureOnce.Do(func() {
 		// reuse fixture directories across child processes for efficiency
 		internalFixtureDir = os.Getenv("OS_TEST_FIXTURE_DIR")
@@ -1467,10 +1468,10 @@ func fixtureDirectory() (string, bool) {
 				panic(err)
 			}
 			internalFixtureDir = dir
-			init = true
+			internalFixtureOwned = true
 		}
 	})
-	return internalFixtureDir, init
+	return internalFixtureDir, internalFixtureOwned
 }
 
 // FixturePath returns an absolute path to a fixture file in test/extended/testdata/,
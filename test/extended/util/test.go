@@ -153,11 +153,12 @@ func requiresTestStart() {
 func WithCleanup(fn func()) {
 	testsStarted = true
 
-	// Initialize the fixture directory. If we were the ones to initialize it, set the env
-	// var so that child processes inherit this directory and take responsibility for
-	// cleaning it up after we exit.
-	fixtureDir, init := fixtureDirectory()
-	if init {
+	// Initialize the fixture directory. If we own it, set the env var so that child
+	// processes inherit this directory, and clean it up after fn. Fixtures are restored
+	// on demand, so a process that calls this once per test, like a worker, gives each
+	// test a clean directory.
+	fixtureDir, owned := fixtureDirectory()
+	if owned {
 		os.Setenv("OS_TEST_FIXTURE_DIR", fixtureDir)
 		defer func() {
 			os.Setenv("OS_TEST_FIXTURE_DIR", "")